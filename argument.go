@@ -1,5 +1,19 @@
 package pgxmock
 
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
 // Argument interface allows to match
 // any argument in specific way when used with
 // ExpectedQuery and ExpectedExec expectations.
@@ -21,3 +35,251 @@ func (a anyArgument) Match(_ interface{}) bool {
 	return true
 }
 
+// AnyArgOf will return an Argument which matches any value
+// of the type T, e.g. AnyArgOf[int64](), but not int or nil.
+//
+// Useful to catch accidental type changes breaking pgx encoding,
+// when the value itself is not known, e.g. generated IDs.
+func AnyArgOf[T any]() Argument {
+	return anyArgumentOf[T]{}
+}
+
+type anyArgumentOf[T any] struct{}
+
+func (a anyArgumentOf[T]) Match(v interface{}) bool {
+	_, ok := v.(T)
+	return ok
+}
+
+func (a anyArgumentOf[T]) String() string {
+	return fmt.Sprintf("AnyArgOf[%s]", reflect.TypeFor[T]())
+}
+
+// Len will return an Argument which matches slices, arrays,
+// strings and maps having n elements, or n bytes for strings.
+//
+// Useful for bulk operations, where the content is generated,
+// but the batch size matters.
+func Len(n int) Argument {
+	return lenArgument(n)
+}
+
+type lenArgument int
+
+func (a lenArgument) Match(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.String, reflect.Map:
+		return rv.Len() == int(a)
+	}
+	return false
+}
+
+func (a lenArgument) String() string {
+	return fmt.Sprintf("Len(%d)", int(a))
+}
+
+// TimeEqual will return an Argument which matches times representing
+// the same instant as expected, regardless of the location and monotonic
+// clock reading, the same as time.Time.Equal does. Expected time.Time
+// arguments are matched this way even without the matcher.
+func TimeEqual(expected time.Time) Argument {
+	return timeArgument(expected)
+}
+
+type timeArgument time.Time
+
+func (a timeArgument) Match(v interface{}) bool {
+	t, ok := v.(time.Time)
+	return ok && t.Equal(time.Time(a))
+}
+
+func (a timeArgument) String() string {
+	return fmt.Sprintf("TimeEqual(%s)", time.Time(a))
+}
+
+// ValueArg will return an Argument which matches arguments having the same
+// value as expected, when both are unwrapped with driver.Valuer and numbers
+// are converted to the widest type, e.g. 5 matches pgtype.Int8{Int64: 5,
+// Valid: true} and pgtype.Text{String: "john", Valid: true} matches "john",
+// while invalid pgtype values match nil.
+//
+// Useful when the code under test mixes plain values and pgtype wrappers.
+func ValueArg(expected interface{}) Argument {
+	return valueArgument{expected: expected}
+}
+
+type valueArgument struct {
+	expected interface{}
+}
+
+func (a valueArgument) Match(v interface{}) bool {
+	expected, actual := plainValue(a.expected), plainValue(v)
+	if x, ok := expected.(int64); ok {
+		if y, ok := actual.(float64); ok {
+			return float64(x) == y
+		}
+	}
+	if x, ok := expected.(float64); ok {
+		if y, ok := actual.(int64); ok {
+			return x == float64(y)
+		}
+	}
+	return argumentEqual(expected, actual)
+}
+
+func (a valueArgument) String() string {
+	return fmt.Sprintf("ValueArg(%+v)", a.expected)
+}
+
+// plainValue unwraps driver.Valuer values and converts
+// integers to int64, unless overflowed, and floats to float64
+func plainValue(v interface{}) interface{} {
+	if valuer, ok := v.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nil
+		}
+		value, err := valuer.Value()
+		if err != nil {
+			return v
+		}
+		v = value
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := rv.Uint(); u <= math.MaxInt64 {
+			return int64(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	}
+	return v
+}
+
+// BytesHex will return an Argument which matches []byte arguments
+// equal to the hex encoded expected value, optionally prefixed with
+// \x as PostgreSQL prints bytea values. Panics if the value is invalid.
+func BytesHex(expected string) Argument {
+	b, err := hex.DecodeString(strings.TrimPrefix(expected, `\x`))
+	if err != nil {
+		panic(fmt.Sprintf("invalid hex value %q: %s", expected, err))
+	}
+	return bytesArgument(b)
+}
+
+// BytesBase64 will return an Argument which matches []byte arguments
+// equal to the standard base64 encoded expected value. Panics if the
+// value is invalid.
+func BytesBase64(expected string) Argument {
+	b, err := base64.StdEncoding.DecodeString(expected)
+	if err != nil {
+		panic(fmt.Sprintf("invalid base64 value %q: %s", expected, err))
+	}
+	return bytesArgument(b)
+}
+
+type bytesArgument []byte
+
+func (a bytesArgument) Match(v interface{}) bool {
+	b, ok := v.([]byte)
+	return ok && bytes.Equal(a, b)
+}
+
+func (a bytesArgument) String() string {
+	return fmt.Sprintf("Bytes(%s)", argString([]byte(a)))
+}
+
+// argString formats the argument for mismatch errors,
+// byte slices are printed in the hex format of bytea
+func argString(v interface{}) string {
+	if b, ok := v.([]byte); ok && b != nil {
+		return `\x` + hex.EncodeToString(b)
+	}
+	return fmt.Sprintf("%+v", v)
+}
+
+// argumentEqual reports whether the actual argument equals
+// the expected one, times are compared with time.Time.Equal
+func argumentEqual(expected, actual interface{}) bool {
+	if t, ok := expected.(time.Time); ok {
+		return timeArgument(t).Match(actual)
+	}
+	return reflect.DeepEqual(expected, actual)
+}
+
+// TypedArg will return an Argument which matches arguments
+// having the same value of the data type t as expected, which
+// may be given in the text representation, e.g. "(1,foo)".
+//
+// Useful for composite types, e.g. a struct argument
+// may be expected as "(1,foo)" or pgtype.CompositeFields.
+func TypedArg(t *pgtype.Type, expected interface{}) Argument {
+	return typedArgument{t: t, expected: expected}
+}
+
+type typedArgument struct {
+	t        *pgtype.Type
+	expected interface{}
+}
+
+func (a typedArgument) Match(v interface{}) bool {
+	m := pgtype.NewMap()
+	m.RegisterType(a.t)
+	// values are compared decoded, since text representations
+	// may differ e.g. by the order of hstore keys
+	decode := func(v interface{}) (interface{}, error) {
+		src, ok := v.(string)
+		if !ok {
+			buf, err := m.Encode(a.t.OID, pgtype.TextFormatCode, v, nil)
+			if err != nil {
+				return nil, err
+			}
+			src = string(buf)
+		}
+		return a.t.Codec.DecodeValue(m, a.t.OID, pgtype.TextFormatCode, []byte(src))
+	}
+	expected, err := decode(a.expected)
+	if err != nil {
+		return false
+	}
+	actual, err := decode(v)
+	return err == nil && reflect.DeepEqual(expected, actual)
+}
+
+// Redacted is printed instead of sensitive values,
+// see Sensitive and Rows.SensitiveColumns
+const Redacted = "[REDACTED]"
+
+// Sensitive will return an Argument which matches the expected value,
+// or the expected Argument, but prints [REDACTED] instead of both
+// expected and actual values in errors, expectation dumps and call log.
+//
+// Useful for passwords and personal data, since test logs may be
+// uploaded to shared CI systems.
+func Sensitive(expected interface{}) Argument {
+	return sensitiveArgument{expected: expected}
+}
+
+type sensitiveArgument struct {
+	expected interface{}
+}
+
+func (a sensitiveArgument) Match(v interface{}) bool {
+	if matcher, ok := a.expected.(Argument); ok {
+		return matcher.Match(v)
+	}
+	return argumentEqual(a.expected, v)
+}
+
+func (a sensitiveArgument) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(Redacted))
+}
+
+// isSensitive reports whether the expected argument is marked with Sensitive
+func isSensitive(expected interface{}) bool {
+	_, ok := expected.(sensitiveArgument)
+	return ok
+}
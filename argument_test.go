@@ -3,10 +3,13 @@ package pgxmock
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
 	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -119,6 +122,28 @@ func TestQueryRewriterFail(t *testing.T) {
 
 }
 
+// connQryRW records the connection passed to the rewriter
+type connQryRW struct {
+	conn **pgx.Conn
+}
+
+func (c connQryRW) RewriteQuery(_ context.Context, conn *pgx.Conn, sql string, _ []any) (newSQL string, newArgs []any, err error) {
+	*c.conn = conn
+	return sql, []any{conn.TypeMap() != nil}, nil
+}
+
+func TestQueryRewriterConnOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	conn := &pgx.Conn{}
+	mock, _ := NewConn(QueryRewriterConnOption(conn))
+	var got *pgx.Conn
+	mock.ExpectExec("INSERT").WithArgs(false).WillReturnResult(NewResult("INSERT", 1))
+	_, err := mock.Exec(ctx, "INSERT", connQryRW{&got})
+	a.NoError(err)
+	a.Same(conn, got)
+}
+
 func TestByteSliceNamedArgument(t *testing.T) {
 	t.Parallel()
 	mock, err := NewConn()
@@ -166,6 +191,127 @@ func TestAnyArgument(t *testing.T) {
 	}
 }
 
+func TestAnyArgOf(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, err := NewConn()
+	a.NoError(err)
+
+	mock.ExpectExec("INSERT INTO users").
+		WithArgs(AnyArgOf[int64](), AnyArgOf[fmt.Stringer]()).
+		WillReturnResult(NewResult("INSERT", 1))
+
+	_, err = mock.Exec(context.Background(), "INSERT INTO users(id, name) VALUES ($1, $2)", 42, time.Second)
+	a.ErrorIs(err, ErrArgsMismatch, "int must not match int64")
+	a.Contains(err.Error(), "anyArgumentOf[int64]")
+	a.Equal("AnyArgOf[int64]", fmt.Sprint(AnyArgOf[int64]()))
+
+	_, err = mock.Exec(context.Background(), "INSERT INTO users(id, name) VALUES ($1, $2)", int64(42), nil)
+	a.ErrorIs(err, ErrArgsMismatch, "nil must not match an interface type")
+
+	_, err = mock.Exec(context.Background(), "INSERT INTO users(id, name) VALUES ($1, $2)", int64(42), time.Second)
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestLenArgument(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.True(Len(3).Match([]int{1, 2, 3}))
+	a.True(Len(2).Match([2]string{}))
+	a.True(Len(3).Match("abc"))
+	a.True(Len(1).Match(map[string]int{"a": 1}))
+	a.True(Len(0).Match([]byte(nil)))
+	a.False(Len(2).Match([]int{1, 2, 3}))
+	a.False(Len(0).Match(nil))
+	a.False(Len(1).Match(1))
+	a.Equal("Len(3)", fmt.Sprint(Len(3)))
+
+	mock, err := NewConn()
+	a.NoError(err)
+	mock.ExpectExec("INSERT INTO users").
+		WithArgs(Len(2)).
+		WillReturnResult(NewResult("INSERT", 2))
+	_, err = mock.Exec(context.Background(), "INSERT INTO users SELECT unnest($1::text[])", []string{"john", "jane", "bob"})
+	a.ErrorIs(err, ErrArgsMismatch)
+	_, err = mock.Exec(context.Background(), "INSERT INTO users SELECT unnest($1::text[])", []string{"john", "jane"})
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestTimeEqualArgument(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	now := time.Now()
+	utc := now.UTC()
+	a.False(reflect.DeepEqual(now, utc))
+	a.True(TimeEqual(now).Match(utc))
+	a.True(TimeEqual(now).Match(now.Round(0)))
+	a.False(TimeEqual(now).Match(now.Add(time.Nanosecond)))
+	a.False(TimeEqual(now).Match(now.Unix()))
+	a.Equal("TimeEqual(2024-01-02 03:04:05 +0000 UTC)", fmt.Sprint(TimeEqual(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))))
+
+	mock, err := NewConn()
+	a.NoError(err)
+	mock.ExpectExec("UPDATE users").
+		WithArgs(now, TimeEqual(now)).
+		WillReturnResult(NewResult("UPDATE", 1))
+	_, err = mock.Exec(context.Background(), "UPDATE users SET created_at = $1, updated_at = $2", utc, now.In(time.FixedZone("UTC+3", 3*60*60)))
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestValueArgument(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.True(ValueArg(5).Match(pgtype.Int8{Int64: 5, Valid: true}))
+	a.True(ValueArg(pgtype.Int4{Int32: 5, Valid: true}).Match(int64(5)))
+	a.True(ValueArg(uint8(5)).Match(pgtype.Float8{Float64: 5, Valid: true}))
+	a.True(ValueArg(pgtype.Text{String: "john", Valid: true}).Match("john"))
+	a.True(ValueArg(nil).Match(pgtype.Text{}))
+	a.True(ValueArg(nil).Match((*pgtype.Text)(nil)))
+	a.True(ValueArg(true).Match(pgtype.Bool{Bool: true, Valid: true}))
+	now := time.Now()
+	a.True(ValueArg(now).Match(pgtype.Timestamptz{Time: now.UTC(), Valid: true}))
+	a.False(ValueArg(5).Match(pgtype.Int8{Int64: 6, Valid: true}))
+	a.False(ValueArg(5).Match(pgtype.Int8{}))
+	a.False(ValueArg(5).Match("5"))
+	a.Equal("ValueArg(5)", fmt.Sprint(ValueArg(5)))
+
+	mock, err := NewConn()
+	a.NoError(err)
+	mock.ExpectExec("UPDATE users").
+		WithArgs(ValueArg("john"), ValueArg(42)).
+		WillReturnResult(NewResult("UPDATE", 1))
+	_, err = mock.Exec(context.Background(), "UPDATE users SET name = $1 WHERE id = $2", pgtype.Text{String: "john", Valid: true}, pgtype.Int8{Int64: 42, Valid: true})
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestBytesArguments(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.True(BytesHex("deadbeef").Match([]byte{0xde, 0xad, 0xbe, 0xef}))
+	a.True(BytesHex(`\xDEADBEEF`).Match([]byte{0xde, 0xad, 0xbe, 0xef}))
+	a.True(BytesBase64("3q2+7w==").Match([]byte{0xde, 0xad, 0xbe, 0xef}))
+	a.False(BytesHex("deadbeef").Match([]byte{0xde, 0xad}))
+	a.False(BytesHex("deadbeef").Match("deadbeef"))
+	a.Panics(func() { BytesHex("xyz") })
+	a.Panics(func() { BytesBase64("!") })
+	a.Equal(`Bytes(\xdeadbeef)`, fmt.Sprint(BytesBase64("3q2+7w==")))
+
+	mock, err := NewConn()
+	a.NoError(err)
+	mock.ExpectExec("UPDATE files").
+		WithArgs([]byte{0xca, 0xfe}).
+		WillReturnResult(NewResult("UPDATE", 1))
+	_, err = mock.Exec(context.Background(), "UPDATE files SET content = $1", []byte{0xca, 0xfe, 0xba, 0xbe})
+	a.ErrorContains(err, `argument 0 expected [[]uint8 - \xcafe] does not match actual [[]uint8 - \xcafebabe]`)
+	_, err = mock.Exec(context.Background(), "UPDATE files SET content = $1", []byte{0xca, 0xfe})
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
 func TestAnyNamedArgument(t *testing.T) {
 	t.Parallel()
 	mock, err := NewConn()
@@ -188,3 +334,113 @@ func TestAnyNamedArgument(t *testing.T) {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
 }
+
+func TestTypedArgument(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	type item struct {
+		ID   int32
+		Name string
+	}
+	composite := newCompositeType()
+	mock, _ := NewConn()
+	mock.ExpectExec("INSERT INTO items").
+		WithArgs(TypedArg(composite, "(1,foo)")).
+		WillReturnResult(NewResult("INSERT", 1))
+	mock.ExpectExec("INSERT INTO items").
+		WithArgs(TypedArg(composite, pgtype.CompositeFields{int32(2), "bar"})).
+		WillReturnResult(NewResult("INSERT", 1))
+
+	_, err := mock.Exec(context.Background(), "INSERT INTO items VALUES ($1)", item{1, "foo"})
+	a.NoError(err)
+	_, err = mock.Exec(context.Background(), "INSERT INTO items VALUES ($1)", item{3, "bar"})
+	a.Error(err)
+	_, err = mock.Exec(context.Background(), "INSERT INTO items VALUES ($1)", item{2, "bar"})
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+	a.False(TypedArg(composite, 42).Match(item{}))
+}
+
+// prefixQryRW prepends the comment to the query and adds the argument
+type prefixQryRW string
+
+func (p prefixQryRW) RewriteQuery(_ context.Context, _ *pgx.Conn, sql string, args []any) (newSQL string, newArgs []any, err error) {
+	return "/* " + string(p) + " */ " + sql, append(args, string(p)), nil
+}
+
+// loopQryRW returns itself among the rewritten arguments
+type loopQryRW struct{}
+
+func (l loopQryRW) RewriteQuery(_ context.Context, _ *pgx.Conn, sql string, args []any) (newSQL string, newArgs []any, err error) {
+	return sql, append([]any{l}, args...), nil
+}
+
+func TestQueryRewritersLeadingArguments(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	// the last leading rewriter is applied, the same as pgx does
+	mock.ExpectExec("INSERT").
+		WithRewrittenSQL(`^/\* b \*/ INSERT$`).
+		WithArgs(1, "b").
+		WillReturnResult(NewResult("INSERT", 1))
+	// rewriters at other positions are plain arguments
+	mock.ExpectExec("UPDATE").
+		WithArgs(1, prefixQryRW("a")).
+		WillReturnResult(NewResult("UPDATE", 1))
+	// other leading options are kept
+	mock.ExpectQuery("SELECT").
+		WithRewrittenSQL(`= \$1$`).
+		WithArgs(pgx.QueryExecModeExec, 1).
+		WillReturnRows(NewRows([]string{"id"}))
+	// the rewriter is applied once, even if it returns another one
+	mock.ExpectExec("DELETE").
+		WithArgs(loopQryRW{}, 1).
+		WillReturnResult(NewResult("DELETE", 1))
+
+	_, err := mock.Exec(ctx, "INSERT", prefixQryRW("a"), prefixQryRW("b"), 1)
+	a.NoError(err)
+	_, err = mock.Exec(ctx, "UPDATE", 1, prefixQryRW("a"))
+	a.NoError(err)
+	_, err = mock.Query(ctx, "SELECT * FROM t WHERE id = @id", pgx.QueryExecModeExec, pgx.NamedArgs{"id": 1})
+	a.NoError(err)
+	_, err = mock.Exec(ctx, "DELETE", loopQryRW{}, 1)
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestSensitiveArgument(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	mock.ExpectExec("UPDATE users").
+		WithArgs("john", Sensitive("s3cret")).
+		WillReturnResult(NewResult("UPDATE", 1)).
+		Times(2)
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(NewRows([]string{"name", "password"}).AddRow("john", "s3cret").SensitiveColumns("password"))
+
+	_, err := mock.Exec(ctx, "UPDATE users", "john", "s3cret")
+	a.NoError(err)
+	_, err = mock.Exec(ctx, "UPDATE users", "john", "wrong")
+	a.ErrorContains(err, "could not match 1 argument string - [REDACTED]")
+	a.NotContains(err.Error(), "s3cret")
+	a.NotContains(err.Error(), "wrong")
+	var mismatch *MismatchError
+	a.ErrorAs(err, &mismatch)
+	a.Equal(Redacted, mismatch.Actual)
+	a.NotContains(fmt.Sprintf("%+v %#v", mismatch.Expected, mismatch.Actual), "s3cret")
+
+	log, err := mock.CallLogJSON()
+	a.NoError(err)
+	a.NotContains(string(log), "s3cret")
+	a.NotContains(string(log), "wrong", "arguments of mismatched calls are redacted too")
+	a.Contains(string(log), `"args":["john","[REDACTED]"]`)
+
+	pending := mock.PendingExpectations()
+	a.Len(pending, 2)
+	for _, e := range pending {
+		a.NotContains(e.Expectation.String(), "s3cret")
+	}
+	a.Contains(pending[1].Expectation.String(), "row 0 - [john [REDACTED]]")
+}
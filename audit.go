@@ -0,0 +1,65 @@
+package pgxmock
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// AuditRule checks the statement executed by the call, e.g. Query(),
+// Exec() or Prepare(), and returns the error describing the violation.
+// Rules are set with AuditRulesOption and violations are reported by
+// ExpectationsWereMet.
+type AuditRule func(call *Call) error
+
+var (
+	reSelectStar = regexp.MustCompile(`(?i)\bselect\s+(?:distinct\s+)?(?:\w+\.)?\*`)
+	reLiteral    = regexp.MustCompile(`(?i)'[^']*'|(?:[=<>]|\bin\s*\(|\blimit|\boffset)\s*-?\d+(?:\.\d+)?\b`)
+	reModifying  = regexp.MustCompile(`(?i)^\s*(?:update|delete)\b`)
+	reWhere      = regexp.MustCompile(`(?i)\bwhere\b`)
+)
+
+// AuditNoSelectStar reports statements selecting all columns with *.
+func AuditNoSelectStar() AuditRule {
+	return func(call *Call) error {
+		if reSelectStar.MatchString(call.SQL) {
+			return errors.New("SELECT * is not allowed")
+		}
+		return nil
+	}
+}
+
+// AuditPlaceholdersOnly reports statements with string or numeric literals
+// compared to columns, which are likely interpolated instead of passed as
+// placeholders arguments, e.g. "WHERE name = 'john'" or "WHERE id = 42".
+func AuditPlaceholdersOnly() AuditRule {
+	return func(call *Call) error {
+		if literal := reLiteral.FindString(call.SQL); literal != "" {
+			return fmt.Errorf("literal value %s is used instead of a placeholder", literal)
+		}
+		return nil
+	}
+}
+
+// AuditWhereRequired reports UPDATE and DELETE statements without WHERE clause.
+func AuditWhereRequired() AuditRule {
+	return func(call *Call) error {
+		if reModifying.MatchString(call.SQL) && !reWhere.MatchString(call.SQL) {
+			return errors.New("UPDATE and DELETE must have a WHERE clause")
+		}
+		return nil
+	}
+}
+
+// audit evaluates audit rules on the statement executed by the call
+// and remembers violations to be reported by ExpectationsWereMet
+func (c *pgxmock) audit(call *Call) {
+	if call.SQL == "" {
+		return
+	}
+	for _, rule := range c.auditRules {
+		if err := rule(call); err != nil {
+			c.addViolation(fmt.Sprintf("%s with query '%s' violates audit rule: %v", call.Method, call.SQL, err))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package pgxmock
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditRules(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	for _, tc := range []struct {
+		rule AuditRule
+		sql  string
+		ok   bool
+	}{
+		{AuditNoSelectStar(), "SELECT * FROM users", false},
+		{AuditNoSelectStar(), "select distinct u.* from users u", false},
+		{AuditNoSelectStar(), "SELECT count(*) FROM users", true},
+		{AuditNoSelectStar(), "SELECT id, name FROM users", true},
+		{AuditPlaceholdersOnly(), "SELECT id FROM users WHERE name = 'john'", false},
+		{AuditPlaceholdersOnly(), "SELECT id FROM users WHERE id = 42", false},
+		{AuditPlaceholdersOnly(), "SELECT id FROM users WHERE id IN (1, 2)", false},
+		{AuditPlaceholdersOnly(), "SELECT id FROM users WHERE id = $1 AND name = $2", true},
+		{AuditPlaceholdersOnly(), "SELECT id FROM users2 WHERE id = @id", true},
+		{AuditWhereRequired(), "DELETE FROM users", false},
+		{AuditWhereRequired(), "update users set name = $1", false},
+		{AuditWhereRequired(), "UPDATE users SET name = $1 WHERE id = $2", true},
+		{AuditWhereRequired(), "SELECT id FROM users", true},
+	} {
+		err := tc.rule(&Call{Method: "Query()", SQL: tc.sql})
+		a.Equal(tc.ok, err == nil, tc.sql)
+	}
+}
+
+func TestAuditRulesOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	custom := func(call *Call) error {
+		if len(call.Args) > 2 {
+			return errors.New("too many arguments")
+		}
+		return nil
+	}
+	mock, _ := NewConn(AuditRulesOption(AuditNoSelectStar(), AuditWhereRequired(), custom))
+	mock.ExpectQuery("SELECT").WillReturnRows(NewRows([]string{"id"}))
+	mock.ExpectExec("DELETE").WithArgs(1, 2, 3).WillReturnResult(NewResult("DELETE", 1))
+	mock.ExpectPing()
+
+	_, err := mock.Query(ctx, "SELECT * FROM users")
+	a.NoError(err, "audit rules do not fail calls")
+	_, err = mock.Exec(ctx, "DELETE FROM users", 1, 2, 3)
+	a.NoError(err)
+	a.NoError(mock.Ping(ctx))
+	err = mock.ExpectationsWereMet()
+	a.ErrorContains(err, "there were 3 violations")
+	a.ErrorContains(err, "Query() with query 'SELECT * FROM users' violates audit rule: SELECT * is not allowed")
+	a.ErrorContains(err, "violates audit rule: UPDATE and DELETE must have a WHERE clause")
+	a.ErrorContains(err, "violates audit rule: too many arguments")
+}
+
+func TestAuditRulesConcurrent(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewPool(AuditRulesOption(AuditPlaceholdersOnly()))
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectQuery("SELECT").WillReturnRows(NewRows([]string{"id"})).Times(20)
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := mock.Query(ctx, "SELECT id FROM users WHERE id = 1")
+			a.NoError(err)
+		}()
+	}
+	wg.Wait()
+	a.ErrorContains(mock.ExpectationsWereMet(), "there were 20 violations")
+}
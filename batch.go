@@ -9,7 +9,9 @@ import (
 )
 
 type batchResults struct {
+	ctx           context.Context // SendBatch context used for queued queries and tracing
 	mock          *pgxmock
+	tx            *pgxmockTx // transaction the batch is sent in, nil for the mock
 	batch         *pgx.Batch
 	expectedBatch *ExpectedBatch
 	qqIdx         int
@@ -38,7 +40,9 @@ func (br *batchResults) Exec() (pgconn.CommandTag, error) {
 	if err != nil {
 		return pgconn.NewCommandTag(""), err
 	}
-	return br.mock.Exec(context.Background(), query, arguments...)
+	tag, err := br.mock.exec(br.ctx, br.tx, query, arguments...)
+	br.mock.traceBatchQuery(br.ctx, query, arguments, tag, err)
+	return tag, err
 }
 
 func (br *batchResults) Query() (pgx.Rows, error) {
@@ -46,7 +50,9 @@ func (br *batchResults) Query() (pgx.Rows, error) {
 	if err != nil {
 		return nil, err
 	}
-	return br.mock.Query(context.Background(), query, arguments...)
+	rows, err := br.mock.query(br.ctx, br.tx, query, arguments...)
+	br.mock.traceBatchQuery(br.ctx, query, arguments, rowsCommandTag(rows), err)
+	return rows, err
 }
 
 func (br *batchResults) QueryRow() pgx.Row {
@@ -54,10 +60,21 @@ func (br *batchResults) QueryRow() pgx.Row {
 	if err != nil {
 		return errRow{err: err}
 	}
-	return br.mock.QueryRow(context.Background(), query, arguments...)
+	rows, err := br.mock.query(br.ctx, br.tx, query, arguments...)
+	br.mock.traceBatchQuery(br.ctx, query, arguments, pgconn.CommandTag{}, err)
+	if err != nil {
+		return errRow{err: err}
+	}
+	rs := rows.(*rowSets)
+	rs.singleRow = true
+	rs.driverBytes = br.mock.driverBytes
+	return (*connRow)(rs)
 }
 
 func (br *batchResults) Close() error {
+	if br.expectedBatch == nil || !br.expectedBatch.closed {
+		defer func() { br.mock.traceBatchEnd(br.ctx, br.err) }()
+	}
 	if br.err != nil {
 		return br.err
 	}
@@ -67,7 +84,9 @@ func (br *batchResults) Close() error {
 			br.err = errors.Join(br.err, br.callQuedQueryFn(qq))
 		}
 	}
-	br.expectedBatch.closed = true
+	if br.expectedBatch != nil {
+		br.expectedBatch.closed = true
+	}
 	return br.err
 }
 
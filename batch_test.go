@@ -1,6 +1,7 @@
 package pgxmock
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -124,3 +125,75 @@ func TestUnorderedBatchExpectations(t *testing.T) {
 	a.NoError(err)
 	a.NoError(mock.ExpectationsWereMet())
 }
+
+func TestBatchInTransaction(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+	batch := &pgx.Batch{}
+	batch.Queue("UPDATE t SET x = 1")
+
+	// the batch sent in the transaction or its savepoint is matched
+	mock, err := NewPool()
+	a.NoError(err)
+	begin := mock.ExpectBegin()
+	begin.ExpectBatch().ExpectExec("UPDATE").WillReturnResult(NewResult("UPDATE", 1))
+	mock.ExpectBegin()
+	begin.ExpectBatch().ExpectExec("UPDATE").WillReturnResult(NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	mock.ExpectCommit()
+	tx, err := mock.Begin(ctx)
+	a.NoError(err)
+	br := tx.SendBatch(ctx, batch)
+	_, err = br.Exec()
+	a.NoError(err)
+	a.NoError(br.Close())
+	savepoint, err := tx.Begin(ctx)
+	a.NoError(err)
+	a.NoError(savepoint.SendBatch(ctx, batch).Close())
+	a.NoError(savepoint.Commit(ctx))
+	a.NoError(tx.Commit(ctx))
+	a.NoError(mock.ExpectationsWereMet())
+
+	// the batch sent on the pool instead of the transaction is not matched
+	mock, err = NewPool()
+	a.NoError(err)
+	begin = mock.ExpectBegin()
+	begin.ExpectBatch().ExpectExec("UPDATE").WillReturnResult(NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	tx, _ = mock.Begin(ctx)
+	err = mock.SendBatch(ctx, batch).Close()
+	a.ErrorIs(err, ErrUnexpectedCall)
+	a.ErrorContains(err, "SendBatch: call was expected to be issued in the transaction begun at batch_test.go:")
+	a.NoError(tx.SendBatch(ctx, batch).Close())
+	a.NoError(tx.Commit(ctx))
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestBatchContext(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	type key struct{}
+	withKey := func(ctx context.Context) error {
+		if ctx.Value(key{}) == nil {
+			return errors.New("context without key")
+		}
+		return nil
+	}
+
+	eb := mock.ExpectBatch()
+	eb.ExpectQuery("select").WillReturnRows(NewRows([]string{"sum"}).AddRow(2)).WithContext(withKey)
+	eb.ExpectExec("update").WillReturnResult(NewResult("UPDATE", 1)).WithContext(withKey)
+
+	batch := &pgx.Batch{}
+	batch.Queue("select 1 + 1")
+	batch.Queue("update users set active = true")
+	br := mock.SendBatch(context.WithValue(ctx, key{}, true), batch)
+	var n int
+	a.NoError(br.QueryRow().Scan(&n))
+	_, err := br.Exec()
+	a.NoError(err)
+	a.NoError(br.Close())
+	a.NoError(mock.ExpectationsWereMet())
+}
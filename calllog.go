@@ -0,0 +1,164 @@
+package pgxmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// loggedCall is a single entry of the mock call log
+type loggedCall struct {
+	Method      string        `json:"method"`
+	SQL         string        `json:"sql,omitempty"`
+	Args        []string      `json:"args,omitempty"`
+	Expectation string        `json:"expectation,omitempty"`
+	Duration    time.Duration `json:"duration"`
+	Delay       time.Duration `json:"delay,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	start       time.Time
+}
+
+// callLog keeps all calls made to the mock
+type callLog struct {
+	sync.Mutex
+	calls []loggedCall
+}
+
+// logCall appends the finished call to the mock call log
+func (c *pgxmock) logCall(call *Call, start time.Time, duration time.Duration, err error) {
+	lc := loggedCall{Method: call.Method, SQL: call.SQL, Duration: duration, start: start}
+	candidates := []expectation{call.expectation}
+	if call.expectation == nil && len(call.Args) > 0 {
+		// it is unknown which expectation the call was meant for
+		candidates = c.declared()
+	}
+	for i, arg := range call.Args {
+		if sensitiveAt(candidates, i) {
+			lc.Args = append(lc.Args, Redacted)
+			continue
+		}
+		lc.Args = append(lc.Args, fmt.Sprintf("%+v", arg))
+	}
+	if call.expectation != nil {
+		lc.Expectation = c.expectationLabel(call.expectation)
+		lc.Delay = call.expectation.delay()
+	}
+	if err != nil {
+		lc.Error = err.Error()
+	}
+	c.callLog.Lock()
+	defer c.callLog.Unlock()
+	c.callLog.calls = append(c.callLog.calls, lc)
+}
+
+// sensitiveAt reports whether the argument at position i
+// is marked with Sensitive by any of the query expectations
+func sensitiveAt(expectations []expectation, i int) bool {
+	for _, e := range expectations {
+		var eargs []interface{}
+		switch e := e.(type) {
+		case *ExpectedQuery:
+			eargs = e.args
+		case *ExpectedExec:
+			eargs = e.args
+		}
+		if i < len(eargs) && isSensitive(eargs[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// expectationLabel returns the short name of the expectation,
+// e.g. "ExpectedExec #2", where the number is the position of
+// the expectation in the order it was declared
+func (c *pgxmock) expectationLabel(e expectation) string {
+	name := reflect.TypeOf(e).Elem().Name()
+	for i, next := range c.declared() {
+		if next == e {
+			return fmt.Sprintf("%s #%d", name, i)
+		}
+	}
+	return name
+}
+
+// CallLogJSON returns every call made to the mock in JSON format.
+// Each call entry contains the method, SQL and stringified arguments
+// if any, the label of the matched expectation, the duration of the
+// call in nanoseconds and the error returned, if any.
+func (c *pgxmock) CallLogJSON() ([]byte, error) {
+	c.callLog.Lock()
+	defer c.callLog.Unlock()
+	calls := c.callLog.calls
+	if calls == nil {
+		calls = []loggedCall{}
+	}
+	return json.Marshal(calls)
+}
+
+// CallTiming is the timing of a single call to the mock
+type CallTiming struct {
+	Method      string
+	SQL         string
+	Expectation string
+	// Duration is measured with the mock clock
+	Duration time.Duration
+	// Delay is the simulated delay planned with WillDelayFor
+	Delay time.Duration
+}
+
+// TimingReport summarizes the timing of all calls made to the mock
+type TimingReport struct {
+	Calls []CallTiming
+	// Total is the sum of all calls durations
+	Total time.Duration
+	// TotalDelay is the sum of all simulated delays
+	TotalDelay time.Duration
+	// Elapsed is the time from the start of the first call till the end
+	// of the last one. Elapsed close to Total means calls were sequential.
+	Elapsed time.Duration
+	// Slowest is the call with the longest duration
+	Slowest CallTiming
+}
+
+// String returns the report as text, one call per line
+func (r TimingReport) String() string {
+	w := new(strings.Builder)
+	for i, call := range r.Calls {
+		fmt.Fprintf(w, "%d. %s %s: %v", i+1, call.Method, call.SQL, call.Duration)
+		if call.Delay > 0 {
+			fmt.Fprintf(w, " (delay %v)", call.Delay)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "calls: %d, total: %v, delay: %v, elapsed: %v\n", len(r.Calls), r.Total, r.TotalDelay, r.Elapsed)
+	return w.String()
+}
+
+// TimingReport returns the timing summary of all calls made to the mock.
+func (c *pgxmock) TimingReport() TimingReport {
+	c.callLog.Lock()
+	defer c.callLog.Unlock()
+	var r TimingReport
+	var first, last time.Time
+	for i, lc := range c.callLog.calls {
+		ct := CallTiming{Method: lc.Method, SQL: lc.SQL, Expectation: lc.Expectation, Duration: lc.Duration, Delay: lc.Delay}
+		r.Calls = append(r.Calls, ct)
+		r.Total += lc.Duration
+		r.TotalDelay += lc.Delay
+		if ct.Duration > r.Slowest.Duration {
+			r.Slowest = ct
+		}
+		if end := lc.start.Add(lc.Duration); i == 0 || end.After(last) {
+			last = end
+		}
+		if i == 0 || lc.start.Before(first) {
+			first = lc.start
+		}
+	}
+	r.Elapsed = last.Sub(first)
+	return r
+}
@@ -0,0 +1,74 @@
+package pgxmock
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallLogJSON(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	js, err := mock.CallLogJSON()
+	a.NoError(err)
+	a.JSONEq(`[]`, string(js))
+
+	mock.ExpectPing()
+	mock.ExpectExec("UPDATE").WithArgs(42, "foo").WillReturnError(errors.New("oops"))
+
+	a.NoError(mock.Ping(ctx))
+	_, err = mock.Exec(ctx, "UPDATE foo SET bar = $2 WHERE id = $1", 42, "foo")
+	a.Error(err)
+	a.Error(mock.Ping(ctx))
+
+	js, err = mock.CallLogJSON()
+	a.NoError(err)
+	var calls []map[string]any
+	a.NoError(json.Unmarshal(js, &calls))
+	a.Len(calls, 3)
+	a.Equal("Ping()", calls[0]["method"])
+	a.Equal("ExpectedPing #0", calls[0]["expectation"])
+	a.NotContains(calls[0], "error")
+	a.Equal("Exec()", calls[1]["method"])
+	a.Equal("UPDATE foo SET bar = $2 WHERE id = $1", calls[1]["sql"])
+	a.Equal([]any{"42", "foo"}, calls[1]["args"])
+	a.Equal("ExpectedExec #1", calls[1]["expectation"])
+	a.Equal("oops", calls[1]["error"])
+	a.NotContains(calls[2], "expectation")
+	a.Contains(calls[2]["error"], "all expectations were already fulfilled")
+}
+
+func TestTimingReport(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	clock := &fakeClock{}
+	mock, _ := NewConn(ClockOption(clock))
+	a.Empty(mock.TimingReport().Calls)
+
+	mock.ExpectPing().WillDelayFor(time.Second)
+	mock.ExpectExec("UPDATE").WillReturnResult(NewResult("UPDATE", 1)).WillDelayFor(2 * time.Second)
+	advance := func(d time.Duration) {
+		a.Eventually(func() bool { return clock.pending() == 1 }, time.Second, time.Millisecond)
+		clock.Advance(d)
+	}
+	go advance(time.Second)
+	a.NoError(mock.Ping(ctx))
+	go advance(2 * time.Second)
+	_, err := mock.Exec(ctx, "UPDATE users")
+	a.NoError(err)
+
+	r := mock.TimingReport()
+	a.Len(r.Calls, 2)
+	a.Equal(3*time.Second, r.Total)
+	a.Equal(3*time.Second, r.TotalDelay)
+	a.Equal(3*time.Second, r.Elapsed)
+	a.Equal("UPDATE users", r.Slowest.SQL)
+	a.Equal("ExpectedExec #1", r.Slowest.Expectation)
+	a.Contains(r.String(), "2. Exec() UPDATE users: 2s (delay 2s)")
+	a.Contains(r.String(), "calls: 2, total: 3s, delay: 3s, elapsed: 3s")
+}
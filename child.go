@@ -0,0 +1,133 @@
+package pgxmock
+
+import "sync"
+
+// TestingT is the subset of testing.TB used by pgxmock
+// to report failures and to register cleanup functions.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Cleanup(func())
+}
+
+// expectationList keeps expectations of the mock and its scopes. Every
+// expectation has the key of the scope it was declared on, so the calls
+// to the mock and to every scope match only their own expectations.
+type expectationList struct {
+	sync.Mutex
+	items  []scopedExpectation
+	scopes int // the last scope key given out
+}
+
+// scopedExpectation is the expectation declared on the scope
+type scopedExpectation struct {
+	expectation
+	scope int
+}
+
+// add appends the expectation declared on the scope
+func (l *expectationList) add(scope int, e expectation) {
+	l.Lock()
+	defer l.Unlock()
+	l.items = append(l.items, scopedExpectation{expectation: e, scope: scope})
+}
+
+// of returns expectations declared on the scope in the declaration order
+func (l *expectationList) of(scope int) []expectation {
+	l.Lock()
+	defer l.Unlock()
+	var expectations []expectation
+	for _, item := range l.items {
+		if item.scope == scope {
+			expectations = append(expectations, item.expectation)
+		}
+	}
+	return expectations
+}
+
+// newScope returns the key of the new scope
+func (l *expectationList) newScope() int {
+	l.Lock()
+	defer l.Unlock()
+	l.scopes++
+	return l.scopes
+}
+
+// declared returns expectations declared on the mock or on the scope
+func (c *pgxmock) declared() []expectation {
+	return c.expectations.of(c.scope)
+}
+
+// scoped returns the child mock sharing the expectation list and the
+// in-memory tables of the mock. Expectations declared on the child are
+// matched only by calls to it. The child is never required to be closed,
+// since it shares the connection or the pool of the mock.
+func (c *pgxmock) scoped() pgxmock {
+	s := c.child()
+	s.store = c.store
+	s.expectations = c.expectations
+	s.scope = c.expectations.newScope()
+	s.requireClose = false
+	return s
+}
+
+// child returns a new mock sharing all settings
+// of the mock, but without any expectations
+func (c *pgxmock) child() pgxmock {
+	return pgxmock{
+		ordered:             c.ordered,
+		queryMatcher:        c.queryMatcher,
+		clock:               c.clock,
+		middlewares:         append([]Middleware(nil), c.middlewares...),
+		callLog:             &callLog{},
+		expectations:        &expectationList{},
+		csvParser:           c.csvParser,
+		types:               c.types,
+		callStacks:          c.callStacks,
+		rowsMustBeClosed:    c.rowsMustBeClosed,
+		rowsMustBeFullyRead: c.rowsMustBeFullyRead,
+		strictConn:          c.strictConn,
+		tracer:              c.tracer,
+		onMatched:           c.onMatched,
+		encodableArgs:       c.encodableArgs,
+		rewriterConn:        c.rewriterConn,
+		forbidden:           c.forbiddenPatterns(),
+		auditRules:          c.auditRules,
+		defaultDelay:        c.defaultDelay,
+		orderByType:         c.orderByType,
+		verifyPoolClose:     c.verifyPoolClose,
+		detectRowsLeaks:     c.detectRowsLeaks,
+		driverBytes:         c.driverBytes,
+		createdAt:           c.clock.Now(),
+		store:               &store{primary: c.store.primary, lag: c.store.lag},
+		txMu:                &sync.Mutex{},
+		mu:                  &sync.Mutex{},
+		readOnly:            c.readOnly,
+		txPooling:           c.txPooling,
+		conns:               connSlots(cap(c.conns)),
+		poolHooks:           c.poolHooks,
+		errorFormatter:      c.errorFormatter,
+		verifyTx:            c.verifyTx,
+		requireClose:        c.requireClose,
+		reporter:            c.reporter,
+	}
+}
+
+// verifyOnCleanup checks whether all expectations
+// were met when the test and its subtests complete
+func (c *pgxmock) verifyOnCleanup(t TestingT) {
+	t.Helper()
+	t.Cleanup(func() {
+		if err := c.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %s", err)
+		}
+	})
+}
+
+// connSlots returns the semaphore of n pool connections, nil if unlimited
+func connSlots(n int) chan struct{} {
+	if n == 0 {
+		return nil
+	}
+	return make(chan struct{}, n)
+}
@@ -0,0 +1,37 @@
+package pgxmock
+
+import "time"
+
+// Clock is a source of time used by pgxmock to simulate
+// delayed execution. It can be replaced by a fake implementation
+// with the ClockOption to avoid real sleeping in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends
+	// the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the default Clock based on the time package.
+// Since it relies on time.After only, delays are faked automatically
+// when the mock is used inside of a testing/synctest bubble.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// scaledClock scales all delays, see TimeScaleOption
+type scaledClock struct {
+	Clock
+	scale float64
+}
+
+func (c scaledClock) After(d time.Duration) <-chan time.Time {
+	return c.Clock.After(time.Duration(float64(d) * c.scale))
+}
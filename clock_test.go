@@ -0,0 +1,132 @@
+package pgxmock
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock fires After channels only when Advance is called
+type fakeClock struct {
+	sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.Lock()
+	defer c.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{deadline: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.now = c.now.Add(d)
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(c.now) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = waiters
+}
+
+func (c *fakeClock) pending() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.waiters)
+}
+
+func TestClockOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	clock := &fakeClock{}
+	mock, err := NewConn(ClockOption(clock))
+	a.NoError(err)
+
+	mock.ExpectPing().WillDelayFor(time.Hour)
+
+	done := make(chan error)
+	go func() { done <- mock.Ping(ctx) }()
+
+	a.Eventually(func() bool { return clock.pending() == 1 }, time.Second, time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("Ping() must be delayed until the clock is advanced")
+	default:
+	}
+	clock.Advance(time.Hour)
+	a.NoError(<-done)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestWithin(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	clock := &fakeClock{}
+	mock, err := NewConn(ClockOption(clock))
+	a.NoError(err)
+
+	mock.ExpectPing().Within(time.Second)
+	mock.ExpectExec("UPDATE").WillReturnResult(NewResult("UPDATE", 1)).Within(time.Minute)
+	mock.ExpectPing().Within(time.Second)
+	a.Contains(mock.ExpectationsWereMet().Error(), "fulfilled within: 1s")
+
+	clock.Advance(time.Millisecond)
+	a.NoError(mock.Ping(ctx))
+	clock.Advance(time.Minute) // measured since the first Ping
+	_, err = mock.Exec(ctx, "UPDATE users")
+	a.NoError(err)
+	clock.Advance(2 * time.Second)
+	a.NoError(mock.Ping(ctx), "late calls are not failed")
+
+	err = mock.ExpectationsWereMet()
+	a.ErrorContains(err, "there were 1 violations")
+	a.ErrorContains(err, "was fulfilled in 2s, but expected within 1s")
+}
+
+func TestTimeScaleOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	_, err := NewConn(TimeScaleOption(0))
+	a.EqualError(err, "time scale must be positive, got 0")
+
+	mock, err := NewConn(TimeScaleOption(0.001))
+	a.NoError(err)
+	mock.ExpectPing().WillDelayFor(10 * time.Second)
+	start := time.Now()
+	a.NoError(mock.Ping(ctx))
+	a.Less(time.Since(start), 5*time.Second)
+	a.Equal(10*time.Second, mock.TimingReport().TotalDelay)
+
+	clock := &fakeClock{}
+	mock, _ = NewConn(TimeScaleOption(0.5), ClockOption(clock))
+	mock.ExpectPing().WillDelayFor(time.Hour)
+	done := make(chan error)
+	go func() { done <- mock.Ping(ctx) }()
+	a.Eventually(func() bool { return clock.pending() == 1 }, time.Second, time.Millisecond)
+	clock.Advance(30 * time.Minute)
+	a.NoError(<-done)
+}
@@ -0,0 +1,163 @@
+package pgxmock
+
+// cloneCommon copies the expectation definition
+// to c without the calls already made
+func (e *commonExpectation) cloneCommon(c *commonExpectation) {
+	e.Lock()
+	defer e.Unlock()
+	c.err = e.err
+	c.optional = e.optional
+	c.panicArgument = e.panicArgument
+	c.panicOnCall = e.panicOnCall
+	c.errOnCall = e.errOnCall
+	c.callErr = e.callErr
+	c.plannedDelay = e.plannedDelay
+	c.release = e.release
+	c.plannedCalls = e.plannedCalls
+	c.beforeCall = e.beforeCall
+	c.afterCall = e.afterCall
+	c.requiredState = e.requiredState
+	c.nextState = e.nextState
+	c.declSite = e.declSite
+	c.ctxMatcher = e.ctxMatcher
+	c.matchPriority = e.matchPriority
+	c.within = e.within
+}
+
+func (e *ExpectedClose) clone(_ *pgxmock) expectation {
+	c := &ExpectedClose{}
+	e.cloneCommon(&c.commonExpectation)
+	return c
+}
+
+func (e *ExpectedBegin) clone(mock *pgxmock) expectation {
+	c := &ExpectedBegin{mock: mock, opts: e.opts}
+	e.cloneCommon(&c.commonExpectation)
+	return c
+}
+
+func (e *ExpectedCommit) clone(_ *pgxmock) expectation {
+	c := &ExpectedCommit{}
+	e.cloneCommon(&c.commonExpectation)
+	return c
+}
+
+func (e *ExpectedExec) clone(_ *pgxmock) expectation {
+	c := &ExpectedExec{queryBasedExpectation: e.queryBasedExpectation, result: e.result}
+	e.cloneCommon(&c.commonExpectation)
+	return c
+}
+
+// clone returns the batch expectation with the queries and the transaction
+// not set, since they must point to the cloned expectations
+func (e *ExpectedBatch) clone(mock *pgxmock) expectation {
+	c := &ExpectedBatch{mock: mock, mustBeClosed: e.mustBeClosed}
+	e.cloneCommon(&c.commonExpectation)
+	return c
+}
+
+func (e *ExpectedPrepare) clone(_ *pgxmock) expectation {
+	c := &ExpectedPrepare{stmtNameExpectation: e.stmtNameExpectation, expectSQL: e.expectSQL}
+	e.cloneCommon(&c.commonExpectation)
+	return c
+}
+
+func (e *ExpectedDeallocate) clone(_ *pgxmock) expectation {
+	c := &ExpectedDeallocate{stmtNameExpectation: e.stmtNameExpectation, expectAll: e.expectAll}
+	e.cloneCommon(&c.commonExpectation)
+	return c
+}
+
+func (e *ExpectedCancelRequest) clone(_ *pgxmock) expectation {
+	c := &ExpectedCancelRequest{}
+	e.cloneCommon(&c.commonExpectation)
+	return c
+}
+
+func (e *ExpectedPing) clone(_ *pgxmock) expectation {
+	c := &ExpectedPing{}
+	e.cloneCommon(&c.commonExpectation)
+	return c
+}
+
+func (e *ExpectedQuery) clone(_ *pgxmock) expectation {
+	c := &ExpectedQuery{
+		queryBasedExpectation: e.queryBasedExpectation,
+		rowsMustBeClosed:      e.rowsMustBeClosed,
+		rowsMustBeFullyRead:   e.rowsMustBeFullyRead,
+		resultFormats:         e.resultFormats,
+		pages:                 e.pages,
+	}
+	e.cloneCommon(&c.commonExpectation)
+	c.rows = e.rows
+	if rs, ok := e.rows.(*rowSets); ok {
+		sets := make([]*Rows, len(rs.sets))
+		for i, r := range rs.sets {
+			sets[i] = r.Clone()
+		}
+		c.rows = &rowSets{sets: sets, ex: c}
+	}
+	return c
+}
+
+// clone returns the copy expectation with the transaction not
+// set, since it must point to the cloned begin expectation
+func (e *ExpectedCopyFrom) clone(_ *pgxmock) expectation {
+	c := &ExpectedCopyFrom{
+		expectedTableName: e.expectedTableName,
+		expectedColumns:   e.expectedColumns,
+		rowsAffected:      e.rowsAffected,
+		columnTypes:       e.columnTypes,
+		expectedRows:      e.expectedRows,
+	}
+	e.cloneCommon(&c.commonExpectation)
+	return c
+}
+
+func (e *ExpectedReset) clone(_ *pgxmock) expectation {
+	c := &ExpectedReset{}
+	e.cloneCommon(&c.commonExpectation)
+	return c
+}
+
+func (e *ExpectedRollback) clone(_ *pgxmock) expectation {
+	c := &ExpectedRollback{}
+	e.cloneCommon(&c.commonExpectation)
+	return c
+}
+
+// cloneExpectationsTo adds the same expectations as the mock
+// has to the mock m, but none of them fulfilled
+func (c *pgxmock) cloneExpectationsTo(m *pgxmock) {
+	clones := make(map[*queryBasedExpectation]*queryBasedExpectation)
+	begins := make(map[*ExpectedBegin]*ExpectedBegin)
+	expectations := c.declared()
+	cloned := make([]expectation, len(expectations))
+	for i, e := range expectations {
+		ce := e.clone(m)
+		switch e := e.(type) {
+		case *ExpectedBegin:
+			begins[e] = ce.(*ExpectedBegin)
+		case *ExpectedExec:
+			clones[&e.queryBasedExpectation] = &ce.(*ExpectedExec).queryBasedExpectation
+		case *ExpectedQuery:
+			clones[&e.queryBasedExpectation] = &ce.(*ExpectedQuery).queryBasedExpectation
+		}
+		cloned[i] = ce
+	}
+	for i, e := range expectations {
+		switch e := e.(type) {
+		case *ExpectedBatch:
+			cb := cloned[i].(*ExpectedBatch)
+			for _, q := range e.expectedQueries {
+				cb.expectedQueries = append(cb.expectedQueries, clones[q])
+			}
+		}
+		if begin := e.requiredTx(); begin != nil {
+			cloned[i].setRequiredTx(begins[begin])
+		}
+	}
+	for _, ce := range cloned {
+		m.expectations.add(m.scope, ce)
+	}
+}
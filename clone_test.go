@@ -0,0 +1,45 @@
+package pgxmock
+
+import (
+	"testing"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloneWithExpectations(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	base, _ := NewPool()
+	base.ExpectBegin()
+	base.ExpectQuery("SELECT id").WithArgs(1).
+		WillReturnRows(NewRows([]string{"id"}).AddRow(1)).RowsWillBeClosed()
+	eb := base.ExpectBatch()
+	eb.ExpectExec("UPDATE").WillReturnResult(NewResult("UPDATE", 1))
+	base.ExpectCommit()
+
+	for _, mock := range []PgxPoolIface{base.CloneWithExpectations(), base.CloneWithExpectations()} {
+		a.Len(mock.PendingExpectations(), 5)
+		_, err := mock.Begin(ctx)
+		a.NoError(err)
+		rows, err := mock.Query(ctx, "SELECT id", 1)
+		a.NoError(err)
+		a.True(rows.Next())
+		rows.Close()
+		b := &pgx.Batch{}
+		b.Queue("UPDATE foo")
+		a.NoError(mock.SendBatch(ctx, b).Close())
+		a.NoError(mock.Commit(ctx))
+		a.NoError(mock.ExpectationsWereMet())
+	}
+	a.Len(base.PendingExpectations(), 5)
+
+	conn, _ := NewConn()
+	conn.ExpectPing().Times(2)
+	clone := conn.CloneWithExpectations()
+	a.NoError(clone.Ping(ctx))
+	a.NoError(clone.Ping(ctx))
+	a.NoError(clone.ExpectationsWereMet())
+	a.Error(conn.ExpectationsWereMet())
+}
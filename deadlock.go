@@ -0,0 +1,86 @@
+package pgxmock
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Deadlock describes two concurrent transactions locking the same
+// rows in the opposite order, expected by ExpectDeadlock.
+type Deadlock struct {
+	// Victim are the statements of the transaction aborted by the deadlock
+	Victim []string
+	// Survivor are the statements of the transaction proceeding after it
+	Survivor []string
+	// At is the index of the statement both transactions wait for each
+	// other at. It fails in the victim and succeeds in the survivor
+	At int
+	// Retry expects the victim transaction to run again and commit
+	Retry bool
+}
+
+// ExpectDeadlock declares two transactions on the mock deadlocking at the
+// statement At. The statement of the victim is held until the survivor
+// reaches its statement At and then fails with NewDeadlockError, while the
+// statement of the survivor is held until the victim fails, so the outcome
+// does not depend on the goroutines scheduling. The victim transaction is
+// expected to be rolled back and, if Retry is set, to run all statements
+// again in the new transaction and commit.
+//
+// Transactions run concurrently, so the mock must match expectations in
+// any order, see MatchExpectationsInOrder. Statements are matched only in
+// their own transaction, so both may run the same SQL. The transaction
+// begun first is the survivor, the second one is the victim, which the
+// arguments set on the returned expectations must agree with. The victim
+// expectations are the statements up to At followed by all statements of
+// the retry if Retry is set.
+func ExpectDeadlock(mock Expecter, d Deadlock) (victim, survivor []*ExpectedExec) {
+	if d.At < 0 || d.At >= len(d.Victim) || d.At >= len(d.Survivor) {
+		panic(fmt.Sprintf("pgxmock: deadlock statement %d is out of range", d.At))
+	}
+	if m, ok := mock.(interface{ inOrder() bool }); ok && m.inOrder() {
+		panic("pgxmock: deadlock requires expectations matched in any order, call MatchExpectationsInOrder(false)")
+	}
+	blocked, failed := make(chan struct{}), make(chan struct{})
+	var blockedOnce, failedOnce sync.Once
+	// exec declares the statement issued in the transaction begun by begin
+	exec := func(begin *ExpectedBegin, stmt string) *ExpectedExec {
+		result := NewResult("SELECT", 1)
+		if cmd := writeStatement(stmt); cmd != "" {
+			result = NewResult(cmd, 1)
+		}
+		e := mock.ExpectExec(stmt).WillReturnResult(result)
+		e.setRequiredTx(begin)
+		return e
+	}
+
+	begin := mock.ExpectBegin()
+	survivor = make([]*ExpectedExec, len(d.Survivor))
+	for i, stmt := range d.Survivor {
+		survivor[i] = exec(begin, stmt)
+		if i == d.At {
+			survivor[i].Before(func() { blockedOnce.Do(func() { close(blocked) }) }).WillDelayUntil(failed)
+		}
+	}
+	mock.ExpectCommit().setRequiredTx(begin)
+
+	begin = mock.ExpectBegin()
+	for i, stmt := range d.Victim[:d.At+1] {
+		victim = append(victim, exec(begin, stmt))
+		if i == d.At {
+			victim[i].WillDelayUntil(blocked).
+				After(func(error) { failedOnce.Do(func() { close(failed) }) }).
+				WillReturnError(NewDeadlockError())
+		}
+	}
+	mock.ExpectRollback().setRequiredTx(begin)
+
+	if d.Retry {
+		begin = mock.ExpectBegin()
+		for _, stmt := range d.Victim {
+			victim = append(victim, exec(begin, stmt))
+		}
+		mock.ExpectCommit().setRequiredTx(begin)
+	}
+	return victim, survivor
+}
@@ -0,0 +1,78 @@
+package pgxmock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	pgconn "github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+// transfer moves money between accounts retrying on deadlocks
+func transfer(ctx context.Context, pool PgxPoolIface, from, to int, retries int) error {
+	for {
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err = tx.Exec(ctx, "UPDATE accounts SET balance = balance - 10 WHERE id = $1", from); err == nil {
+			_, err = tx.Exec(ctx, "UPDATE accounts SET balance = balance + 10 WHERE id = $1", to)
+		}
+		if err == nil {
+			return tx.Commit(ctx)
+		}
+		_ = tx.Rollback(ctx)
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != "40P01" || retries == 0 {
+			return err
+		}
+		retries--
+	}
+}
+
+func TestExpectDeadlock(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	stmts := []string{
+		"UPDATE accounts SET balance = balance - 10 WHERE id = $1",
+		"UPDATE accounts SET balance = balance + 10 WHERE id = $1",
+	}
+	for _, retries := range []int{0, 1} {
+		a := assert.New(t)
+		mock, _ := NewPool(QueryMatcherOption(QueryMatcherEqual))
+		mock.MatchExpectationsInOrder(false)
+		victim, survivor := ExpectDeadlock(mock, Deadlock{Victim: stmts, Survivor: stmts, At: 1, Retry: retries > 0})
+		a.Len(survivor, 2)
+		a.Len(victim, 2+2*retries)
+		for _, e := range append(victim, survivor...) {
+			e.WithArgs(AnyArg())
+		}
+
+		// both transactions run the same SQL, the one begun first survives
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		wg.Add(2)
+		go func() { defer wg.Done(); errs[0] = transfer(ctx, mock, 1, 2, retries) }()
+		go func() { defer wg.Done(); errs[1] = transfer(ctx, mock, 2, 1, retries) }()
+		wg.Wait()
+
+		if retries == 0 {
+			a.NotEqual(errs[0] == nil, errs[1] == nil, "exactly one transaction is the victim")
+			var pgErr *pgconn.PgError
+			a.ErrorAs(errors.Join(errs...), &pgErr)
+			a.Equal("40P01", pgErr.Code)
+		} else {
+			a.NoError(errors.Join(errs...))
+		}
+		a.NoError(mock.ExpectationsWereMet())
+	}
+
+	a := assert.New(t)
+	mock, _ := NewPool()
+	a.PanicsWithValue("pgxmock: deadlock requires expectations matched in any order, call MatchExpectationsInOrder(false)",
+		func() { ExpectDeadlock(mock, Deadlock{Victim: stmts, Survivor: stmts, At: 1}) })
+	mock.MatchExpectationsInOrder(false)
+	a.Panics(func() { ExpectDeadlock(mock, Deadlock{Victim: stmts, Survivor: stmts[:1], At: 1}) })
+}
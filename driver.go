@@ -17,7 +17,26 @@ type pgxmockConn struct {
 func NewConn(options ...func(*pgxmock) error) (PgxConnIface, error) {
 	smock := &pgxmockConn{}
 	smock.ordered = true
-	return smock, smock.open(options)
+	err := smock.open(options)
+	smock.verifyPoolClose = false
+	return smock, err
+}
+
+// Scope returns the child mock for the subtest. The child shares all settings,
+// the expectation list and the in-memory tables of the mock, but matches only
+// the expectations declared on it, which are verified on the subtest cleanup.
+func (c *pgxmockConn) Scope(t TestingT) PgxConnIface {
+	child := &pgxmockConn{pgxmock: c.scoped()}
+	child.verifyOnCleanup(t)
+	return child
+}
+
+// CloneWithExpectations returns the independent mock sharing all settings
+// and having the same expectations as the mock, but none of them fulfilled.
+func (c *pgxmockConn) CloneWithExpectations() PgxConnIface {
+	clone := &pgxmockConn{pgxmock: c.child()}
+	c.cloneExpectationsTo(&clone.pgxmock)
+	return clone
 }
 
 func (c *pgxmockConn) Config() *pgx.ConnConfig {
@@ -26,6 +45,7 @@ func (c *pgxmockConn) Config() *pgx.ConnConfig {
 
 type pgxmockPool struct {
 	pgxmock
+	idle []*pgxmockPooledConn // released connections guarded by the mock lock, see AcquireConn
 }
 
 // NewPool creates PgxPoolIface pool of database connections and a mock to manage expectations.
@@ -33,11 +53,44 @@ type pgxmockPool struct {
 func NewPool(options ...func(*pgxmock) error) (PgxPoolIface, error) {
 	smock := &pgxmockPool{}
 	smock.ordered = true
-	return smock, smock.open(options)
+	err := smock.open(options)
+	smock.strictConn = false
+	return smock, err
 }
 
+// Close matches the ExpectClose expectation. Since pgxpool.Pool.Close
+// returns no error, the unexpected call is reported by ExpectationsWereMet
+// if VerifyPoolCloseOption is set.
 func (p *pgxmockPool) Close() {
-	p.pgxmock.Close(context.Background())
+	call := &Call{Method: "Close()"}
+	first := !p.wasClosed()
+	// the error is not returned, so it is not reported to the test either
+	err := p.dispatch(context.Background(), call, p.close)
+	if p.verifyPoolClose {
+		// the first Close is expected implicitly, if not declared
+		if err != nil && call.expectation == nil && (!first || p.closeExpected()) {
+			p.addViolation("unexpected pool Close(): " + err.Error())
+		}
+		p.markClosed()
+	}
+	p.closeIdle()
+}
+
+// Scope returns the child mock for the subtest. The child shares all settings,
+// the expectation list and the in-memory tables of the mock, but matches only
+// the expectations declared on it, which are verified on the subtest cleanup.
+func (p *pgxmockPool) Scope(t TestingT) PgxPoolIface {
+	child := &pgxmockPool{pgxmock: p.scoped()}
+	child.verifyOnCleanup(t)
+	return child
+}
+
+// CloneWithExpectations returns the independent mock sharing all settings
+// and having the same expectations as the mock, but none of them fulfilled.
+func (p *pgxmockPool) CloneWithExpectations() PgxPoolIface {
+	clone := &pgxmockPool{pgxmock: p.child()}
+	p.cloneExpectationsTo(&clone.pgxmock)
+	return clone
 }
 
 func (p *pgxmockPool) Acquire(context.Context) (*pgxpool.Conn, error) {
@@ -45,7 +98,7 @@ func (p *pgxmockPool) Acquire(context.Context) (*pgxpool.Conn, error) {
 }
 
 func (p *pgxmockPool) Config() *pgxpool.Config {
-	return &pgxpool.Config{}
+	return &pgxpool.Config{MaxConns: int32(cap(p.conns))}
 }
 
 // AsConn is similar to Acquire but returns proper mocking interface
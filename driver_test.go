@@ -2,7 +2,14 @@ package pgxmock
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
 )
 
 func TestTwoOpenConnectionsOnTheSameDSN(t *testing.T) {
@@ -41,6 +48,43 @@ func TestPools(t *testing.T) {
 	mock2.Close()
 }
 
+func TestVerifyPoolCloseOption(t *testing.T) {
+	mock, _ := NewPool(VerifyPoolCloseOption(true))
+	mock.ExpectClose()
+	if err := mock.ExpectationsWereMet(); err == nil {
+		t.Error("expected error for the pool not closed, but got nil")
+	}
+	mock.Close()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected no error, but got: %s", err)
+	}
+	mock.Close()
+	err := mock.ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "unexpected pool Close()") {
+		t.Errorf("expected unexpected pool Close() error, but got: %v", err)
+	}
+
+	mock, _ = NewPool(VerifyPoolCloseOption(true))
+	if err := mock.ExpectationsWereMet(); err == nil || !strings.Contains(err.Error(), "Close() was never called") {
+		t.Errorf("expected missing Close() error, but got: %v", err)
+	}
+	mock.Close()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected no error for the implicitly expected Close(), but got: %s", err)
+	}
+
+	mock, _ = NewPool()
+	mock.Close()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected no error without the option, but got: %s", err)
+	}
+
+	conn, _ := NewConn(VerifyPoolCloseOption(true))
+	if err := conn.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected no error for the connection mock, but got: %s", err)
+	}
+}
+
 func TestAcquire(t *testing.T) {
 	mock, err := NewPool()
 	if err != nil {
@@ -62,3 +106,225 @@ func TestPoolStat(t *testing.T) {
 		t.Error("expected stat object, but got nil")
 	}
 }
+
+type testingTRecorder struct {
+	errors   []string
+	cleanups []func()
+}
+
+func (r *testingTRecorder) Helper() {}
+
+func (r *testingTRecorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *testingTRecorder) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *testingTRecorder) cleanup() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestScope(t *testing.T) {
+	mock, _ := NewPool(QueryMatcherOption(QueryMatcherEqual))
+	for _, id := range []int{1, 2, 3} {
+		t.Run(strconv.Itoa(id), func(t *testing.T) {
+			t.Parallel()
+			child := mock.Scope(t)
+			child.ExpectExec("DELETE FROM users WHERE id = $1").WithArgs(id).
+				WillReturnResult(NewResult("DELETE", 1))
+			if _, err := child.Exec(context.Background(), "DELETE FROM users WHERE id = $1", id); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	conn, _ := NewConn()
+	rec := &testingTRecorder{}
+	child := conn.Scope(rec)
+	child.ExpectPing()
+	if err := conn.Ping(context.Background()); err == nil {
+		t.Error("expectations of the child must not be visible to the parent")
+	}
+	rec.cleanup()
+	if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "ExpectedPing") {
+		t.Errorf("expected unfulfilled Ping() to be reported, got: %v", rec.errors)
+	}
+	if len(conn.PendingExpectations()) != 0 {
+		t.Error("expectations of the child must not be pending for the parent")
+	}
+
+	// scopes share seeded tables, but not the required close
+	conn, _ = NewConn(RequireCloseOption(true))
+	conn.SeedTable("users", NewRows([]string{"id"}).AddRow(1))
+	rec = &testingTRecorder{}
+	child = conn.Scope(rec)
+	if _, err := child.Exec(context.Background(), "INSERT INTO users VALUES ($1)", 2); err != nil {
+		t.Error(err)
+	}
+	if rows := conn.TableRows("users"); len(rows) != 2 {
+		t.Errorf("expected the table shared with the child, got: %v", rows)
+	}
+	rec.cleanup()
+	if len(rec.errors) != 0 {
+		t.Errorf("expected the child not required to be closed, got: %v", rec.errors)
+	}
+}
+
+func TestRequireVerificationOption(t *testing.T) {
+	rec := &testingTRecorder{}
+	_, _ = NewConn(RequireVerificationOption(rec))
+	mock, _ := NewPool(RequireVerificationOption(rec))
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected no error, but got: %s", err)
+	}
+	rec.cleanup()
+	if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "ExpectationsWereMet was never called for the mock created at driver_test.go:") {
+		t.Errorf("expected one error for the unverified mock, but got: %v", rec.errors)
+	}
+}
+
+func TestMaxConnsOption(t *testing.T) {
+	if _, err := NewPool(MaxConnsOption(0)); err == nil {
+		t.Error("expected error for non-positive max conns")
+	}
+	mock, err := NewPool(MaxConnsOption(2))
+	if err != nil {
+		t.Fatalf("expected no error, but got: %s", err)
+	}
+	if mock.Config().MaxConns != 2 {
+		t.Errorf("expected MaxConns 2, but got %d", mock.Config().MaxConns)
+	}
+	ctx := context.Background()
+	c1, err := mock.AcquireConn(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, but got: %s", err)
+	}
+	if _, err = mock.AcquireConn(ctx); err != nil {
+		t.Fatalf("expected no error, but got: %s", err)
+	}
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err = mock.AcquireConn(timeout); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, but got: %v", err)
+	}
+
+	acquired := make(chan PgxPooledConnIface)
+	go func() {
+		c, _ := mock.AcquireConn(ctx)
+		acquired <- c
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("expected AcquireConn to block until Release")
+	case <-time.After(10 * time.Millisecond):
+	}
+	c1.Release()
+	c1.Release() // ignored
+	if c := <-acquired; c == nil {
+		t.Error("expected the connection to be acquired after Release")
+	}
+	timeout, cancel = context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err = mock.AcquireConn(timeout); err == nil {
+		t.Error("expected the second Release to be ignored")
+	}
+}
+
+func TestAcquireConnUnlimited(t *testing.T) {
+	mock, err := NewPool()
+	if err != nil {
+		t.Fatalf("expected no error, but got: %s", err)
+	}
+	for range 10 {
+		if _, err := mock.AcquireConn(context.Background()); err != nil {
+			t.Fatalf("expected no error, but got: %s", err)
+		}
+	}
+}
+
+func TestSetTestReporter(t *testing.T) {
+	rec := &testingTRecorder{}
+	mock, _ := NewConn()
+	mock.SetTestReporter(rec)
+	mock.ExpectExec("UPDATE users").WithArgs(1).WillReturnResult(NewResult("UPDATE", 1))
+	// the code under test masks the error
+	_, _ = mock.Exec(context.Background(), "UPDATE users", 2)
+	if len(rec.errors) != 1 {
+		t.Fatalf("expected the mismatch to be reported, but got: %v", rec.errors)
+	}
+	if !strings.Contains(rec.errors[0], "argument 0 expected [int - 1] does not match actual [int - 2]") ||
+		!strings.Contains(rec.errors[0], "called at driver_test.go:") {
+		t.Errorf("expected the mismatch and the call site to be reported, but got: %s", rec.errors[0])
+	}
+	if _, err := mock.Exec(context.Background(), "UPDATE users", 1); err != nil {
+		t.Errorf("expected no error, but got: %s", err)
+	}
+	if len(rec.errors) != 1 {
+		t.Errorf("expected matched calls not to be reported, but got: %v", rec.errors)
+	}
+}
+
+func TestSetTestReporterClosedTx(t *testing.T) {
+	rec := &testingTRecorder{}
+	mock, _ := NewConn()
+	mock.SetTestReporter(rec)
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	tx, err := mock.Begin(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, but got: %s", err)
+	}
+	if err = tx.Commit(context.Background()); err != nil {
+		t.Errorf("expected no error, but got: %s", err)
+	}
+	// the deferred rollback after the successful commit
+	if err = tx.Rollback(context.Background()); !errors.Is(err, pgx.ErrTxClosed) {
+		t.Errorf("expected pgx.ErrTxClosed, but got: %v", err)
+	}
+	if len(rec.errors) != 0 {
+		t.Errorf("expected the rollback of the closed transaction not to be reported, but got: %v", rec.errors)
+	}
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected all expectations to be met, but got: %s", err)
+	}
+}
+
+func TestSetTestReporterSwallowedErrors(t *testing.T) {
+	rec := &testingTRecorder{}
+
+	// Close is expected implicitly with RequireCloseOption
+	conn, _ := NewConn(RequireCloseOption(true))
+	conn.SetTestReporter(rec)
+	if err := conn.Close(context.Background()); err != nil {
+		t.Errorf("expected no error, but got: %s", err)
+	}
+
+	// the first pool Close is expected implicitly with VerifyPoolCloseOption
+	pool, _ := NewPool(VerifyPoolCloseOption(true))
+	pool.SetTestReporter(rec)
+	pool.Close()
+
+	// calls matching no expectation are answered from the seeded tables
+	pool.SeedTable("users", NewRows([]string{"id", "name"}).AddRow(1, "john"))
+	var name string
+	if err := pool.QueryRow(context.Background(), "SELECT name FROM users WHERE id = $1", 1).Scan(&name); err != nil {
+		t.Errorf("expected no error, but got: %s", err)
+	}
+	if _, err := pool.Exec(context.Background(), "INSERT INTO users (id, name) VALUES ($1, $2)", 2, "jane"); err != nil {
+		t.Errorf("expected no error, but got: %s", err)
+	}
+	if len(rec.errors) != 0 {
+		t.Errorf("expected calls answered by the mock not to be reported, but got: %v", rec.errors)
+	}
+
+	if _, err := pool.Exec(context.Background(), "VACUUM"); err == nil {
+		t.Error("expected error for the unexpected call, but got nil")
+	}
+	if len(rec.errors) != 1 {
+		t.Errorf("expected the returned error to be reported, but got: %v", rec.errors)
+	}
+}
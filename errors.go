@@ -0,0 +1,157 @@
+package pgxmock
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors returned by the mock, so tests and helper libraries may
+// check failures with errors.Is instead of matching error messages.
+var (
+	// ErrUnexpectedCall is returned when a call matches no expectation,
+	// including the case when the next ordered expectation does not match
+	ErrUnexpectedCall = errors.New("unexpected call")
+	// ErrQueryMismatch is returned when the actual SQL does not match the expected one
+	ErrQueryMismatch = errors.New("query mismatch")
+	// ErrArgsMismatch is returned when the actual arguments do not match the expected ones
+	ErrArgsMismatch = errors.New("arguments mismatch")
+	// ErrUnmetExpectations is returned by ExpectationsWereMet
+	ErrUnmetExpectations = errors.New("unmet expectations")
+)
+
+// MismatchError describes why the call did not match the expectation, e.g.
+// for custom reporters. Mock errors wrap it, so use errors.As to get it.
+type MismatchError struct {
+	Method      string // the called method, e.g. "Query()"
+	ExpectedSQL string
+	ActualSQL   string
+	ArgIndex    int // the mismatched argument, -1 if SQL or the number of arguments mismatch
+	Expected    any // the expected argument, its Argument matcher or the number of arguments
+	Actual      any // the actual argument, Redacted if it is Sensitive, or the number of arguments
+	Err         error
+}
+
+func (e *MismatchError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *MismatchError) Unwrap() error {
+	return e.Err
+}
+
+// sqlMismatch returns the MismatchError for the error of QueryMatcher, nil if err is nil
+func sqlMismatch(expectedSQL, actualSQL string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &MismatchError{ExpectedSQL: expectedSQL, ActualSQL: actualSQL, ArgIndex: -1, Err: markError(ErrQueryMismatch, err)}
+}
+
+// argsMismatch returns the MismatchError for the argument at index i,
+// or for the number of arguments if i is -1
+func argsMismatch(expectedSQL, actualSQL string, i int, expected, actual any, format string, args ...any) error {
+	return &MismatchError{
+		ExpectedSQL: expectedSQL,
+		ActualSQL:   actualSQL,
+		ArgIndex:    i,
+		Expected:    expected,
+		Actual:      actual,
+		Err:         markError(ErrArgsMismatch, fmt.Errorf(format, args...)),
+	}
+}
+
+// lazyError formats the message only when needed, since the expectation
+// descriptions are expensive and errors of the candidate expectations
+// are discarded in the unordered mode
+type lazyError struct {
+	format string
+	args   []any
+}
+
+func (e *lazyError) Error() string {
+	return fmt.Sprintf(e.format, e.args...)
+}
+
+// lazyErrorf returns the error formatted according to
+// the format specifier when its message is requested
+func lazyErrorf(format string, args ...any) error {
+	return &lazyError{format: format, args: args}
+}
+
+// markedError is the error marked with a sentinel error,
+// which keeps the message of the original error intact
+type markedError struct {
+	sentinel error
+	err      error
+}
+
+func (e *markedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *markedError) Unwrap() []error {
+	return []error{e.sentinel, e.err}
+}
+
+// markError marks the error with the sentinel, nil if err is nil
+func markError(sentinel, err error) error {
+	if err == nil || errors.Is(err, sentinel) {
+		return err
+	}
+	return &markedError{sentinel: sentinel, err: err}
+}
+
+// formattedError is the mock error with the message
+// customized by the ErrorFormatterOption formatter
+type formattedError struct {
+	msg string
+	err error
+}
+
+func (e *formattedError) Error() string {
+	return e.msg
+}
+
+func (e *formattedError) Unwrap() error {
+	return e.err
+}
+
+// failedCall is the error about the failed call, which is reported to the
+// test only if returned to the caller, since the mock may swallow it, e.g.
+// answering the query from the in-memory tables
+type failedCall struct {
+	err error
+}
+
+func (e *failedCall) Error() string {
+	return e.err.Error()
+}
+
+func (e *failedCall) Unwrap() error {
+	return e.err
+}
+
+// failure returns the error about the failed call
+// with the call stack and the customized message
+func (c *pgxmock) failure(err error) error {
+	return &failedCall{err: c.formatError(c.withCallStack(err))}
+}
+
+// report reports the failed call to the test if SetTestReporter is set
+func (c *pgxmock) report(err error) {
+	var failed *failedCall
+	if c.reporter == nil || !errors.As(err, &failed) {
+		return
+	}
+	c.reporter.Helper()
+	c.reporter.Errorf("pgxmock: %s\ncalled at %s", err, declarationSite())
+}
+
+// formatError customizes the message of the mock error
+// if ErrorFormatterOption is set, nil if err is nil
+func (c *pgxmock) formatError(err error) error {
+	if err == nil || c.errorFormatter == nil {
+		return err
+	}
+	return &formattedError{msg: c.errorFormatter(err), err: err}
+}
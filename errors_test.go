@@ -0,0 +1,126 @@
+package pgxmock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+	mock, err := NewConn()
+	a.NoError(err)
+
+	_, err = mock.Exec(ctx, "DELETE FROM users")
+	a.ErrorIs(err, ErrUnexpectedCall)
+	a.EqualError(err, "all expectations were already fulfilled, call to method Exec() was not expected")
+
+	mock.ExpectExec("UPDATE users").WithArgs(1).WillReturnResult(NewResult("UPDATE", 1))
+	_, err = mock.Exec(ctx, "DELETE FROM users", 1)
+	a.ErrorIs(err, ErrUnexpectedCall)
+	a.ErrorIs(err, ErrQueryMismatch)
+	a.NotErrorIs(err, ErrArgsMismatch)
+
+	_, err = mock.Exec(ctx, "UPDATE users", 2)
+	a.ErrorIs(err, ErrUnexpectedCall)
+	a.ErrorIs(err, ErrArgsMismatch)
+	a.NotErrorIs(err, ErrQueryMismatch)
+	a.Contains(err.Error(), "argument 0 expected [int - 1] does not match actual [int - 2]")
+
+	err = mock.ExpectationsWereMet()
+	a.ErrorIs(err, ErrUnmetExpectations)
+	a.True(errors.Is(mock.ExpectationsWereMet(), ErrUnmetExpectations))
+
+	_, err = mock.Exec(ctx, "UPDATE users", 1)
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestMarkError(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.NoError(markError(ErrQueryMismatch, nil))
+	err := errors.New("failure")
+	marked := markError(ErrQueryMismatch, err)
+	a.ErrorIs(marked, err)
+	a.ErrorIs(marked, ErrQueryMismatch)
+	a.Equal("failure", marked.Error())
+	a.Same(marked, markError(ErrQueryMismatch, marked))
+}
+
+func TestMismatchError(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+	mock, err := NewConn()
+	a.NoError(err)
+	mock.ExpectQuery("SELECT name FROM users").WithArgs(42, AnyArg()).WillReturnRows(NewRows([]string{"name"}))
+
+	var mismatch *MismatchError
+	_, err = mock.Query(ctx, "SELECT id FROM users", 42, "x")
+	a.ErrorAs(err, &mismatch)
+	a.Equal("Query()", mismatch.Method)
+	a.Equal("SELECT name FROM users", mismatch.ExpectedSQL)
+	a.Equal("SELECT id FROM users", mismatch.ActualSQL)
+	a.Equal(-1, mismatch.ArgIndex)
+	a.ErrorIs(mismatch, ErrQueryMismatch)
+
+	_, err = mock.Query(ctx, "SELECT name FROM users", 42)
+	a.ErrorAs(err, &mismatch)
+	a.Equal(-1, mismatch.ArgIndex)
+	a.Equal(2, mismatch.Expected)
+	a.Equal(1, mismatch.Actual)
+	a.EqualError(mismatch, "expected 2, but got 1 arguments")
+
+	_, err = mock.Query(ctx, "SELECT name FROM users", 7, "x")
+	a.ErrorAs(err, &mismatch)
+	a.Equal(0, mismatch.ArgIndex)
+	a.Equal(42, mismatch.Expected)
+	a.Equal(7, mismatch.Actual)
+	a.ErrorIs(mismatch, ErrArgsMismatch)
+	a.EqualError(mismatch, "argument 0 expected [int - 42] does not match actual [int - 7]")
+}
+
+func TestErrorFormatterOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+	mock, err := NewConn(ErrorFormatterOption(func(err error) string {
+		return "pgxmock: " + err.Error() + " (see https://example.com/docs/mocks)"
+	}))
+	a.NoError(err)
+
+	_, err = mock.Exec(ctx, "DELETE FROM users")
+	a.EqualError(err, "pgxmock: all expectations were already fulfilled, call to method Exec() was not expected (see https://example.com/docs/mocks)")
+	a.ErrorIs(err, ErrUnexpectedCall)
+
+	mock.ExpectExec("UPDATE users").WillReturnError(errors.New("deadlock"))
+	err = mock.ExpectationsWereMet()
+	a.ErrorIs(err, ErrUnmetExpectations)
+	a.Regexp(`^pgxmock: there is a remaining expectation`, err.Error())
+
+	_, err = mock.Exec(ctx, "UPDATE users")
+	a.EqualError(err, "deadlock", "errors set by WillReturnError must not be formatted")
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+type countingStringer int
+
+func (c *countingStringer) String() string {
+	*c++
+	return "expectation"
+}
+
+func TestLazyErrorf(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	var c countingStringer
+	err := lazyErrorf("no match: %s", &c)
+	a.Zero(c, "message must not be formatted until requested")
+	a.EqualError(err, "no match: expectation")
+	a.EqualValues(1, c)
+}
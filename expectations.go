@@ -1,16 +1,18 @@
 package pgxmock
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"reflect"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 
 	pgx "github.com/jackc/pgx/v5"
 	pgconn "github.com/jackc/pgx/v5/pgconn"
+	pgtype "github.com/jackc/pgx/v5/pgtype"
 )
 
 // an expectation interface
@@ -19,6 +21,22 @@ type expectation interface {
 	required() bool
 	fulfilled() bool
 	fulfill()
+	calls() (made, planned uint)
+	matchesState(state string) error
+	matchesContext(ctx context.Context) error
+	transition() (state string, ok bool)
+	setRequiredState(state string)
+	requiredTx() *ExpectedBegin
+	setRequiredTx(begin *ExpectedBegin)
+	declaredAt() string
+	setDeclaredAt(site string)
+	delay() time.Duration
+	setDelay(d time.Duration)
+	priority() int
+	deadline() time.Duration
+	fulfilledAt() time.Time
+	setFulfilledAt(t time.Time)
+	clone(mock *pgxmock) expectation
 	sync.Locker
 	fmt.Stringer
 }
@@ -34,22 +52,69 @@ type CallModifier interface {
 	// WillDelayFor allows to specify duration for which it will delay
 	// result. May be used together with Context
 	WillDelayFor(duration time.Duration) CallModifier
+	// WillDelayUntil allows to hold the expected method "in flight" until
+	// the channel is closed or receives a value. May be used together with Context
+	WillDelayUntil(release <-chan struct{}) CallModifier
+	// Before allows to specify a function which will be called every time
+	// the expected method is matched, before any delay and result are applied
+	Before(f func()) CallModifier
+	// After allows to specify a function which will be called every time
+	// the expected method is matched, right before it returns err to the caller
+	After(f func(err error)) CallModifier
+	// TransitionsTo allows to switch the mock to the named state every time
+	// the expected method is matched. See also the InState method of the mock
+	TransitionsTo(state string) CallModifier
+	// WithContext allows to check the context the expected method is called with,
+	// e.g. for a deadline or a specific value. The method is matched only
+	// if the matcher returns no error
+	WithContext(matcher func(ctx context.Context) error) CallModifier
+	// Priority allows to prefer the expected method over others matching the
+	// same call in unordered mode, e.g. a negative priority makes a catch-all
+	// expectation match only when no specific one does. The default is zero
+	Priority(n int) CallModifier
+	// Within asserts that the expected method is fulfilled within the duration
+	// measured with the mock clock since the preceding expectation was
+	// fulfilled, or since the mock was created for the first one.
+	// Late calls are not failed, but reported by ExpectationsWereMet
+	Within(d time.Duration) CallModifier
 	// WillReturnError allows to set an error for the expected method
 	WillReturnError(err error)
 	// WillPanic allows to force the expected method to panic
 	WillPanic(v any)
+	// WillPanicOnCall allows to force the expected method to panic only on the
+	// n-th call, other calls succeed. The method is awaited at least n times
+	WillPanicOnCall(n uint, v any)
+
+	// WillReturnErrorOnCall allows to force the expected method to return err
+	// only on the n-th call, other calls succeed. The method is awaited at least
+	// n times, e.g. Times(3).WillReturnErrorOnCall(2, NewCachedPlanError())
+	WillReturnErrorOnCall(n uint, err error)
 }
 
 // common expectation struct
 // satisfies the expectation interface
 type commonExpectation struct {
 	sync.Mutex
-	triggered     uint          // how many times method was called
-	err           error         // should method return error
-	optional      bool          // can method be skipped
-	panicArgument any           // panic value to return for recovery
-	plannedDelay  time.Duration // should method delay before return
-	plannedCalls  uint          // how many sequentional calls should be made
+	triggered     uint                        // how many times method was called
+	err           error                       // should method return error
+	optional      bool                        // can method be skipped
+	panicArgument any                         // panic value to return for recovery
+	panicOnCall   uint                        // the only call number to panic on if set
+	errOnCall     uint                        // the only call number to return callErr on
+	callErr       error                       // error to return on the errOnCall call
+	plannedDelay  time.Duration               // should method delay before return
+	release       <-chan struct{}             // should method wait for release before return
+	plannedCalls  uint                        // how many sequentional calls should be made
+	beforeCall    func()                      // hook to call before the method returns
+	afterCall     func(error)                 // hook to call with the method result
+	requiredState *string                     // mock state in which method may be matched
+	nextState     *string                     // mock state to switch to after method matched
+	declSite      string                      // file:line where expectation was declared
+	ctxMatcher    func(context.Context) error // context check for method to be matched
+	matchPriority int                         // preference among matching methods in unordered mode
+	within        time.Duration               // should method be fulfilled within duration
+	doneAt        time.Time                   // when method was fulfilled
+	tx            *ExpectedBegin              // transaction the method must be issued in
 }
 
 func (e *commonExpectation) error() error {
@@ -64,19 +129,93 @@ func (e *commonExpectation) fulfilled() bool {
 	return e.triggered >= max(e.plannedCalls, 1)
 }
 
+func (e *commonExpectation) calls() (made, planned uint) {
+	return e.triggered, max(e.plannedCalls, 1)
+}
+
 func (e *commonExpectation) required() bool {
 	return !e.optional
 }
 
-func (e *commonExpectation) waitForDelay(ctx context.Context) (err error) {
-	select {
-	case <-time.After(e.plannedDelay):
+func (e *commonExpectation) declaredAt() string {
+	return e.declSite
+}
+
+func (e *commonExpectation) setDeclaredAt(site string) {
+	e.declSite = site
+}
+
+func (e *commonExpectation) matchesState(state string) error {
+	if e.requiredState != nil && *e.requiredState != state {
+		return fmt.Errorf("expectation may be matched only in state '%s', but current state is '%s'", *e.requiredState, state)
+	}
+	return nil
+}
+
+func (e *commonExpectation) matchesContext(ctx context.Context) error {
+	if e.ctxMatcher == nil {
+		return nil
+	}
+	if err := e.ctxMatcher(ctx); err != nil {
+		return fmt.Errorf("context does not match: %w", err)
+	}
+	return nil
+}
+
+func (e *commonExpectation) setRequiredState(state string) {
+	e.requiredState = &state
+}
+
+func (e *commonExpectation) requiredTx() *ExpectedBegin {
+	return e.tx
+}
+
+func (e *commonExpectation) setRequiredTx(begin *ExpectedBegin) {
+	e.tx = begin
+}
+
+func (e *commonExpectation) transition() (string, bool) {
+	if e.nextState == nil {
+		return "", false
+	}
+	return *e.nextState, true
+}
+
+// waitForDelay returns the result of the callNo-th call of the matched
+// expectation after the planned delay. The call number is captured when
+// the call is matched, since concurrent calls may match it meanwhile.
+func (e *commonExpectation) waitForDelay(ctx context.Context, clock Clock, callNo uint) (err error) {
+	if e.beforeCall != nil {
+		e.beforeCall()
+	}
+	if e.release != nil {
+		select {
+		case <-e.release:
+		case <-ctx.Done():
+		}
+	}
+	if e.plannedDelay > 0 {
+		select {
+		case <-clock.After(e.plannedDelay):
+			err = e.error()
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	} else if err = ctx.Err(); err == nil {
+		// no timer is started without delay, so nothing is left
+		// running e.g. inside of testing/synctest bubbles
 		err = e.error()
-	case <-ctx.Done():
-		err = ctx.Err()
+	}
+	if err == nil && e.errOnCall > 0 && e.errOnCall == callNo {
+		err = e.callErr
+	}
+	if e.afterCall != nil {
+		e.afterCall(err)
 	}
 	if e.panicArgument != nil {
-		panic(e.panicArgument)
+		if e.panicOnCall == 0 || e.panicOnCall == callNo {
+			panic(e.panicArgument)
+		}
 	}
 	return err
 }
@@ -96,6 +235,58 @@ func (e *commonExpectation) WillDelayFor(duration time.Duration) CallModifier {
 	return e
 }
 
+func (e *commonExpectation) WillDelayUntil(release <-chan struct{}) CallModifier {
+	e.release = release
+	return e
+}
+
+func (e *commonExpectation) Before(f func()) CallModifier {
+	e.beforeCall = f
+	return e
+}
+
+func (e *commonExpectation) After(f func(err error)) CallModifier {
+	e.afterCall = f
+	return e
+}
+
+func (e *commonExpectation) TransitionsTo(state string) CallModifier {
+	e.nextState = &state
+	return e
+}
+
+func (e *commonExpectation) WithContext(matcher func(ctx context.Context) error) CallModifier {
+	e.ctxMatcher = matcher
+	return e
+}
+
+func (e *commonExpectation) Priority(n int) CallModifier {
+	e.matchPriority = n
+	return e
+}
+
+// priority returns the preference of the method in unordered mode
+func (e *commonExpectation) priority() int {
+	return e.matchPriority
+}
+
+func (e *commonExpectation) Within(d time.Duration) CallModifier {
+	e.within = d
+	return e
+}
+
+func (e *commonExpectation) deadline() time.Duration {
+	return e.within
+}
+
+func (e *commonExpectation) fulfilledAt() time.Time {
+	return e.doneAt
+}
+
+func (e *commonExpectation) setFulfilledAt(t time.Time) {
+	e.doneAt = t
+}
+
 func (e *commonExpectation) WillReturnError(err error) {
 	e.err = err
 }
@@ -107,6 +298,28 @@ func (e *commonExpectation) WillPanic(v any) {
 	e.panicArgument = v
 }
 
+func (e *commonExpectation) WillPanicOnCall(n uint, v any) {
+	e.panicArgument = v
+	e.panicOnCall = max(n, 1)
+	e.plannedCalls = max(e.plannedCalls, e.panicOnCall)
+}
+
+// delay returns the simulated delay of the method
+func (e *commonExpectation) delay() time.Duration {
+	return e.plannedDelay
+}
+
+// setDelay sets the default delay overridden by WillDelayFor
+func (e *commonExpectation) setDelay(d time.Duration) {
+	e.plannedDelay = d
+}
+
+func (e *commonExpectation) WillReturnErrorOnCall(n uint, err error) {
+	e.callErr = err
+	e.errOnCall = max(n, 1)
+	e.plannedCalls = max(e.plannedCalls, e.errOnCall)
+}
+
 // String returns string representation
 func (e *commonExpectation) String() string {
 	w := new(strings.Builder)
@@ -117,57 +330,172 @@ func (e *commonExpectation) String() string {
 			fmt.Fprintf(w, "\t- panics with: %v\n", e.panicArgument)
 		}
 	}
+	if e.panicOnCall > 0 {
+		fmt.Fprintf(w, "\t- panics on call %d with: %v\n", e.panicOnCall, e.panicArgument)
+	}
+	if e.errOnCall > 0 {
+		fmt.Fprintf(w, "\t- returns error on call %d: %v\n", e.errOnCall, e.callErr)
+	}
 	if e.plannedDelay > 0 {
 		fmt.Fprintf(w, "\t- delayed execution for: %v\n", e.plannedDelay)
 	}
+	if e.release != nil {
+		fmt.Fprint(w, "\t- delayed execution until released\n")
+	}
 	if e.optional {
 		fmt.Fprint(w, "\t- execution is optional\n")
 	}
 	if e.plannedCalls > 0 {
 		fmt.Fprintf(w, "\t- execution calls awaited: %d\n", e.plannedCalls)
 	}
+	if e.requiredState != nil {
+		fmt.Fprintf(w, "\t- matches only in state: '%s'\n", *e.requiredState)
+	}
+	if e.nextState != nil {
+		fmt.Fprintf(w, "\t- transitions to state: '%s'\n", *e.nextState)
+	}
+	if e.ctxMatcher != nil {
+		fmt.Fprint(w, "\t- matches context\n")
+	}
+	if e.matchPriority != 0 {
+		fmt.Fprintf(w, "\t- has priority: %d\n", e.matchPriority)
+	}
+	if e.within > 0 {
+		fmt.Fprintf(w, "\t- fulfilled within: %v\n", e.within)
+	}
+	if e.tx != nil {
+		fmt.Fprintf(w, "\t- issued in the transaction begun at %s\n", e.tx.declaredAt())
+	}
 	return w.String()
 }
 
 // queryBasedExpectation is a base class that adds a query matching logic
 type queryBasedExpectation struct {
 	expectSQL          string
+	anyOfSQL           []string // alternatives set by ExpectQueryAnyOf
 	expectRewrittenSQL string
 	args               []interface{}
+	compiledSQL        []compiledQuery // expected SQL or alternatives compiled for QueryMatcherRegexp
 }
 
-func (e *queryBasedExpectation) argsMatches(sql string, args []interface{}) (rewrittenSQL string, err error) {
-	eargs := e.args
-	// check for any QueryRewriter arguments: only supported as the first argument
-	if len(args) == 1 {
-		if qrw, ok := args[0].(pgx.QueryRewriter); ok {
-			// note: pgx.Conn is not currently used by the query rewriter
-			if rewrittenSQL, args, err = qrw.RewriteQuery(context.Background(), nil, sql, args); err != nil {
-				return rewrittenSQL, fmt.Errorf("error rewriting query: %w", err)
-			}
+// compiledQuery is the compiled expected SQL or the compilation error
+// returned when the call is matched, the same as QueryMatcherRegexp does
+type compiledQuery struct {
+	re  *regexp.Regexp
+	err error
+}
+
+// compile compiles the expected SQL or the alternatives
+// once at declaration if m is QueryMatcherRegexp
+func (e *queryBasedExpectation) compile(m QueryMatcher) {
+	if _, ok := m.(regexpMatcher); !ok {
+		return
+	}
+	patterns := e.anyOfSQL
+	if len(patterns) == 0 {
+		patterns = []string{e.expectSQL}
+	}
+	e.compiledSQL = make([]compiledQuery, len(patterns))
+	for i, pattern := range patterns {
+		re, err := compileQuery(pattern)
+		e.compiledSQL[i] = compiledQuery{re: re, err: err}
+	}
+}
+
+// match matches the actual SQL against the expected SQL or the alternative i
+func (e *queryBasedExpectation) match(m QueryMatcher, i int, expectedSQL, sql string) error {
+	if i < len(e.compiledSQL) {
+		if e.compiledSQL[i].err != nil {
+			return e.compiledSQL[i].err
 		}
-		// also do rewriting on the expected args if a QueryRewriter is present
-		if len(eargs) == 1 {
-			if qrw, ok := eargs[0].(pgx.QueryRewriter); ok {
-				if _, eargs, err = qrw.RewriteQuery(context.Background(), nil, sql, eargs); err != nil {
-					return "", fmt.Errorf("error rewriting query expectation: %w", err)
-				}
-			}
+		return matchRegexp(e.compiledSQL[i].re, sql)
+	}
+	return m.Match(expectedSQL, sql)
+}
+
+// sqlMatches matches the actual SQL against the expected one
+// or any of the alternatives set by ExpectQueryAnyOf
+func (e *queryBasedExpectation) sqlMatches(m QueryMatcher, sql string) error {
+	if len(e.anyOfSQL) == 0 {
+		return sqlMismatch(e.expectSQL, sql, e.match(m, 0, e.expectSQL, sql))
+	}
+	errs := make([]error, 0, len(e.anyOfSQL))
+	for i, expectedSQL := range e.anyOfSQL {
+		err := e.match(m, i, expectedSQL, sql)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return sqlMismatch(strings.Join(e.anyOfSQL, "\n"), sql, errors.Join(errs...))
+}
+
+// sqlString returns the expected SQL formatted for String methods
+func (e *queryBasedExpectation) sqlString() string {
+	if len(e.anyOfSQL) == 0 {
+		return fmt.Sprintf("\t- matches sql: '%s'\n", e.expectSQL)
+	}
+	return fmt.Sprintf("\t- matches any of sql: '%s'\n", strings.Join(e.anyOfSQL, "', '"))
+}
+
+// rewriteQuery applies pgx.QueryRewriter the same way pgx does: only leading
+// arguments are query options and the last rewriter among them is applied
+// once to the arguments following the options, rewriters at other positions
+// are plain arguments. Other leading options are kept in front of the
+// rewritten arguments. The rewritten SQL is empty if there is no rewriter.
+func rewriteQuery(conn *pgx.Conn, sql string, args []interface{}) (rewrittenSQL string, _ []interface{}, err error) {
+	var qrw pgx.QueryRewriter
+	var options []interface{}
+	i := 0
+optionLoop:
+	for ; i < len(args); i++ {
+		switch arg := args[i].(type) {
+		case pgx.QueryExecMode, pgx.QueryResultFormats, pgx.QueryResultFormatsByOID:
+			options = append(options, arg)
+		case pgx.QueryRewriter:
+			qrw = arg
+		default:
+			break optionLoop
 		}
 	}
+	if qrw == nil {
+		return "", args, nil
+	}
+	rewrittenSQL, rest, err := qrw.RewriteQuery(context.Background(), conn, sql, args[i:])
+	if err != nil {
+		return rewrittenSQL, nil, err
+	}
+	return rewrittenSQL, append(options, rest...), nil
+}
+
+// argsMatches matches the actual arguments against the expected ones, conn is
+// passed to pgx.QueryRewriter arguments, see QueryRewriterConnOption
+func (e *queryBasedExpectation) argsMatches(conn *pgx.Conn, sql string, args []interface{}) (rewrittenSQL string, err error) {
+	eargs := e.args
+	if rewrittenSQL, args, err = rewriteQuery(conn, sql, args); err != nil {
+		return rewrittenSQL, fmt.Errorf("error rewriting query: %w", err)
+	}
+	// also do rewriting on the expected args if a QueryRewriter is present
+	if _, eargs, err = rewriteQuery(conn, sql, eargs); err != nil {
+		return "", fmt.Errorf("error rewriting query expectation: %w", err)
+	}
 	if len(args) != len(eargs) {
-		return rewrittenSQL, fmt.Errorf("expected %d, but got %d arguments", len(eargs), len(args))
+		return rewrittenSQL, argsMismatch(e.expectSQL, sql, -1, len(eargs), len(args), "expected %d, but got %d arguments", len(eargs), len(args))
 	}
 	for k, v := range args {
 		// custom argument matcher
 		if matcher, ok := eargs[k].(Argument); ok {
 			if !matcher.Match(v) {
-				return rewrittenSQL, fmt.Errorf("matcher %T could not match %d argument %T - %+v", matcher, k, args[k], args[k])
+				actual := v
+				if isSensitive(matcher) {
+					actual = Redacted
+				}
+				return rewrittenSQL, argsMismatch(e.expectSQL, sql, k, matcher, actual, "matcher %T could not match %d argument %T - %s", matcher, k, v, argString(actual))
 			}
 			continue
 		}
-		if darg := eargs[k]; !reflect.DeepEqual(darg, v) {
-			return rewrittenSQL, fmt.Errorf("argument %d expected [%T - %+v] does not match actual [%T - %+v]", k, darg, darg, v, v)
+		if darg := eargs[k]; !argumentEqual(darg, v) {
+			return rewrittenSQL, argsMismatch(e.expectSQL, sql, k, darg, v, "argument %d expected [%T - %s] does not match actual [%T - %s]", k, darg, argString(darg), v, argString(v))
 		}
 	}
 	return
@@ -188,9 +516,27 @@ func (e *ExpectedClose) String() string {
 // returned by pgxmock.ExpectBegin.
 type ExpectedBegin struct {
 	commonExpectation
+	mock *pgxmock
 	opts pgx.TxOptions
 }
 
+// ExpectBatch allows to expect SendBatch() issued in this transaction,
+// the batch sent on the mock itself, e.g. on the pool, is not matched.
+func (e *ExpectedBegin) ExpectBatch() *ExpectedBatch {
+	eb := e.mock.ExpectBatch()
+	eb.setRequiredTx(e)
+	return eb
+}
+
+// ExpectCopyFrom allows to expect CopyFrom() issued in this transaction, so
+// the bulk load is covered by its rollback, the rows copied on the mock
+// itself, e.g. on the pool, are not matched.
+func (e *ExpectedBegin) ExpectCopyFrom(expectedTableName pgx.Identifier, expectedColumns []string) *ExpectedCopyFrom {
+	ec := e.mock.ExpectCopyFrom(expectedTableName, expectedColumns)
+	ec.setRequiredTx(e)
+	return ec
+}
+
 // String returns string representation
 func (e *ExpectedBegin) String() string {
 	msg := "ExpectedBegin => expecting call to Begin() or to BeginTx()\n"
@@ -237,7 +583,7 @@ func (e *ExpectedExec) WithRewrittenSQL(sql string) *ExpectedExec {
 // String returns string representation
 func (e *ExpectedExec) String() string {
 	msg := "ExpectedExec => expecting call to Exec():\n"
-	msg += fmt.Sprintf("\t- matches sql: '%s'\n", e.expectSQL)
+	msg += e.sqlString()
 
 	if len(e.args) == 0 {
 		msg += "\t- is without arguments\n"
@@ -276,8 +622,10 @@ type ExpectedBatch struct {
 func (e *ExpectedBatch) ExpectExec(query string) *ExpectedExec {
 	ee := &ExpectedExec{}
 	ee.expectSQL = query
+	ee.compile(e.mock.queryMatcher)
+	ee.requiredState = e.requiredState
 	e.expectedQueries = append(e.expectedQueries, &ee.queryBasedExpectation)
-	e.mock.expectations = append(e.mock.expectations, ee)
+	e.mock.addExpectation(ee)
 	return ee
 }
 
@@ -285,8 +633,10 @@ func (e *ExpectedBatch) ExpectExec(query string) *ExpectedExec {
 func (e *ExpectedBatch) ExpectQuery(query string) *ExpectedQuery {
 	eq := &ExpectedQuery{}
 	eq.expectSQL = query
+	eq.compile(e.mock.queryMatcher)
+	eq.requiredState = e.requiredState
 	e.expectedQueries = append(e.expectedQueries, &eq.queryBasedExpectation)
-	e.mock.expectations = append(e.mock.expectations, eq)
+	e.mock.addExpectation(eq)
 	return eq
 }
 
@@ -299,18 +649,69 @@ func (e *ExpectedBatch) String() string {
 	return msg + e.commonExpectation.String()
 }
 
+// AnyStatementName may be passed to ExpectPrepare and ExpectDeallocate
+// to match the statement of any name, e.g. generated by libraries
+const AnyStatementName = "\x00any"
+
+// stmtNameExpectation matches the prepared statement name
+type stmtNameExpectation struct {
+	expectStmtName string
+	stmtNameRe     *regexp.Regexp // set by WithStmtNameRegexp
+	stmtNameErr    error          // compilation error of the WithStmtNameRegexp pattern
+}
+
+// setStmtNameRegexp compiles the pattern of the statement name, the
+// compilation error is returned when the call is matched, the same as
+// QueryMatcherRegexp does
+func (e *stmtNameExpectation) setStmtNameRegexp(pattern string) {
+	e.stmtNameRe, e.stmtNameErr = regexp.Compile(pattern)
+}
+
+// stmtNameMatches returns an error if the statement name passed to
+// the method does not match the expected name or the pattern
+func (e *stmtNameExpectation) stmtNameMatches(method, name string) error {
+	if e.stmtNameErr != nil {
+		return fmt.Errorf("%s: invalid statement name pattern: %w", method, e.stmtNameErr)
+	}
+	ok := e.expectStmtName == AnyStatementName || e.expectStmtName == name
+	if e.stmtNameRe != nil {
+		ok = e.stmtNameRe.MatchString(name)
+	}
+	if !ok {
+		return fmt.Errorf("%s: prepared statement name '%s' was not expected, expected name is %s", method, name, e.stmtNameString())
+	}
+	return nil
+}
+
+func (e *stmtNameExpectation) stmtNameString() string {
+	switch {
+	case e.stmtNameRe != nil:
+		return fmt.Sprintf("matching '%s'", e.stmtNameRe)
+	case e.expectStmtName == AnyStatementName:
+		return "any"
+	}
+	return fmt.Sprintf("'%s'", e.expectStmtName)
+}
+
 // ExpectedPrepare is used to manage pgx.Prepare or pgx.Tx.Prepare expectations.
 // Returned by pgxmock.ExpectPrepare.
 type ExpectedPrepare struct {
 	commonExpectation
-	expectStmtName string
-	expectSQL      string
+	stmtNameExpectation
+	expectSQL string
+}
+
+// WithStmtNameRegexp matches the statement name against the regular expression
+// instead of the expected name, e.g. "^lrupsc_" for names generated by pgx
+func (e *ExpectedPrepare) WithStmtNameRegexp(pattern string) *ExpectedPrepare {
+	e.setStmtNameRegexp(pattern)
+	return e
 }
 
 // String returns string representation
 func (e *ExpectedPrepare) String() string {
 	msg := "ExpectedPrepare => expecting call to Prepare():\n"
-	msg += fmt.Sprintf("\t- matches statement name: '%s'\n", e.expectStmtName)
+	msg += fmt.Sprintf("\t- matches statement name: %s\n", e.stmtNameString())
 	msg += fmt.Sprintf("\t- matches sql: '%s'\n", e.expectSQL)
 	return msg + e.commonExpectation.String()
 }
@@ -319,8 +720,15 @@ func (e *ExpectedPrepare) String() string {
 // Returned by pgxmock.ExpectDeallocate(string) and pgxmock.ExpectDeallocateAll().
 type ExpectedDeallocate struct {
 	commonExpectation
-	expectStmtName string
-	expectAll      bool
+	stmtNameExpectation
+	expectAll bool
+}
+
+// WithStmtNameRegexp matches the statement name against the regular expression
+// instead of the expected name, see ExpectedPrepare.WithStmtNameRegexp
+func (e *ExpectedDeallocate) WithStmtNameRegexp(pattern string) *ExpectedDeallocate {
+	e.setStmtNameRegexp(pattern)
+	return e
 }
 
 // String returns string representation
@@ -329,11 +737,22 @@ func (e *ExpectedDeallocate) String() string {
 	if e.expectAll {
 		msg += "\t- matches all statements\n"
 	} else {
-		msg += fmt.Sprintf("\t- matches statement name: '%s'\n", e.expectStmtName)
+		msg += fmt.Sprintf("\t- matches statement name: %s\n", e.stmtNameString())
 	}
 	return msg + e.commonExpectation.String()
 }
 
+// ExpectedCancelRequest is used to manage CancelRequest() expectations
+type ExpectedCancelRequest struct {
+	commonExpectation
+}
+
+// String returns string representation
+func (e *ExpectedCancelRequest) String() string {
+	msg := "ExpectedCancelRequest => expecting call to CancelRequest()\n"
+	return msg + e.commonExpectation.String()
+}
+
 // ExpectedPing is used to manage Ping() expectations
 type ExpectedPing struct {
 	commonExpectation
@@ -350,9 +769,24 @@ func (e *ExpectedPing) String() string {
 type ExpectedQuery struct {
 	commonExpectation
 	queryBasedExpectation
-	rows             pgx.Rows
-	rowsMustBeClosed bool
-	rowsWereClosed   bool
+	rows                pgx.Rows
+	rowsMustBeClosed    bool
+	rowsWereClosed      bool
+	rowsMustBeFullyRead bool
+	resultFormats       []int16 // formats set by WithResultFormats
+	pages               *Rows   // dataset set by WillReturnPages
+}
+
+// WithResultFormats simulates results exchanged in the text or binary format,
+// e.g. pgtype.BinaryFormatCode, the same way the pgx.QueryResultFormats argument
+// does: a single format applies to all columns, otherwise formats are set per
+// column. Values and RawValues of the rows then return values decoded from and
+// encoded into the format, e.g. to exercise custom codecs. The actual call
+// pgx.QueryResultFormats and pgx.QueryResultFormatsByOID arguments take
+// precedence over formats set by WithResultFormats.
+func (e *ExpectedQuery) WithResultFormats(formats ...int16) *ExpectedQuery {
+	e.resultFormats = formats
+	return e
 }
 
 // WithArgs will match given expected args to actual database query arguments.
@@ -376,10 +810,18 @@ func (e *ExpectedQuery) RowsWillBeClosed() *ExpectedQuery {
 	return e
 }
 
+// RowsMustBeFullyRead expects this query rows to be iterated until the end
+// or until the row error. Rows returned to QueryRow are never checked,
+// since only the first row is read by design.
+func (e *ExpectedQuery) RowsMustBeFullyRead() *ExpectedQuery {
+	e.rowsMustBeFullyRead = true
+	return e
+}
+
 // String returns string representation
 func (e *ExpectedQuery) String() string {
 	msg := "ExpectedQuery => expecting call to Query() or to QueryRow():\n"
-	msg += fmt.Sprintf("\t- matches sql: '%s'\n", e.expectSQL)
+	msg += e.sqlString()
 
 	if len(e.args) == 0 {
 		msg += "\t- is without arguments\n"
@@ -389,6 +831,12 @@ func (e *ExpectedQuery) String() string {
 			msg += fmt.Sprintf("\t\t%d - %+v\n", i, arg)
 		}
 	}
+	if e.resultFormats != nil {
+		msg += fmt.Sprintf("\t- returns results in formats: %v\n", e.resultFormats)
+	}
+	if e.pages != nil {
+		msg += "\t- returns pages of data\n"
+	}
 	if e.rows != nil {
 		msg += fmt.Sprintf("%s\n", e.rows)
 	}
@@ -396,9 +844,14 @@ func (e *ExpectedQuery) String() string {
 }
 
 // WillReturnRows specifies the set of resulting rows that will be returned
-// by the triggered query
+// by the triggered query. Rows are copied, so the same rows may be used
+// for many expectations.
 func (e *ExpectedQuery) WillReturnRows(rows ...*Rows) *ExpectedQuery {
-	e.rows = &rowSets{sets: rows, ex: e}
+	sets := make([]*Rows, len(rows))
+	for i, r := range rows {
+		sets[i] = r.Clone()
+	}
+	e.rows = &rowSets{sets: sets, ex: e}
 	return e
 }
 
@@ -409,6 +862,8 @@ type ExpectedCopyFrom struct {
 	expectedTableName pgx.Identifier
 	expectedColumns   []string
 	rowsAffected      int64
+	columnTypes       []uint32        // OIDs to encode copied values with
+	expectedRows      [][]interface{} // values expected to be copied
 }
 
 // String returns string representation
@@ -416,6 +871,15 @@ func (e *ExpectedCopyFrom) String() string {
 	msg := "ExpectedCopyFrom => expecting CopyFrom which:"
 	msg += "\n  - matches table name: '" + e.expectedTableName.Sanitize() + "'"
 	msg += fmt.Sprintf("\n  - matches column names: '%+v'", e.expectedColumns)
+	if e.columnTypes != nil {
+		msg += fmt.Sprintf("\n  - encodes values with type OIDs: '%v'", e.columnTypes)
+	}
+	if e.expectedRows != nil {
+		msg += fmt.Sprintf("\n  - copies rows: '%+v'", e.expectedRows)
+	}
+	if e.tx != nil {
+		msg += fmt.Sprintf("\n  - copies in the transaction begun at %s", e.tx.declaredAt())
+	}
 
 	if e.err != nil {
 		msg += fmt.Sprintf("\n  - should returns error: %s", e.err)
@@ -430,6 +894,102 @@ func (e *ExpectedCopyFrom) WillReturnResult(result int64) *ExpectedCopyFrom {
 	return e
 }
 
+// WithColumnTypes arranges for the values read from the CopyFrom() source
+// to be encoded in the binary format using the data types of the columns,
+// the same way pgx does, failing the call if any value cannot be encoded.
+func (e *ExpectedCopyFrom) WithColumnTypes(oids ...uint32) *ExpectedCopyFrom {
+	e.columnTypes = oids
+	return e
+}
+
+// WithRows arranges for the values read from the CopyFrom() source to be
+// matched against expected rows. Values may be Argument matchers, nil
+// matches NULL. If the column types are set, values are compared encoded.
+func (e *ExpectedCopyFrom) WithRows(rows ...[]interface{}) *ExpectedCopyFrom {
+	e.expectedRows = rows
+	return e
+}
+
+// validateRows reads the CopyFrom() source validating
+// the copied values, if the validation was requested
+func (e *ExpectedCopyFrom) validateRows(m *pgtype.Map, src pgx.CopyFromSource) error {
+	if e.columnTypes == nil && e.expectedRows == nil {
+		return nil
+	}
+	if e.columnTypes != nil && len(e.columnTypes) != len(e.expectedColumns) {
+		return fmt.Errorf("CopyFrom: %d column types specified for %d columns", len(e.columnTypes), len(e.expectedColumns))
+	}
+	rowNo := 0
+	for ; src != nil && src.Next(); rowNo++ {
+		values, err := src.Values()
+		if err != nil {
+			return err
+		}
+		if err := e.validateRow(m, rowNo, values); err != nil {
+			return err
+		}
+	}
+	if src != nil && src.Err() != nil {
+		return src.Err()
+	}
+	if e.expectedRows != nil && rowNo != len(e.expectedRows) {
+		return fmt.Errorf("CopyFrom: %d rows were copied, expected %d", rowNo, len(e.expectedRows))
+	}
+	return nil
+}
+
+// validateRow validates the values of the copied row rowNo
+func (e *ExpectedCopyFrom) validateRow(m *pgtype.Map, rowNo int, values []interface{}) error {
+	if len(values) != len(e.expectedColumns) {
+		return fmt.Errorf("CopyFrom: row %d has %d values, expected %d", rowNo, len(values), len(e.expectedColumns))
+	}
+	if e.expectedRows != nil && rowNo >= len(e.expectedRows) {
+		return fmt.Errorf("CopyFrom: row %d was not expected, expected %d rows", rowNo, len(e.expectedRows))
+	}
+	for i, v := range values {
+		if err := e.validateValue(m, rowNo, i, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateValue validates the copied value v of the column i at row rowNo
+func (e *ExpectedCopyFrom) validateValue(m *pgtype.Map, rowNo, i int, v interface{}) error {
+	actual, err := e.encode(m, i, v)
+	if err != nil {
+		return fmt.Errorf("CopyFrom: cannot encode value %+v of column '%s' at row %d: %w", v, e.expectedColumns[i], rowNo, err)
+	}
+	if e.expectedRows == nil {
+		return nil
+	}
+	ev := e.expectedRows[rowNo][i]
+	if matcher, ok := ev.(Argument); ok {
+		if !matcher.Match(v) {
+			return fmt.Errorf("CopyFrom: matcher %T could not match value %+v of column '%s' at row %d", matcher, v, e.expectedColumns[i], rowNo)
+		}
+		return nil
+	}
+	matches := argumentEqual(ev, v)
+	if e.columnTypes != nil {
+		expected, err := e.encode(m, i, ev)
+		matches = err == nil && (expected == nil) == (actual == nil) && bytes.Equal(expected, actual)
+	}
+	if !matches {
+		return fmt.Errorf("CopyFrom: value %+v of column '%s' at row %d does not match expected %+v", v, e.expectedColumns[i], rowNo, ev)
+	}
+	return nil
+}
+
+// encode encodes the value v of the column i in the binary format
+// if the column types are set
+func (e *ExpectedCopyFrom) encode(m *pgtype.Map, i int, v interface{}) ([]byte, error) {
+	if e.columnTypes == nil {
+		return nil, nil
+	}
+	return m.Encode(e.columnTypes[i], pgtype.BinaryFormatCode, v, nil)
+}
+
 // ExpectedReset is used to manage pgx.Reset expectation
 type ExpectedReset struct {
 	commonExpectation
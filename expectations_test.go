@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -164,6 +166,29 @@ func TestUnexpectedCopyFrom(t *testing.T) {
 	}
 }
 
+func TestCopyFromInTransaction(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	base, _ := NewPool()
+	begin := base.ExpectBegin()
+	begin.ExpectCopyFrom(pgx.Identifier{"t"}, []string{"id"}).WillReturnResult(2)
+	base.ExpectRollback()
+	rows := pgx.CopyFromRows([][]any{{1}, {2}})
+
+	for _, mock := range []PgxPoolIface{base, base.CloneWithExpectations()} {
+		tx, err := mock.Begin(ctx)
+		a.NoError(err)
+		_, err = mock.CopyFrom(ctx, pgx.Identifier{"t"}, []string{"id"}, rows)
+		a.ErrorIs(err, ErrUnexpectedCall, "the rows copied on the pool are not covered by the rollback")
+		a.ErrorContains(err, "CopyFrom: call was expected to be issued in the transaction begun at expectations_test.go:")
+		n, err := tx.CopyFrom(ctx, pgx.Identifier{"t"}, []string{"id"}, rows)
+		a.NoError(err)
+		a.EqualValues(2, n)
+		a.NoError(tx.Rollback(ctx))
+		a.NoError(mock.ExpectationsWereMet())
+	}
+}
+
 func TestBuildQuery(t *testing.T) {
 	mock, _ := NewConn()
 	a := assert.New(t)
@@ -322,3 +347,194 @@ func TestQueryRewriter(t *testing.T) {
 	a.Error(err)
 	a.NoError(mock.ExpectationsWereMet())
 }
+
+func TestWillDelayUntil(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	release := make(chan struct{})
+	mock.ExpectPing().WillDelayUntil(release)
+	mock.ExpectPing().WillDelayUntil(release)
+
+	done := make(chan error)
+	go func() { done <- mock.Ping(ctx) }()
+	select {
+	case <-done:
+		t.Fatal("Ping() must be held until released")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	a.NoError(<-done)
+
+	c, cancel := context.WithCancel(ctx)
+	cancel()
+	a.ErrorIs(mock.Ping(c), context.Canceled)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestBeforeAfterHooks(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	var calls []string
+	mock.ExpectPing().
+		Before(func() { calls = append(calls, "before") }).
+		After(func(err error) { calls = append(calls, fmt.Sprint("after: ", err)) }).
+		Times(2)
+	mock.ExpectExec("UPDATE").
+		After(func(err error) { calls = append(calls, fmt.Sprint("after: ", err)) }).
+		WillReturnError(errors.New("oops"))
+
+	a.NoError(mock.Ping(ctx))
+	a.NoError(mock.Ping(ctx))
+	_, err := mock.Exec(ctx, "UPDATE foo")
+	a.Error(err)
+	a.Equal([]string{"before", "after: <nil>", "before", "after: <nil>", "after: oops"}, calls)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestWillPanicOnCall(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	ex := mock.ExpectPing()
+	ex.WillPanicOnCall(2, "second ping")
+	a.Contains(ex.String(), "panics on call 2 with: second ping")
+
+	a.NoError(mock.Ping(ctx))
+	a.Error(mock.ExpectationsWereMet(), "second call must be awaited")
+	a.PanicsWithValue("second ping", func() { _ = mock.Ping(ctx) })
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestWillPanicOnCallConcurrently(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewPool()
+	a := assert.New(t)
+
+	const calls = 10
+	var matched sync.WaitGroup
+	matched.Add(calls)
+	release := make(chan struct{})
+	ex := mock.ExpectPing().Times(calls).Before(matched.Done).WillDelayUntil(release)
+	ex.WillPanicOnCall(3, "third ping")
+	ex.WillReturnErrorOnCall(5, errors.New("fifth ping"))
+
+	var panics, errs atomic.Int32
+	var done sync.WaitGroup
+	for range calls {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			defer func() {
+				if recover() != nil {
+					panics.Add(1)
+				}
+			}()
+			if mock.Ping(ctx) != nil {
+				errs.Add(1)
+			}
+		}()
+	}
+	// all calls are matched before any of them returns
+	matched.Wait()
+	close(release)
+	done.Wait()
+	a.EqualValues(1, panics.Load())
+	a.EqualValues(1, errs.Load())
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestWillReturnErrorOnCall(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	ex := mock.ExpectExec("EXECUTE stmt").WillReturnResult(NewResult("UPDATE", 1))
+	ex.Times(3).WillReturnErrorOnCall(2, NewCachedPlanError())
+	a.Contains(ex.String(), "returns error on call 2: ERROR: cached plan must not change result type (SQLSTATE 0A000)")
+
+	_, err := mock.Exec(ctx, "EXECUTE stmt")
+	a.NoError(err)
+	_, err = mock.Exec(ctx, "EXECUTE stmt")
+	var pgErr *pgconn.PgError
+	a.ErrorAs(err, &pgErr)
+	a.Equal("0A000", pgErr.Code)
+	_, err = mock.Exec(ctx, "EXECUTE stmt")
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+type tenantKey struct{}
+
+func TestWithContext(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	mock.ExpectExec("UPDATE").
+		WillReturnResult(NewResult("UPDATE", 1)).
+		WithContext(func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); !ok {
+				return errors.New("no deadline")
+			}
+			if ctx.Value(tenantKey{}) != "acme" {
+				return errors.New("wrong tenant")
+			}
+			return nil
+		})
+
+	_, err := mock.Exec(ctx, "UPDATE foo")
+	a.ErrorContains(err, "context does not match: no deadline")
+
+	c, cancel := context.WithTimeout(context.WithValue(ctx, tenantKey{}, "acme"), time.Minute)
+	defer cancel()
+	_, err = mock.Exec(c, "UPDATE foo")
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+type point struct{ X, Y int }
+
+func TestCopyFromPayloadValidation(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	columns := []string{"id", "name", "created_at"}
+	rows := [][]any{{1, "john", time.Now()}, {2, nil, time.Now()}}
+
+	mock.ExpectCopyFrom(pgx.Identifier{"users"}, columns).
+		WithColumnTypes(pgtype.Int8OID, pgtype.TextOID, pgtype.TimestamptzOID).
+		WithRows([]any{int64(1), "john", AnyArg()}, []any{2, nil, AnyArg()}).
+		WillReturnResult(2)
+	n, err := mock.CopyFrom(ctx, pgx.Identifier{"users"}, columns, pgx.CopyFromRows(rows))
+	a.NoError(err)
+	a.EqualValues(2, n)
+
+	mock.ExpectCopyFrom(pgx.Identifier{"users"}, columns).
+		WithColumnTypes(pgtype.Int8OID, pgtype.TextOID, pgtype.TimestamptzOID)
+	_, err = mock.CopyFrom(ctx, pgx.Identifier{"users"}, columns, pgx.CopyFromRows([][]any{{1, point{1, 2}, time.Now()}}))
+	a.ErrorContains(err, "CopyFrom: cannot encode value {X:1 Y:2} of column 'name' at row 0")
+
+	mock.ExpectCopyFrom(pgx.Identifier{"users"}, columns).
+		WithColumnTypes(pgtype.Int8OID, pgtype.TextOID, pgtype.TimestamptzOID).
+		WithRows([]any{1, "", AnyArg()})
+	_, err = mock.CopyFrom(ctx, pgx.Identifier{"users"}, columns, pgx.CopyFromRows(rows[1:]))
+	a.ErrorContains(err, "CopyFrom: value 2 of column 'id' at row 0 does not match expected 1")
+
+	mock.ExpectCopyFrom(pgx.Identifier{"users"}, []string{"id"}).WithRows([]any{1}, []any{2})
+	_, err = mock.CopyFrom(ctx, pgx.Identifier{"users"}, []string{"id"}, pgx.CopyFromRows([][]any{{1}}))
+	a.ErrorContains(err, "CopyFrom: 1 rows were copied, expected 2")
+
+	mock.ExpectCopyFrom(pgx.Identifier{"users"}, []string{"id"}).WithRows([]any{1})
+	_, err = mock.CopyFrom(ctx, pgx.Identifier{"users"}, []string{"id"}, pgx.CopyFromRows([][]any{{1}, {2}}))
+	a.ErrorContains(err, "CopyFrom: row 1 was not expected, expected 1 rows")
+
+	mock.ExpectCopyFrom(pgx.Identifier{"users"}, []string{"id"}).WithColumnTypes(pgtype.Int8OID, pgtype.TextOID)
+	_, err = mock.CopyFrom(ctx, pgx.Identifier{"users"}, []string{"id"}, pgx.CopyFromRows(nil))
+	a.ErrorContains(err, "CopyFrom: 2 column types specified for 1 columns")
+	a.NoError(mock.ExpectationsWereMet())
+}
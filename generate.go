@@ -0,0 +1,127 @@
+package pgxmock
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ValueGenerator is a function producing a value for the column
+// of the generated row. The rnd source should be used for any
+// randomness, so generated rows may be reproduced if needed.
+type ValueGenerator func(column pgconn.FieldDescription, rowNo int, rnd *rand.Rand) any
+
+// GenerateOption allows to customize rows generation
+type GenerateOption func(*generateConfig)
+
+type generateConfig struct {
+	seed   uint64
+	seeded bool
+}
+
+// WithSeed makes rows generation deterministic. The same seed
+// produces the same rows across runs and machines, which is handy
+// for reproducing flaky failures. By default a random seed is used.
+func WithSeed(seed uint64) GenerateOption {
+	return func(cfg *generateConfig) {
+		cfg.seed = seed
+		cfg.seeded = true
+	}
+}
+
+// NewRowsGenerated creates Rows with the columns metadata filled with n rows
+// produced by the generate function. Useful for tests caring only about
+// the volume and shape of the result set, not about the exact content.
+// Accepts options, like WithSeed, to make generated rows reproducible.
+func NewRowsGenerated(columns []pgconn.FieldDescription, n int, generate ValueGenerator, options ...GenerateOption) *Rows {
+	cfg := generateConfig{}
+	for _, option := range options {
+		option(&cfg)
+	}
+	if !cfg.seeded {
+		cfg.seed = rand.Uint64()
+	}
+	rnd := rand.New(rand.NewPCG(cfg.seed, cfg.seed))
+	r := NewRowsWithColumnDefinition(columns...)
+	for rowNo := 0; rowNo < n; rowNo++ {
+		row := make([]any, len(columns))
+		for i, col := range columns {
+			row[i] = generate(col, rowNo, rnd)
+		}
+		r.AddRow(row...)
+	}
+	return r
+}
+
+var fakeFirstNames = []string{"John", "Jane", "Peter", "Emily", "Michael", "Sarah", "David", "Laura"}
+var fakeLastNames = []string{"Smith", "Johnson", "Brown", "Taylor", "Miller", "Wilson", "Moore", "Clark"}
+var fakeWords = []string{"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit"}
+
+// FakeByType is a ValueGenerator producing plausible values based on
+// the column DataTypeOID. Text columns are additionally guessed by the
+// column name, e.g. "email" or "name". Unknown types are filled with text.
+func FakeByType(column pgconn.FieldDescription, rowNo int, rnd *rand.Rand) any {
+	switch column.DataTypeOID {
+	case pgtype.BoolOID:
+		return rnd.IntN(2) == 1
+	case pgtype.Int2OID:
+		return int16(rnd.IntN(1 << 15))
+	case pgtype.Int4OID:
+		return rnd.Int32()
+	case pgtype.Int8OID:
+		return rnd.Int64()
+	case pgtype.Float4OID:
+		return rnd.Float32() * 1000
+	case pgtype.Float8OID, pgtype.NumericOID:
+		return rnd.Float64() * 1000
+	case pgtype.DateOID:
+		return time.Date(2000+rnd.IntN(30), time.Month(1+rnd.IntN(12)), 1+rnd.IntN(28), 0, 0, 0, 0, time.UTC)
+	case pgtype.TimestampOID, pgtype.TimestamptzOID:
+		return time.Date(2000+rnd.IntN(30), time.Month(1+rnd.IntN(12)), 1+rnd.IntN(28),
+			rnd.IntN(24), rnd.IntN(60), rnd.IntN(60), 0, time.UTC)
+	case pgtype.IntervalOID:
+		return time.Duration(rnd.Int64N(int64(24 * time.Hour)))
+	case pgtype.UUIDOID:
+		var u [16]byte
+		for i := range u {
+			u[i] = byte(rnd.UintN(256))
+		}
+		u[6] = (u[6] & 0x0f) | 0x40 // version 4
+		u[8] = (u[8] & 0x3f) | 0x80 // variant 10
+		return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+	case pgtype.ByteaOID:
+		b := make([]byte, 1+rnd.IntN(16))
+		for i := range b {
+			b[i] = byte(rnd.UintN(256))
+		}
+		return b
+	case pgtype.JSONOID, pgtype.JSONBOID:
+		return map[string]any{"id": rowNo + 1, fakeWords[rnd.IntN(len(fakeWords))]: fakeWords[rnd.IntN(len(fakeWords))]}
+	}
+	return fakeText(column.Name, rowNo, rnd)
+}
+
+// fakeText returns a plausible text value based on the column name
+func fakeText(name string, rowNo int, rnd *rand.Rand) string {
+	first := fakeFirstNames[rnd.IntN(len(fakeFirstNames))]
+	last := fakeLastNames[rnd.IntN(len(fakeLastNames))]
+	switch name = strings.ToLower(name); {
+	case strings.Contains(name, "email"):
+		return fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), rowNo+1)
+	case strings.Contains(name, "first"):
+		return first
+	case strings.Contains(name, "last"):
+		return last
+	case strings.Contains(name, "name"):
+		return first + " " + last
+	}
+	words := make([]string, 1+rnd.IntN(4))
+	for i := range words {
+		words[i] = fakeWords[rnd.IntN(len(fakeWords))]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,62 @@
+package pgxmock
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRowsGenerated(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	rows := NewRowsGenerated([]pgconn.FieldDescription{
+		{Name: "id", DataTypeOID: pgtype.Int8OID},
+		{Name: "email", DataTypeOID: pgtype.TextOID},
+		{Name: "active", DataTypeOID: pgtype.BoolOID},
+		{Name: "created_at", DataTypeOID: pgtype.TimestamptzOID},
+		{Name: "comment"},
+	}, 100, FakeByType)
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	rs, err := mock.Query(ctx, "SELECT id, email, active, created_at, comment FROM users")
+	a.NoError(err)
+	var (
+		n         int
+		id        int64
+		email     string
+		active    bool
+		createdAt time.Time
+		comment   string
+	)
+	for rs.Next() {
+		a.NoError(rs.Scan(&id, &email, &active, &createdAt, &comment))
+		a.True(strings.HasSuffix(email, "@example.com"))
+		a.False(createdAt.IsZero())
+		a.NotEmpty(comment)
+		n++
+	}
+	a.Equal(100, n)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestNewRowsGeneratedWithSeed(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	columns := []pgconn.FieldDescription{
+		{Name: "id", DataTypeOID: pgtype.Int4OID},
+		{Name: "name", DataTypeOID: pgtype.VarcharOID},
+		{Name: "uid", DataTypeOID: pgtype.UUIDOID},
+		{Name: "payload", DataTypeOID: pgtype.JSONBOID},
+	}
+	r1 := NewRowsGenerated(columns, 10, FakeByType, WithSeed(42))
+	r2 := NewRowsGenerated(columns, 10, FakeByType, WithSeed(42))
+	r3 := NewRowsGenerated(columns, 10, FakeByType, WithSeed(43))
+	a.Equal(r1.rows, r2.rows)
+	a.NotEqual(r1.rows, r3.rows)
+}
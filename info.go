@@ -0,0 +1,159 @@
+package pgxmock
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// ExpectationInfo describes the expectation declared on the mock.
+// Returned by pgxmock.PendingExpectations and passed to the
+// OnExpectationMatchedOption hook.
+type ExpectationInfo struct {
+	// Type is the expectation type name, e.g. "ExpectedQuery"
+	Type string
+	// SQL is the expected SQL if the expectation is SQL based
+	SQL string
+	// Label is the short unique name of the expectation, e.g. "ExpectedQuery #2"
+	Label string
+	// DeclaredAt is the file:line where the expectation was declared
+	DeclaredAt string
+	// Optional is true if the expectation was declared with Maybe()
+	Optional bool
+	// Expectation is the expectation itself, e.g. *ExpectedQuery
+	Expectation fmt.Stringer
+}
+
+func (c *pgxmock) PendingExpectations() []ExpectationInfo {
+	var infos []ExpectationInfo
+	for _, e := range c.declared() {
+		e.Lock()
+		fulfilled := e.fulfilled()
+		e.Unlock()
+		if fulfilled {
+			continue
+		}
+		infos = append(infos, c.expectationInfo(e))
+	}
+	return infos
+}
+
+// maxSummarySQL is the number of SQL characters printed by Summary
+const maxSummarySQL = 40
+
+// Summary returns the report of fulfilled and pending expectations,
+// see Expecter.Summary
+func (c *pgxmock) Summary() string {
+	w := new(strings.Builder)
+	var fulfilledCount, pendingCount int
+	expectations := c.declared()
+	for _, e := range expectations {
+		e.Lock()
+		made, planned := e.calls()
+		fulfilled := e.fulfilled()
+		e.Unlock()
+		status := "[ok]"
+		switch {
+		case fulfilled:
+			fulfilledCount++
+		case !e.required():
+			status = "[optional]"
+		default:
+			status = "[pending]"
+			pendingCount++
+		}
+		name := strings.Replace(reflect.TypeOf(e).Elem().Name(), "Expected", "Expect", 1)
+		sql := []rune(stripQuery(expectationSQL(e)))
+		if len(sql) > maxSummarySQL {
+			sql = append(sql[:maxSummarySQL], '…')
+		}
+		fmt.Fprintf(w, "\t%s %d/%d calls of %s(%s)%s\n", status, made, planned, name, string(sql), declaredAt(e))
+	}
+	return fmt.Sprintf("expectations: %d of %d fulfilled, %d pending\n", fulfilledCount, len(expectations), pendingCount) + w.String()
+}
+
+func (c *pgxmock) expectationInfo(e expectation) ExpectationInfo {
+	return ExpectationInfo{
+		Type:        reflect.TypeOf(e).Elem().Name(),
+		SQL:         expectationSQL(e),
+		Label:       c.expectationLabel(e),
+		DeclaredAt:  e.declaredAt(),
+		Optional:    !e.required(),
+		Expectation: e,
+	}
+}
+
+// expectationSQL returns the expected SQL of the expectation if any
+func expectationSQL(e expectation) string {
+	switch e := e.(type) {
+	case *ExpectedQuery:
+		return e.expectSQL
+	case *ExpectedExec:
+		return e.expectSQL
+	case *ExpectedPrepare:
+		return e.expectSQL
+	}
+	return ""
+}
+
+// declaredAt returns the declaration site of the expectation
+// formatted to be included into error messages
+func declaredAt(e expectation) string {
+	if site := e.declaredAt(); site != "" {
+		return " declared at " + site
+	}
+	return ""
+}
+
+// libraryDir is the directory of pgxmock sources
+var libraryDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// withCallStack appends the call stack of the code under test
+// to the mismatch error if enabled by CallStackOption
+func (c *pgxmock) withCallStack(err error) error {
+	if !c.callStacks {
+		return err
+	}
+	w := new(strings.Builder)
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !isLibraryFrame(frame) && !strings.HasPrefix(frame.Function, "runtime.") && !strings.HasPrefix(frame.Function, "testing.") {
+			fmt.Fprintf(w, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return fmt.Errorf("%w\ncall stack:\n%s", err, w.String())
+}
+
+// isLibraryFrame reports whether the frame belongs to pgxmock sources
+func isLibraryFrame(frame runtime.Frame) bool {
+	return filepath.Dir(frame.File) == libraryDir && !strings.HasSuffix(frame.File, "_test.go")
+}
+
+// declarationSite returns the file:line of the first caller
+// outside of pgxmock sources, i.e. the place in the test code
+// where the expectation was declared
+func declarationSite() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !isLibraryFrame(frame) {
+			return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
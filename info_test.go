@@ -0,0 +1,106 @@
+package pgxmock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingExpectations(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	a.Empty(mock.PendingExpectations())
+	mock.ExpectPing()
+	mock.ExpectTx(func(tx TxExpecter) {
+		tx.ExpectQuery("SELECT 1").WillReturnRows(NewRows([]string{"a"}))
+	})
+	mock.ExpectExec("UPDATE").Maybe()
+
+	a.NoError(mock.Ping(ctx))
+	infos := mock.PendingExpectations()
+	a.Len(infos, 4)
+	a.Equal("ExpectedBegin", infos[0].Type)
+	a.Equal("ExpectedBegin #1", infos[0].Label)
+	a.Equal("ExpectedQuery", infos[1].Type)
+	a.Equal("SELECT 1", infos[1].SQL)
+	a.Regexp(`^info_test\.go:\d+$`, infos[1].DeclaredAt)
+	a.Equal("ExpectedCommit", infos[2].Type)
+	a.Equal("UPDATE", infos[3].SQL)
+	a.True(infos[3].Optional)
+}
+
+func TestSummary(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	a.Equal("expectations: 0 of 0 fulfilled, 0 pending\n", mock.Summary())
+	mock.ExpectPing()
+	mock.ExpectExec("INSERT INTO users\n SELECT name, email, created_at FROM staging").
+		WillReturnResult(NewResult("INSERT", 1)).Times(3)
+	mock.ExpectQuery("SELECT").WillReturnRows(NewRows([]string{"a"})).Maybe()
+
+	a.NoError(mock.Ping(ctx))
+	_, _ = mock.Exec(ctx, "INSERT INTO users SELECT name, email, created_at FROM staging")
+	_, _ = mock.Exec(ctx, "INSERT INTO users SELECT name, email, created_at FROM staging")
+	a.Regexp(`^expectations: 1 of 3 fulfilled, 1 pending
+	\[ok\] 1/1 calls of ExpectPing\(\) declared at info_test\.go:\d+
+	\[pending\] 2/3 calls of ExpectExec\(INSERT INTO users SELECT name, email, cr…\) declared at info_test\.go:\d+
+	\[optional\] 0/1 calls of ExpectQuery\(SELECT\) declared at info_test\.go:\d+
+$`, mock.Summary())
+}
+
+func TestDeclarationSiteInErrors(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	mock.ExpectExec("UPDATE").WithArgs(1).WillReturnResult(NewResult("UPDATE", 1))
+	_, err := mock.Exec(ctx, "UPDATE", 2)
+	a.ErrorContains(err, "declared at info_test.go:")
+	_, err = mock.Query(ctx, "SELECT")
+	a.ErrorContains(err, "next expectation declared at info_test.go:")
+	a.ErrorContains(mock.ExpectationsWereMet(), "remaining expectation declared at info_test.go:")
+}
+
+func queryFromRepository(mock PgxConnIface) error {
+	_, err := mock.Exec(ctx, "DELETE FROM users")
+	return err
+}
+
+func TestCallStackOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	mock, _ := NewConn(CallStackOption(true))
+	mock.ExpectPing()
+	err := queryFromRepository(mock)
+	a.ErrorContains(err, "call stack:")
+	a.ErrorContains(err, "pgxmock/v4.queryFromRepository")
+	a.NotContains(err.Error(), "findExpectationFunc")
+
+	mock, _ = NewConn()
+	a.NotContains(queryFromRepository(mock).Error(), "call stack:")
+}
+
+func TestOnExpectationMatchedOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	var matched []string
+	mock, _ := NewConn(OnExpectationMatchedOption(func(e ExpectationInfo, call *Call) {
+		matched = append(matched, e.Label+" "+call.Method+" "+call.SQL)
+		if q, ok := e.Expectation.(*ExpectedExec); ok {
+			a.Contains(q.String(), "matches sql: 'UPDATE'")
+		}
+	}))
+	mock.ExpectPing()
+	mock.ExpectExec("UPDATE").WillReturnResult(NewResult("UPDATE", 1))
+	a.NoError(mock.Ping(ctx))
+	_, err := mock.Exec(ctx, "INSERT")
+	a.Error(err)
+	_, err = mock.Exec(ctx, "UPDATE users")
+	a.NoError(err)
+	a.Equal([]string{"ExpectedPing #0 Ping() ", "ExpectedExec #1 Exec() UPDATE users"}, matched)
+}
@@ -0,0 +1,90 @@
+package pgxmock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Call describes a single call to the mocked pgx method.
+// SQL and Args are set only for methods accepting them,
+// e.g. Query(), QueryRow(), Exec() and Prepare().
+type Call struct {
+	Method string
+	SQL    string
+	Args   []any
+
+	expectation expectation // matched expectation if any
+	number      uint        // number of the call matching the expectation
+	tx          *pgxmockTx  // transaction the call is issued in, nil for the mock
+}
+
+// CallHandler handles the call to the mocked pgx method by
+// matching it against expectations and returns the resulting error.
+type CallHandler func(ctx context.Context, call *Call) error
+
+// Middleware wraps the CallHandler to add some cross-cutting behavior,
+// e.g. logging, artificial latency or chaos injection. Middleware may
+// alter the call before passing it to the next handler. If the next
+// handler is not called, the middleware must return an error, since
+// no expectation is matched and hence no result is available.
+type Middleware func(next CallHandler) CallHandler
+
+// Use adds middlewares to the mock. Middlewares are applied
+// to every call in the order they were added, i.e. the first
+// middleware is the outermost one.
+func (c *pgxmock) Use(middlewares ...Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// handle passes the call through all middlewares to the handler h
+// and reports the failed call returned to the caller, see SetTestReporter
+func (c *pgxmock) handle(ctx context.Context, call *Call, h CallHandler) error {
+	err := c.dispatch(ctx, call, h)
+	c.report(err)
+	return err
+}
+
+// dispatch passes the call through all middlewares to the handler h
+func (c *pgxmock) dispatch(ctx context.Context, call *Call, h CallHandler) error {
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+	start := c.clock.Now()
+	c.audit(call)
+	err := c.forbiddenQuery(call)
+	if err != nil {
+		err = c.failure(err)
+	} else if c.connBusy(call) {
+		err = c.failure(fmt.Errorf("%s: %w", call.Method, errConnBusy))
+	} else {
+		c.switchServerConn(call)
+		err = h(ctx, call)
+	}
+	c.logCall(call, start, c.clock.Now().Sub(start), err)
+	return err
+}
+
+// errConnBusy is returned by the strict connection mock, same as pgx does
+var errConnBusy = errors.New("conn busy")
+
+// connBusy checks whether the rows returned by the strict connection
+// are still open, so the connection cannot be used for the call
+func (c *pgxmock) connBusy(call *Call) bool {
+	if !c.strictConn || call.Method == "Close()" {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.openRows != nil
+}
+
+// releaseRows frees the strict connection kept busy by the rows,
+// once they are closed or fully read
+func (c *pgxmock) releaseRows(rs *rowSets) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.openRows == rs {
+		c.openRows = nil
+	}
+}
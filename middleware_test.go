@@ -0,0 +1,60 @@
+package pgxmock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUse(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	var log []string
+	mock.Use(
+		func(next CallHandler) CallHandler {
+			return func(ctx context.Context, call *Call) error {
+				log = append(log, call.Method+" "+call.SQL)
+				return next(ctx, call)
+			}
+		},
+		func(next CallHandler) CallHandler {
+			return func(ctx context.Context, call *Call) error {
+				if call.Method == "Ping()" {
+					return errors.New("chaos")
+				}
+				call.SQL = "SELECT 1"
+				return next(ctx, call)
+			}
+		},
+	)
+
+	mock.ExpectExec("SELECT 1").WillReturnResult(NewResult("SELECT", 1))
+
+	a.EqualError(mock.Ping(ctx), "chaos")
+	_, err := mock.Exec(ctx, "SELECT 42")
+	a.NoError(err)
+	a.Equal([]string{"Ping() ", "Exec() SELECT 42"}, log)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestUseShortCircuit(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	// the middleware neither calls the next handler nor returns an error
+	mock.Use(func(CallHandler) CallHandler {
+		return func(context.Context, *Call) error { return nil }
+	})
+
+	var n int
+	a.NotPanics(func() {
+		err := mock.QueryRow(ctx, "SELECT 1").Scan(&n)
+		a.ErrorContains(err, "the middleware must call the next handler or return an error")
+	})
+	a.NoError(mock.ExpectationsWereMet())
+}
@@ -0,0 +1,147 @@
+package pgxmock
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	pgconn "github.com/jackc/pgx/v5/pgconn"
+)
+
+// Migration describes the conversation of a schema migration tool, e.g.
+// tern, golang-migrate or a custom migrator built on pgx, expected by
+// ExpectMigration.
+type Migration struct {
+	// VersionTable is the table keeping the schema version, optionally
+	// qualified with the schema, "schema_version" by default or
+	// "schema_migrations" with VersionInsert
+	VersionTable string
+	// CurrentVersion is the version returned by the version query
+	CurrentVersion int64
+	// Statements are the expected statements of the migrations applied in
+	// order, one per migration. They are matched by the mock QueryMatcher.
+	Statements []string
+	// NoLock disables the advisory lock expectations
+	NoLock bool
+	// NoTx disables the transaction expectations around every migration
+	NoTx bool
+	// ResetAll expects "reset all" after every statement, as tern does
+	// to reset the settings changed by the migration
+	ResetAll bool
+	// VersionInsert expects the version to be written the way
+	// golang-migrate does: every migration is surrounded by transactions
+	// truncating the version table and inserting the version with the
+	// dirty flag, true before the statement and false after it. The
+	// version query returns the false dirty flag too.
+	VersionInsert bool
+	// Err is returned by the last statement, so the version is not updated
+	// and its transaction is expected to be rolled back, or the version is
+	// left dirty with VersionInsert
+	Err error
+}
+
+// ExpectMigration declares the typical migration conversation on the mock:
+//   - Exec "SELECT pg_advisory_lock($1)";
+//   - Query "SELECT version FROM schema_version" returning CurrentVersion;
+//   - for every statement Begin, Exec of the statement,
+//     Exec "UPDATE schema_version SET version = $1" and Commit,
+//     or Rollback after the last statement failing with Err;
+//   - Exec "SELECT pg_advisory_unlock($1)".
+//
+// Helper patterns are case-insensitive, e.g. tern sends lowercase SQL, and
+// rely on the default QueryMatcherRegexp. Versions set are verified to be
+// incremented from CurrentVersion. See Migration.VersionInsert for the
+// golang-migrate conversation. The expectations of the statements are
+// returned, e.g. to make one of them fail.
+func ExpectMigration(mock Expecter, m Migration) []*ExpectedExec {
+	table := versionTablePattern(m)
+	if !m.NoLock {
+		mock.ExpectExec(`(?i)SELECT pg_advisory_lock\(`).WithArgs(AnyArg()).
+			WillReturnResult(NewResult("SELECT", 1))
+	}
+	if m.VersionInsert {
+		mock.ExpectQuery(`(?i)SELECT version, dirty FROM ` + table).
+			WillReturnRows(NewRows([]string{"version", "dirty"}).AddRow(m.CurrentVersion, false))
+	} else {
+		mock.ExpectQuery(`(?i)SELECT version FROM ` + table).
+			WillReturnRows(NewRows([]string{"version"}).AddRow(m.CurrentVersion))
+	}
+	statements := make([]*ExpectedExec, len(m.Statements))
+	for i, stmt := range m.Statements {
+		version := m.CurrentVersion + int64(i) + 1
+		failed := m.Err != nil && i == len(m.Statements)-1
+		exec := func(tx TxExpecter) {
+			tag := writeStatement(stmt)
+			if tag == "" {
+				tag = "DO"
+			}
+			statements[i] = tx.ExpectExec(stmt).WillReturnResult(pgconn.NewCommandTag(tag))
+			if failed {
+				statements[i].WillReturnError(m.Err)
+				return
+			}
+			if m.ResetAll {
+				tx.ExpectExec(`(?i)^reset all$`).WillReturnResult(pgconn.NewCommandTag("RESET"))
+			}
+		}
+		if m.VersionInsert {
+			expectVersionInsert(mock, table, version, true)
+			exec(mock)
+			if !failed {
+				expectVersionInsert(mock, table, version, false)
+			}
+			continue
+		}
+		migrate := func(tx TxExpecter) {
+			exec(tx)
+			if !failed {
+				tx.ExpectExec(`(?i)UPDATE ` + table + ` SET version`).
+					WithArgs(ValueArg(version)).
+					WillReturnResult(NewResult("UPDATE", 1))
+			}
+		}
+		switch {
+		case m.NoTx:
+			migrate(mock)
+		case failed:
+			mock.ExpectBegin()
+			migrate(mock)
+			mock.ExpectRollback()
+		default:
+			mock.ExpectTx(migrate)
+		}
+	}
+	if !m.NoLock {
+		mock.ExpectExec(`(?i)SELECT pg_advisory_unlock\(`).WithArgs(AnyArg()).
+			WillReturnResult(NewResult("SELECT", 1))
+	}
+	return statements
+}
+
+// versionTablePattern returns the pattern of the version table name,
+// every part of which may be quoted, e.g. "public"."schema_migrations"
+func versionTablePattern(m Migration) string {
+	table := m.VersionTable
+	switch {
+	case table != "":
+	case m.VersionInsert:
+		table = "schema_migrations"
+	default:
+		table = "schema_version"
+	}
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = fmt.Sprintf(`"?%s"?`, regexp.QuoteMeta(part))
+	}
+	return strings.Join(parts, `\.`)
+}
+
+// expectVersionInsert expects the version to be set by golang-migrate
+func expectVersionInsert(mock Expecter, table string, version int64, dirty bool) {
+	mock.ExpectTx(func(tx TxExpecter) {
+		tx.ExpectExec(`(?i)TRUNCATE ` + table).WillReturnResult(NewResult("TRUNCATE TABLE", 0))
+		tx.ExpectExec(`(?i)INSERT INTO `+table+` \(version, dirty\)`).
+			WithArgs(ValueArg(version), dirty).
+			WillReturnResult(NewResult("INSERT", 1))
+	})
+}
@@ -0,0 +1,135 @@
+package pgxmock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ternMigrate is the minimal migrator sending the same SQL as tern
+func ternMigrate(ctx context.Context, conn PgxConnIface, table string, migrations []string) error {
+	if _, err := conn.Exec(ctx, "select pg_advisory_lock($1)", int64(42)); err != nil {
+		return err
+	}
+	defer func() { _, _ = conn.Exec(ctx, "select pg_advisory_unlock($1)", int64(42)) }()
+	var version int32
+	if err := conn.QueryRow(ctx, "select version from "+table).Scan(&version); err != nil {
+		return err
+	}
+	for i := int(version); i < len(migrations); i++ {
+		if err := func() error {
+			tx, err := conn.Begin(ctx)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = tx.Rollback(ctx) }()
+			if _, err = tx.Exec(ctx, migrations[i]); err != nil {
+				return err
+			}
+			if _, err = tx.Exec(ctx, "reset all"); err != nil {
+				return err
+			}
+			if _, err = tx.Exec(ctx, "update "+table+" set version=$1", int32(i+1)); err != nil {
+				return err
+			}
+			return tx.Commit(ctx)
+		}(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// golangMigrate is the minimal migrator sending the same SQL as golang-migrate
+func golangMigrate(ctx context.Context, conn PgxConnIface, table string, migrations []string) error {
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", int64(42)); err != nil {
+		return err
+	}
+	defer func() { _, _ = conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", int64(42)) }()
+	var version int
+	var dirty bool
+	if err := conn.QueryRow(ctx, "SELECT version, dirty FROM "+table+" LIMIT 1").Scan(&version, &dirty); err != nil {
+		return err
+	}
+	setVersion := func(version int, dirty bool) error {
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err = tx.Exec(ctx, "TRUNCATE "+table); err == nil {
+			_, err = tx.Exec(ctx, "INSERT INTO "+table+" (version, dirty) VALUES ($1, $2)", version, dirty)
+		}
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+	for i := version; i < len(migrations); i++ {
+		if err := setVersion(i+1, true); err != nil {
+			return err
+		}
+		if _, err := conn.Exec(ctx, migrations[i]); err != nil {
+			return err
+		}
+		if err := setVersion(i+1, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestExpectMigration(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+	migrations := []string{"CREATE TABLE users (id int)", "ALTER TABLE users ADD name text", "CREATE INDEX ON users (name)"}
+
+	mock, _ := NewConn()
+	ExpectMigration(mock, Migration{
+		CurrentVersion: 1,
+		Statements:     []string{"ALTER TABLE users", "CREATE INDEX"},
+		ResetAll:       true,
+	})
+	a.NoError(ternMigrate(ctx, mock, "schema_version", migrations))
+	a.NoError(mock.ExpectationsWereMet())
+
+	mock, _ = NewConn()
+	stmts := ExpectMigration(mock, Migration{
+		VersionTable: "public.versions",
+		Statements:   []string{"CREATE TABLE users", "ALTER TABLE users"},
+		ResetAll:     true,
+		Err:          errors.New("column already exists"),
+	})
+	a.Len(stmts, 2)
+	a.EqualError(ternMigrate(ctx, mock, `"public.versions"`, migrations), "column already exists")
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestExpectMigrationVersionInsert(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+	migrations := []string{"CREATE TABLE users (id int)", "ALTER TABLE users ADD name text", "CREATE INDEX ON users (name)"}
+
+	mock, _ := NewConn()
+	ExpectMigration(mock, Migration{
+		VersionTable:   "public.schema_migrations",
+		CurrentVersion: 1,
+		Statements:     []string{"ALTER TABLE users", "CREATE INDEX"},
+		VersionInsert:  true,
+	})
+	a.NoError(golangMigrate(ctx, mock, `"public"."schema_migrations"`, migrations))
+	a.NoError(mock.ExpectationsWereMet())
+
+	mock, _ = NewConn()
+	ExpectMigration(mock, Migration{
+		Statements:    []string{"CREATE TABLE users"},
+		VersionInsert: true,
+		Err:           errors.New("relation already exists"),
+	})
+	a.EqualError(golangMigrate(ctx, mock, `"schema_migrations"`, migrations[:1]), "relation already exists")
+	a.NoError(mock.ExpectationsWereMet(), "the version is left dirty")
+}
@@ -0,0 +1,516 @@
+/*
+Package mockserver serves pgxmock expectations over a real TCP socket speaking
+the PostgreSQL wire protocol, so even code constructing its own *pgx.Conn or
+*pgxpool.Pool from a connection string, e.g. third-party libraries, may be
+tested against the mock.
+
+The server supports the simple query protocol and the extended query protocol
+without prepared statements, which is used by pgx when the connection string
+specifies default_query_exec_mode=exec, as the one returned by Server.DSN does.
+
+Arguments are passed to the mock in the text representation sent by the client,
+e.g. an int argument 42 is matched by WithArgs("42"). Statements returning rows,
+e.g. SELECT or statements with RETURNING clause, are matched with ExpectQuery,
+other statements with ExpectExec. BEGIN, COMMIT and ROLLBACK are matched with
+ExpectBegin, ExpectCommit and ExpectRollback, the connection check with ExpectPing
+and the cancellation with ExpectCancelRequest. The isolation level, access and
+deferrable modes of BEGIN are matched with ExpectBeginTx. Statements sent by the
+client in the transaction are issued in the transaction begun by the session,
+so they match expectations declared with ExpectTx.
+*/
+package mockserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+// Server serves the mock expectations over TCP
+type Server struct {
+	mock     pgxmock.PgxCommonIface
+	listener net.Listener
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	conns    map[net.Conn]struct{}
+}
+
+// Start starts the server for the mock listening on a random local port
+func Start(mock pgxmock.PgxCommonIface) (*Server, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{mock: mock, listener: l, conns: make(map[net.Conn]struct{})}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address the server is listening on
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// DSN returns the connection string for pgx.Connect or pgxpool.New
+func (s *Server) DSN() string {
+	return fmt.Sprintf("postgres://pgxmock@%s/pgxmock?sslmode=disable&default_query_exec_mode=exec", s.Addr())
+}
+
+// Close stops the server and closes all client connections
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, conn)
+				s.mu.Unlock()
+				_ = conn.Close()
+			}()
+			sess := &session{mock: s.mock, backend: pgproto3.NewBackend(conn, conn), typeMap: pgtype.NewMap()}
+			if sess.startup(conn) == nil {
+				sess.serve()
+			}
+		}()
+	}
+}
+
+// session serves the single client connection
+type session struct {
+	mock     pgxmock.PgxCommonIface
+	backend  *pgproto3.Backend
+	typeMap  *pgtype.Map
+	txStatus byte
+	tx       pgx.Tx            // transaction begun by the session
+	failed   bool              // error occurred, messages are ignored until Sync
+	stmts    map[string]string // parsed statements
+	portal   *portal
+}
+
+// portal is the bound statement
+type portal struct {
+	sql           string
+	args          []any
+	resultFormats []int16
+	result        *result
+}
+
+// result of the statement sent to the client
+type result struct {
+	fields []pgproto3.FieldDescription
+	rows   [][][]byte
+	tag    string
+	empty  bool
+}
+
+func (s *session) startup(conn net.Conn) error {
+	for {
+		msg, err := s.backend.ReceiveStartupMessage()
+		if err != nil {
+			return err
+		}
+		switch msg.(type) {
+		case *pgproto3.SSLRequest, *pgproto3.GSSEncRequest:
+			if _, err := conn.Write([]byte("N")); err != nil {
+				return err
+			}
+		case *pgproto3.StartupMessage:
+			s.txStatus = 'I'
+			s.stmts = make(map[string]string)
+			s.backend.Send(&pgproto3.AuthenticationOk{})
+			for name, value := range map[string]string{
+				"server_version":              "17.0",
+				"server_encoding":             "UTF8",
+				"client_encoding":             "UTF8",
+				"DateStyle":                   "ISO, MDY",
+				"integer_datetimes":           "on",
+				"standard_conforming_strings": "on",
+			} {
+				s.backend.Send(&pgproto3.ParameterStatus{Name: name, Value: value})
+			}
+			s.backend.Send(&pgproto3.BackendKeyData{ProcessID: 1, SecretKey: 1})
+			s.backend.Send(&pgproto3.ReadyForQuery{TxStatus: s.txStatus})
+			return s.backend.Flush()
+		case *pgproto3.CancelRequest:
+			// the cancel request is sent over the separate connection closed after
+			_ = s.mock.CancelRequest(context.Background())
+			return errors.New("cancel request served")
+		default:
+			return fmt.Errorf("unexpected startup message %T", msg)
+		}
+	}
+}
+
+func (s *session) serve() {
+	for {
+		msg, err := s.backend.Receive()
+		if err != nil {
+			return
+		}
+		switch msg := msg.(type) {
+		case *pgproto3.Query:
+			s.simpleQuery(msg.String)
+		case *pgproto3.Sync:
+			s.failed = false
+			s.portal = nil
+			s.backend.Send(&pgproto3.ReadyForQuery{TxStatus: s.txStatus})
+		case *pgproto3.Terminate:
+			return
+		default:
+			if !s.failed {
+				s.extendedQuery(msg)
+			}
+		}
+		if s.backend.Flush() != nil {
+			return
+		}
+	}
+}
+
+func (s *session) simpleQuery(sql string) {
+	defer func() {
+		s.failed = false
+		s.backend.Send(&pgproto3.ReadyForQuery{TxStatus: s.txStatus})
+	}()
+	res, err := s.run(sql, nil, nil)
+	if err != nil {
+		s.sendError(err)
+		return
+	}
+	if res.empty {
+		s.backend.Send(&pgproto3.EmptyQueryResponse{})
+		return
+	}
+	if res.fields != nil {
+		s.backend.Send(&pgproto3.RowDescription{Fields: res.fields})
+	}
+	s.sendRows(res)
+}
+
+func (s *session) extendedQuery(msg pgproto3.FrontendMessage) {
+	switch msg := msg.(type) {
+	case *pgproto3.Parse:
+		s.stmts[msg.Name] = msg.Query
+		s.backend.Send(&pgproto3.ParseComplete{})
+	case *pgproto3.Bind:
+		sql, ok := s.stmts[msg.PreparedStatement]
+		if !ok {
+			s.sendError(fmt.Errorf("prepared statement \"%s\" does not exist", msg.PreparedStatement))
+			return
+		}
+		args := make([]any, len(msg.Parameters))
+		for i, p := range msg.Parameters {
+			switch {
+			case p == nil:
+				args[i] = nil
+			case formatCode(msg.ParameterFormatCodes, i) == pgtype.BinaryFormatCode:
+				args[i] = p
+			default:
+				args[i] = string(p)
+			}
+		}
+		s.portal = &portal{sql: sql, args: args, resultFormats: msg.ResultFormatCodes}
+		s.backend.Send(&pgproto3.BindComplete{})
+	case *pgproto3.Describe:
+		if msg.ObjectType == 'S' {
+			s.sendError(errors.New("prepared statements are not supported, use default_query_exec_mode=exec"))
+			return
+		}
+		res, err := s.execPortal()
+		if err != nil {
+			s.sendError(err)
+			return
+		}
+		if res.fields != nil {
+			s.backend.Send(&pgproto3.RowDescription{Fields: res.fields})
+		} else {
+			s.backend.Send(&pgproto3.NoData{})
+		}
+	case *pgproto3.Execute:
+		res, err := s.execPortal()
+		if err != nil {
+			s.sendError(err)
+			return
+		}
+		if res.empty {
+			s.backend.Send(&pgproto3.EmptyQueryResponse{})
+			return
+		}
+		s.sendRows(res)
+	case *pgproto3.Close:
+		if msg.ObjectType == 'S' {
+			delete(s.stmts, msg.Name)
+		}
+		s.backend.Send(&pgproto3.CloseComplete{})
+	case *pgproto3.Flush:
+	default:
+		s.sendError(fmt.Errorf("unsupported message %T", msg))
+	}
+}
+
+// execPortal runs the bound statement once, either on Describe or Execute
+func (s *session) execPortal() (*result, error) {
+	if s.portal == nil {
+		return nil, errors.New("portal does not exist")
+	}
+	if s.portal.result == nil {
+		res, err := s.run(s.portal.sql, s.portal.args, s.portal.resultFormats)
+		if err != nil {
+			return nil, err
+		}
+		s.portal.result = res
+	}
+	return s.portal.result, nil
+}
+
+var returningRe = regexp.MustCompile(`(?is)\breturning\b`)
+
+// returnsRows checks whether the statement is expected with ExpectQuery
+func returnsRows(sql string) bool {
+	switch firstWord(sql) {
+	case "select", "with", "values", "show", "table", "explain", "fetch":
+		return true
+	}
+	return returningRe.MatchString(sql)
+}
+
+func firstWord(sql string) string {
+	fields := strings.Fields(strings.ToLower(sql))
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimRight(fields[0], ";")
+}
+
+func (s *session) run(sql string, args []any, resultFormats []int16) (*result, error) {
+	ctx := context.Background()
+	if strings.TrimSpace(sql) == "-- ping" { // sent by pgconn.PgConn.Ping
+		return &result{empty: true}, s.mock.Ping(ctx)
+	}
+	switch firstWord(sql) {
+	case "":
+		return &result{empty: true}, nil
+	case "begin", "start":
+		if s.tx != nil { // PostgreSQL warns about the transaction in progress
+			return &result{tag: "BEGIN"}, nil
+		}
+		tx, err := s.mock.BeginTx(ctx, txOptions(sql))
+		if err != nil {
+			return nil, err
+		}
+		s.tx, s.txStatus = tx, 'T'
+		return &result{tag: "BEGIN"}, nil
+	case "commit", "end":
+		tag := "COMMIT"
+		if s.txStatus == 'E' { // the failed transaction is rolled back instead
+			tag = "ROLLBACK"
+		}
+		return &result{tag: tag}, s.endTx(pgx.Tx.Commit)
+	case "rollback", "abort":
+		return &result{tag: "ROLLBACK"}, s.endTx(pgx.Tx.Rollback)
+	}
+	if !returnsRows(sql) {
+		tag, err := s.conn().Exec(ctx, sql, args...)
+		if err != nil {
+			return nil, err
+		}
+		return &result{tag: tag.String()}, nil
+	}
+	rows, err := s.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return s.collect(rows, resultFormats)
+}
+
+// conn returns the transaction begun by the session or the mock outside of it
+func (s *session) conn() pgx.Tx {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.mock
+}
+
+// endTx commits or rolls back the transaction begun by the session. The same
+// as PostgreSQL, COMMIT and ROLLBACK outside of it only warn, so they never end
+// the transaction of another session.
+func (s *session) endTx(end func(pgx.Tx, context.Context) error) error {
+	tx := s.tx
+	if tx == nil {
+		return nil
+	}
+	s.tx, s.txStatus = nil, 'I'
+	return end(tx, context.Background())
+}
+
+var (
+	isoLevelRe   = regexp.MustCompile(`\bisolation\s+level\s+(serializable|repeatable\s+read|read\s+committed|read\s+uncommitted)\b`)
+	accessModeRe = regexp.MustCompile(`\bread\s+(only|write)\b`)
+	deferrableRe = regexp.MustCompile(`\b(not\s+)?deferrable\b`)
+	spacesRe     = regexp.MustCompile(`\s+`)
+)
+
+// txOptions parses the transaction modes of BEGIN or START TRANSACTION,
+// e.g. "begin isolation level serializable read only" sent by pgx
+func txOptions(sql string) (opts pgx.TxOptions) {
+	sql = strings.ToLower(sql)
+	if m := isoLevelRe.FindStringSubmatch(sql); m != nil {
+		opts.IsoLevel = pgx.TxIsoLevel(spacesRe.ReplaceAllString(m[1], " "))
+		sql = strings.Replace(sql, m[0], "", 1) // "read committed" is not the access mode
+	}
+	if m := accessModeRe.FindStringSubmatch(sql); m != nil {
+		opts.AccessMode = pgx.TxAccessMode("read " + m[1])
+	}
+	if m := deferrableRe.FindStringSubmatch(sql); m != nil {
+		opts.DeferrableMode = pgx.Deferrable
+		if m[1] != "" {
+			opts.DeferrableMode = pgx.NotDeferrable
+		}
+	}
+	return
+}
+
+// collect reads all rows encoding their values in the wire format
+func (s *session) collect(rows pgx.Rows, resultFormats []int16) (*result, error) {
+	defs := rows.FieldDescriptions()
+	var values [][]any
+	for rows.Next() {
+		v, err := rows.Values()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	res := &result{fields: make([]pgproto3.FieldDescription, len(defs))}
+	for i, def := range defs {
+		oid := def.DataTypeOID
+		if _, ok := s.typeMap.TypeForOID(oid); !ok {
+			oid = s.guessOID(values, i)
+		}
+		res.fields[i] = pgproto3.FieldDescription{
+			Name:         []byte(def.Name),
+			TableOID:     def.TableOID,
+			DataTypeOID:  oid,
+			DataTypeSize: def.DataTypeSize,
+			TypeModifier: def.TypeModifier,
+			Format:       formatCode(resultFormats, i),
+		}
+	}
+	for _, row := range values {
+		data := make([][]byte, len(row))
+		for i, v := range row {
+			if v == nil {
+				continue
+			}
+			buf, err := s.encode(res.fields[i], v)
+			if err != nil {
+				return nil, fmt.Errorf("cannot encode value of column '%s': %w", res.fields[i].Name, err)
+			}
+			data[i] = buf
+		}
+		res.rows = append(res.rows, data)
+	}
+	if tag := rows.CommandTag().String(); tag != "" {
+		res.tag = tag
+	} else {
+		res.tag = fmt.Sprintf("SELECT %d", len(values))
+	}
+	return res, nil
+}
+
+// guessOID finds the data type of the untyped column by its first value
+func (s *session) guessOID(values [][]any, col int) uint32 {
+	for _, row := range values {
+		if row[col] == nil {
+			continue
+		}
+		if t, ok := s.typeMap.TypeForValue(row[col]); ok {
+			return t.OID
+		}
+		break
+	}
+	return pgtype.TextOID
+}
+
+func (s *session) encode(field pgproto3.FieldDescription, v any) ([]byte, error) {
+	buf, err := s.typeMap.Encode(field.DataTypeOID, field.Format, v, nil)
+	if err != nil && field.DataTypeOID == pgtype.TextOID && field.Format == pgtype.TextFormatCode {
+		return []byte(fmt.Sprint(v)), nil
+	}
+	return buf, err
+}
+
+func (s *session) sendRows(res *result) {
+	for _, row := range res.rows {
+		s.backend.Send(&pgproto3.DataRow{Values: row})
+	}
+	s.backend.Send(&pgproto3.CommandComplete{CommandTag: []byte(res.tag)})
+}
+
+func (s *session) sendError(err error) {
+	s.failed = true
+	if s.txStatus == 'T' { // the error aborts the transaction
+		s.txStatus = 'E'
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		pgErr = &pgconn.PgError{Severity: "ERROR", Code: "XX000", Message: err.Error()}
+	}
+	s.backend.Send(&pgproto3.ErrorResponse{
+		Severity:       pgErr.Severity,
+		Code:           pgErr.Code,
+		Message:        pgErr.Message,
+		Detail:         pgErr.Detail,
+		Hint:           pgErr.Hint,
+		SchemaName:     pgErr.SchemaName,
+		TableName:      pgErr.TableName,
+		ColumnName:     pgErr.ColumnName,
+		ConstraintName: pgErr.ConstraintName,
+	})
+}
+
+// formatCode returns the format code of the i-th value
+func formatCode(codes []int16, i int) int16 {
+	switch len(codes) {
+	case 0:
+		return pgtype.TextFormatCode
+	case 1:
+		return codes[0]
+	}
+	return codes[i]
+}
@@ -0,0 +1,224 @@
+package mockserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.Background()
+
+func TestServerWithPool(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := pgxmock.NewConn()
+	srv, err := Start(mock)
+	a.NoError(err)
+	defer srv.Close()
+
+	mock.ExpectPing()
+	mock.ExpectQuery("SELECT id, name FROM users WHERE id = \\$1").
+		WithArgs("42").
+		WillReturnRows(mock.NewRows([]string{"id", "name"}).AddRow(int64(42), "john"))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users").
+		WithArgs("john", nil).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("DELETE FROM users").
+		WithArgs("1").
+		WillReturnError(&pgconn.PgError{Severity: "ERROR", Code: "23503", Message: "violates foreign key constraint"})
+
+	pool, err := pgxpool.New(ctx, srv.DSN())
+	a.NoError(err)
+	defer pool.Close()
+	a.NoError(pool.Ping(ctx))
+
+	var id int64
+	var name string
+	a.NoError(pool.QueryRow(ctx, "SELECT id, name FROM users WHERE id = $1", 42).Scan(&id, &name))
+	a.Equal(int64(42), id)
+	a.Equal("john", name)
+
+	a.NoError(pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, "UPDATE users SET name = $1, email = $2", "john", nil)
+		a.Equal(int64(1), tag.RowsAffected())
+		return err
+	}))
+
+	_, err = pool.Exec(ctx, "DELETE FROM users WHERE id = $1", 1)
+	var pgErr *pgconn.PgError
+	a.True(errors.As(err, &pgErr))
+	a.Equal("23503", pgErr.Code)
+
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestServerFailedTransaction(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := pgxmock.NewConn()
+	srv, err := Start(mock)
+	a.NoError(err)
+	defer srv.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO users").
+		WithArgs("1").
+		WillReturnError(&pgconn.PgError{Severity: "ERROR", Code: "23505", Message: "duplicate key value"})
+	mock.ExpectCommit()
+
+	conn, err := pgx.Connect(ctx, srv.DSN())
+	a.NoError(err)
+	defer conn.Close(ctx)
+	tx, err := conn.Begin(ctx)
+	a.NoError(err)
+	_, err = tx.Exec(ctx, "INSERT INTO users VALUES ($1)", 1)
+	a.Error(err)
+	a.Equal(byte('E'), conn.PgConn().TxStatus())
+	a.ErrorIs(tx.Commit(ctx), pgx.ErrTxCommitRollback)
+	a.Equal(byte('I'), conn.PgConn().TxStatus())
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestServerTransactionScope(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := pgxmock.NewConn()
+	srv, err := Start(mock)
+	a.NoError(err)
+	defer srv.Close()
+
+	mock.ExpectBeginTx(pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	mock.ExpectQuery("SELECT name FROM users").WillReturnRows(mock.NewRows([]string{"name"}).AddRow("john"))
+	mock.ExpectCommit()
+	mock.ExpectTx(func(tx pgxmock.TxExpecter) {
+		tx.ExpectExec("UPDATE users").WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	})
+
+	conn, err := pgx.Connect(ctx, srv.DSN())
+	a.NoError(err)
+	defer conn.Close(ctx)
+	var name string
+	a.NoError(pgx.BeginTxFunc(ctx, conn, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		return tx.QueryRow(ctx, "SELECT name FROM users").Scan(&name)
+	}))
+	a.Equal("john", name)
+	a.NoError(pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, "UPDATE users SET name = 'jim'")
+		return err
+	}))
+	a.NoError(mock.ExpectationsWereMet())
+
+	// every session commits its own transaction
+	mock.MatchExpectationsInOrder(false)
+	for _, table := range []string{"users", "orders"} {
+		mock.ExpectTx(func(tx pgxmock.TxExpecter) {
+			tx.ExpectExec("DELETE FROM " + table).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		})
+	}
+	other, err := pgx.Connect(ctx, srv.DSN())
+	a.NoError(err)
+	defer other.Close(ctx)
+	tx1, err := conn.Begin(ctx)
+	a.NoError(err)
+	tx2, err := other.Begin(ctx)
+	a.NoError(err)
+	_, err = tx1.Exec(ctx, "DELETE FROM users")
+	a.NoError(err)
+	_, err = tx2.Exec(ctx, "DELETE FROM orders")
+	a.NoError(err)
+	a.NoError(tx1.Commit(ctx))
+	a.NoError(tx2.Commit(ctx))
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestServerUnexpectedQuery(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := pgxmock.NewConn()
+	srv, err := Start(mock)
+	a.NoError(err)
+	defer srv.Close()
+
+	mock.ExpectQuery("SELECT 1").WillReturnRows(mock.NewRows([]string{"n"}).AddRow(1))
+	conn, err := pgx.Connect(ctx, srv.DSN())
+	a.NoError(err)
+	defer conn.Close(ctx)
+
+	_, err = conn.Exec(ctx, "INSERT INTO users VALUES ($1)", 1)
+	a.ErrorContains(err, "was not expected")
+	var n int
+	a.NoError(conn.QueryRow(ctx, "SELECT 1").Scan(&n))
+	a.Equal(1, n)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestServerConnForQueryRewriter(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	backend, _ := pgxmock.NewConn()
+	srv, err := Start(backend)
+	a.NoError(err)
+	defer srv.Close()
+	conn, err := pgx.Connect(ctx, srv.DSN())
+	a.NoError(err)
+	defer conn.Close(ctx)
+
+	mock, _ := pgxmock.NewConn(pgxmock.QueryRewriterConnOption(conn), pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	mock.ExpectExec("INSERT INTO users(name) VALUES (@name)").
+		WithRewrittenSQL("INSERT INTO users(name) VALUES ($1::text)").
+		WithArgs("john").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	_, err = mock.Exec(ctx, "INSERT INTO users(name) VALUES (@name)", typedArg{"name", "john"})
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+
+	mock, _ = pgxmock.NewConn()
+	mock.ExpectExec("INSERT").WithArgs("john").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	_, err = mock.Exec(ctx, "INSERT INTO users(name) VALUES (@name)", typedArg{"name", "john"})
+	a.ErrorContains(err, "no connection to look up the type")
+}
+
+// typedArg rewrites the named placeholder into the positional one
+// cast to the type found in the type map of the connection
+type typedArg struct {
+	name  string
+	value any
+}
+
+func (ta typedArg) RewriteQuery(_ context.Context, conn *pgx.Conn, sql string, _ []any) (string, []any, error) {
+	if conn == nil {
+		return "", nil, errors.New("no connection to look up the type")
+	}
+	typ, ok := conn.TypeMap().TypeForValue(ta.value)
+	if !ok {
+		return "", nil, fmt.Errorf("unknown type of %v", ta.value)
+	}
+	return strings.ReplaceAll(sql, "@"+ta.name, "$1::"+typ.Name), []any{ta.value}, nil
+}
+
+func TestServerCancelRequest(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := pgxmock.NewConn()
+	srv, err := Start(mock)
+	a.NoError(err)
+	defer srv.Close()
+	mock.ExpectCancelRequest()
+
+	conn, err := pgx.Connect(ctx, srv.DSN())
+	a.NoError(err)
+	defer conn.Close(ctx)
+	a.NoError(conn.PgConn().CancelRequest(ctx))
+	a.Eventually(func() bool { return mock.ExpectationsWereMet() == nil }, time.Second, time.Millisecond)
+}
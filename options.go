@@ -1,5 +1,13 @@
 package pgxmock
 
+import (
+	"fmt"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
 // QueryMatcherOption allows to customize SQL query matcher
 // and match SQL query strings in more sophisticated ways.
 // The default QueryMatcher is QueryMatcherRegexp.
@@ -9,3 +17,329 @@ func QueryMatcherOption(queryMatcher QueryMatcher) func(*pgxmock) error {
 		return nil
 	}
 }
+
+// ClockOption allows to customize the clock used to simulate
+// delays set with WillDelayFor. A fake clock makes it possible
+// to advance delays virtually instead of really sleeping.
+// The default Clock uses time package functions.
+func ClockOption(clock Clock) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.clock = clock
+		return nil
+	}
+}
+
+// CSVColumnParserOption allows to customize the function which converts
+// trimmed csv column string to a value for all rows created by the mock
+// NewRows and NewRowsWithColumnDefinition methods. The default parser
+// is the package level CSVColumnParser.
+func CSVColumnParserOption(parser func(string) interface{}) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.csvParser = parser
+		return nil
+	}
+}
+
+// CallStackOption allows to include the call stack of the code under
+// test into errors about unexpected calls, making it obvious which
+// function issued the surprising call. Disabled by default.
+func CallStackOption(enabled bool) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.callStacks = enabled
+		return nil
+	}
+}
+
+// RowsWillBeClosedOption allows to require rows returned by every query
+// to be closed, as if RowsWillBeClosed was set for every query expectation.
+// ExpectationsWereMet lists all queries which rows were not closed.
+func RowsWillBeClosedOption(enabled bool) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.rowsMustBeClosed = enabled
+		return nil
+	}
+}
+
+// RowsMustBeFullyReadOption allows to require rows returned by every query
+// to be iterated until the end, as if RowsMustBeFullyRead was set for every
+// query expectation. ExpectationsWereMet lists all queries which rows were not.
+func RowsMustBeFullyReadOption(enabled bool) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.rowsMustBeFullyRead = enabled
+		return nil
+	}
+}
+
+// StrictConnOption allows to simulate a single connection, which returns
+// a "conn busy" error if a new command is issued before the rows returned
+// by the previous query were closed or read until the end. Has no effect
+// for the pool mock, since every pool command uses its own connection.
+func StrictConnOption(enabled bool) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.strictConn = enabled
+		return nil
+	}
+}
+
+// TypesOption allows to register extra data types, e.g. extension types like
+// pgvector's vector, enums or composites, for all rows created by the mock
+// NewRows and NewRowsWithColumnDefinition methods. The types are used to scan
+// values into destinations, which are not assignable from the row value.
+func TypesOption(types ...*pgtype.Type) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.types = append(s.types, types...)
+		return nil
+	}
+}
+
+// TracerOption allows to trace calls to the mock the same way pgx does
+// with pgx.ConnConfig.Tracer, e.g. using tracelog.TraceLog adapter to
+// verify the logger configuration. The tracer may additionally implement
+// pgx.BatchTracer, pgx.CopyFromTracer and pgx.PrepareTracer interfaces.
+func TracerOption(tracer pgx.QueryTracer) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.tracer = tracer
+		return nil
+	}
+}
+
+// OnExpectationMatchedOption allows to set the hook invoked with the matched
+// expectation and the actual call each time a call matches an expectation,
+// e.g. for custom assertions, fixtures coverage tracking or debugging.
+func OnExpectationMatchedOption(hook func(e ExpectationInfo, call *Call)) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.onMatched = hook
+		return nil
+	}
+}
+
+// EncodableArgsOption allows to check that every argument passed to Query,
+// QueryRow, Exec and batch queries could be encoded by pgx using the default
+// type map extended with types registered by TypesOption. So an unsupported
+// value, e.g. a plain struct, fails against the mock as it would against
+// PostgreSQL. Disabled by default.
+func EncodableArgsOption(enabled bool) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.encodableArgs = enabled
+		return nil
+	}
+}
+
+// QueryRewriterConnOption allows to set the connection passed to
+// pgx.QueryRewriter arguments, e.g. for custom rewriters consulting the
+// connection TypeMap. Such a connection may be obtained by connecting
+// pgx to the mockserver package server. The default connection is nil.
+func QueryRewriterConnOption(conn *pgx.Conn) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.rewriterConn = conn
+		return nil
+	}
+}
+
+// AuditRulesOption allows to evaluate audit rules on every statement
+// executed with the mock, e.g. AuditNoSelectStar or AuditWhereRequired.
+// Calls are not failed, but ExpectationsWereMet reports all violations.
+func AuditRulesOption(rules ...AuditRule) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.auditRules = append(s.auditRules, rules...)
+		return nil
+	}
+}
+
+// DefaultDelayOption allows to set the baseline delay of every expected
+// call, overridden by WillDelayFor, so concurrency bugs hidden by instant
+// in-memory responses surface in tests. Delays are simulated with the
+// mock Clock, see ClockOption. The default delay is zero.
+func DefaultDelayOption(delay time.Duration) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.defaultDelay = delay
+		return nil
+	}
+}
+
+// OrderByTypeOption allows to enforce the order of expectations only among
+// expectations of the same type, e.g. all Exec calls must be made in order
+// and all Query calls must be made in order, but a Query may be called
+// before an Exec declared earlier. Useful for code interleaving reads and
+// writes from separate components. Has no effect if expectations are not
+// matched in order, see MatchExpectationsInOrder.
+func OrderByTypeOption(enabled bool) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.orderByType = enabled
+		return nil
+	}
+}
+
+// VerifyPoolCloseOption allows to require the pool to be closed, so tests
+// catch the missing pool shutdown: ExpectationsWereMet fails if Close() was
+// never called. The first Close() is expected implicitly unless ExpectClose
+// was declared, and since it returns no error, ExpectationsWereMet reports
+// the unexpected Close() call, e.g. the repeated one. Has no effect for the
+// connection mock.
+func VerifyPoolCloseOption(enabled bool) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.verifyPoolClose = enabled
+		return nil
+	}
+}
+
+// RowsLeakDetectionOption allows to track all rows returned by queries and
+// to report by ExpectationsWereMet the ones never closed together with the
+// query SQL and the call site in the code under test. Rows returned to
+// QueryRow are not tracked, since they are closed by Scan.
+func RowsLeakDetectionOption(enabled bool) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.detectRowsLeaks = enabled
+		return nil
+	}
+}
+
+// RequireVerificationOption allows to fail the test t if ExpectationsWereMet
+// of the mock was never called before the test and its subtests complete,
+// since skipped verification silently passes any test.
+func RequireVerificationOption(t TestingT) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		site := declarationSite()
+		t.Cleanup(func() {
+			t.Helper()
+			if !s.wasVerified() {
+				t.Errorf("ExpectationsWereMet was never called for the mock created at %s", site)
+			}
+		})
+		return nil
+	}
+}
+
+// DriverBytesOption allows to scan into *pgtype.DriverBytes from QueryRow,
+// which pgx rejects, since the bytes would refer to the buffer released when
+// the row is closed. The mock copies the raw column value instead, so the
+// scanned bytes remain valid after Scan returns for both Query and QueryRow.
+func DriverBytesOption(enabled bool) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.driverBytes = enabled
+		return nil
+	}
+}
+
+// ReadOnlyOption makes the mock behave like a read replica: statements
+// writing data or changing the schema, e.g. INSERT or CREATE, and CopyFrom
+// fail with SQLSTATE 25006 the same way PostgreSQL does in a read-only
+// transaction. Transactions begun with pgx.ReadOnly access mode are always
+// read-only, so this option is not needed to validate them.
+func ReadOnlyOption(enabled bool) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.readOnly = enabled
+		return nil
+	}
+}
+
+// TransactionPoolingOption allows to simulate the transaction pooling mode
+// of PgBouncer and similar poolers, where every transaction and every
+// statement outside of a transaction may be executed on another server
+// connection. Named statements prepared before are unknown there and
+// executing them fails with SQLSTATE 26000, so the statement cache
+// settings and fallbacks may be verified. Disabled by default.
+func TransactionPoolingOption(enabled bool) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.txPooling = enabled
+		return nil
+	}
+}
+
+// TimeScaleOption allows to scale all simulated delays, e.g. 0.01 makes
+// WillDelayFor(time.Second) delay for 10ms, so suites modelling realistic
+// latencies still run fast. Planned delays are reported unscaled.
+func TimeScaleOption(scale float64) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		if scale <= 0 {
+			return fmt.Errorf("time scale must be positive, got %v", scale)
+		}
+		s.timeScale = scale
+		return nil
+	}
+}
+
+// MaxConnsOption allows to limit the virtual size of the pool, so the
+// AcquireConn call blocks until one of n acquired connections is released,
+// e.g. to test back-pressure of the code under test. Unlimited by default.
+// Has no effect for the connection mock.
+func MaxConnsOption(n int32) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		if n <= 0 {
+			return fmt.Errorf("max conns must be positive, got %d", n)
+		}
+		s.conns = connSlots(int(n))
+		return nil
+	}
+}
+
+// PoolHooksOption allows to set the pgxpool lifecycle hooks invoked by the
+// pool mock for connections acquired with AcquireConn, so the connection
+// initialization logic may be tested. Has no effect for the connection mock.
+func PoolHooksOption(hooks PoolHooks) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.poolHooks = hooks
+		return nil
+	}
+}
+
+// ErrorFormatterOption allows to customize messages of errors about
+// unexpected or mismatched calls and of ExpectationsWereMet errors, e.g. to
+// shorten, localize or colorize them or to append links to documentation.
+// The formatter gets the original error, which remains available for
+// errors.Is and errors.As checks. Errors set by WillReturnError are
+// returned as is.
+func ErrorFormatterOption(formatter func(err error) string) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.errorFormatter = formatter
+		return nil
+	}
+}
+
+// VerifyTxCompletionOption allows to verify that every transaction begun
+// was committed or rolled back, so ExpectationsWereMet reports leaked
+// transactions together with the call sites of Begin in the code under test.
+func VerifyTxCompletionOption(enabled bool) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.verifyTx = enabled
+		return nil
+	}
+}
+
+// RequireCloseOption allows to require the connection or the pool to be
+// closed, so ExpectationsWereMet fails unless Close() was called, even
+// without ExpectClose declared, to enforce the proper teardown. The first
+// Close() is expected implicitly then, unless ExpectClose was declared,
+// other calls are matched against declared expectations.
+func RequireCloseOption(enabled bool) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		s.requireClose = enabled
+		return nil
+	}
+}
+
+// ReplicaOption makes the mock the read replica of the primary connection
+// or pool mock lagging behind it for the duration. Simple queries matching
+// no expectation read the in-memory tables of the primary as they were
+// committed lag ago, measured with the clock of the primary, so
+// read-your-writes fallbacks may be tested. Tables seeded on the primary
+// are replicated immediately. The replica is read-only, see ReadOnlyOption.
+func ReplicaOption(primary Expecter, lag time.Duration) func(*pgxmock) error {
+	return func(s *pgxmock) error {
+		var p *pgxmock
+		switch m := primary.(type) {
+		case *pgxmockConn:
+			p = &m.pgxmock
+		case *pgxmockPool:
+			p = &m.pgxmock
+		default:
+			return fmt.Errorf("primary must be the connection or the pool mock, got %T", primary)
+		}
+		if lag < 0 {
+			return fmt.Errorf("replica lag must not be negative, got %s", lag)
+		}
+		s.readOnly = true
+		s.store.replicate(p.store, p.clock, lag)
+		return nil
+	}
+}
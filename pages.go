@@ -0,0 +1,161 @@
+package pgxmock
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// WillReturnPages specifies the full dataset, from which every call of the
+// query gets the rows selected by the actual query and arguments, so methods
+// reading data page by page may be tested against one fixture. The dataset is
+// expected to be sorted the way the query sorts it. Rows are selected by:
+//   - keyset predicates comparing the dataset column, e.g. "id > $1";
+//   - OFFSET skipping rows;
+//   - LIMIT or FETCH FIRST limiting the number of rows.
+//
+// Values may be literals or placeholders.
+func (e *ExpectedQuery) WillReturnPages(rows *Rows) *ExpectedQuery {
+	e.WillReturnRows(rows)
+	e.pages = rows.Clone()
+	return e
+}
+
+var (
+	reLimit  = regexp.MustCompile(`(?i)\b(?:LIMIT|FETCH\s+(?:FIRST|NEXT))\s+(\$\d+|\d+)`)
+	reOffset = regexp.MustCompile(`(?i)\bOFFSET\s+(\$\d+|\d+)`)
+	reKeyset = regexp.MustCompile(`(?i)\b(\w+)\s*(>=|<=|>|<)\s*(\$\d+|-?\d+(?:\.\d+)?|'(?:[^']|'')*')`)
+	reNumber = regexp.MustCompile(`^-?\d+(?:\.\d+)?$`)
+)
+
+// page returns the copy of rows selected by the query, see WillReturnPages
+func (r *Rows) page(sql string, args []any) (*Rows, error) {
+	args = queryArgs(args)
+	columns := make(map[string]int, len(r.defs))
+	for i, def := range r.defs {
+		columns[strings.ToLower(def.Name)] = i
+	}
+	p := r.Clone()
+	for _, m := range reKeyset.FindAllStringSubmatch(sql, -1) {
+		col, ok := columns[strings.ToLower(m[1])]
+		if !ok {
+			continue
+		}
+		value, err := pageValue(m[3], args)
+		if err != nil {
+			return nil, err
+		}
+		selected := p.rows[:0]
+		for _, row := range p.rows {
+			c, ok := compareValues(row[col], value)
+			if !ok {
+				return nil, fmt.Errorf("cannot compare column %s value %v with %v", m[1], row[col], value)
+			}
+			if c > 0 && strings.HasPrefix(m[2], ">") || c < 0 && strings.HasPrefix(m[2], "<") || c == 0 && strings.HasSuffix(m[2], "=") {
+				selected = append(selected, row)
+			}
+		}
+		p.rows = selected
+	}
+	if m := reOffset.FindStringSubmatch(sql); m != nil {
+		offset, err := pageCount(m[1], args)
+		if err != nil {
+			return nil, err
+		}
+		p.rows = p.rows[min(offset, len(p.rows)):]
+	}
+	if m := reLimit.FindStringSubmatch(sql); m != nil {
+		limit, err := pageCount(m[1], args)
+		if err != nil {
+			return nil, err
+		}
+		p.rows = p.rows[:min(limit, len(p.rows))]
+	}
+	return p, nil
+}
+
+// pageValue returns the value of the literal or the placeholder argument
+func pageValue(s string, args []any) (any, error) {
+	switch {
+	case strings.HasPrefix(s, "$"):
+		n, _ := strconv.Atoi(s[1:])
+		if n < 1 || n > len(args) {
+			return nil, fmt.Errorf("no argument for placeholder %s", s)
+		}
+		return args[n-1], nil
+	case strings.HasPrefix(s, "'"):
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case reNumber.MatchString(s):
+		return strconv.ParseFloat(s, 64)
+	}
+	return nil, fmt.Errorf("unsupported value %s", s)
+}
+
+// pageCount returns the non-negative LIMIT or OFFSET value
+func pageCount(s string, args []any) (int, error) {
+	value, err := pageValue(s, args)
+	if err != nil {
+		return 0, err
+	}
+	if s, ok := value.(string); ok {
+		if value, err = strconv.ParseFloat(s, 64); err != nil {
+			return 0, err
+		}
+	}
+	n, ok := toFloat(value)
+	if !ok || n < 0 {
+		return 0, fmt.Errorf("invalid row count %v", value)
+	}
+	return int(n), nil
+}
+
+// compareValues compares numbers, strings and times
+func compareValues(a, b any) (int, bool) {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return cmp.Compare(x, y), ok
+	}
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return cmp.Compare(x, y), ok
+	case time.Time:
+		y, ok := b.(time.Time)
+		return x.Compare(y), ok
+	}
+	return 0, false
+}
+
+// toFloat converts any integer or float value to float64
+func toFloat(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// queryArgs returns the query arguments without leading
+// pgx.QueryExecMode and result formats options
+func queryArgs(args []any) []any {
+	for len(args) > 0 {
+		switch args[0].(type) {
+		case pgx.QueryExecMode, pgx.QueryResultFormats, pgx.QueryResultFormatsByOID:
+			args = args[1:]
+		default:
+			return args
+		}
+	}
+	return args
+}
@@ -0,0 +1,46 @@
+package pgxmock
+
+import (
+	"testing"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWillReturnPages(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	users := NewRows([]string{"id", "name"}).
+		AddRowsFunc(10, func(i int) []any { return []any{i + 1, string(rune('a' + i))} })
+
+	ids := func(sql string, args ...any) (ids []int) {
+		rows, err := mock.Query(ctx, sql, args...)
+		if !a.NoError(err) {
+			return nil
+		}
+		ids, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (id int, err error) {
+			err = row.Scan(&id, nil)
+			return
+		})
+		a.NoError(err)
+		return
+	}
+
+	mock.ExpectQuery("LIMIT").WithArgs(AnyArg(), AnyArg()).WillReturnPages(users).Times(4)
+	for offset, page := range [][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}, {10}} {
+		a.Equal(page, ids("SELECT id, name FROM users ORDER BY id LIMIT $1 OFFSET $2", 3, offset*3))
+	}
+
+	mock.ExpectQuery("FETCH").WithArgs(pgx.QueryExecModeExec, 8).WillReturnPages(users)
+	a.Equal([]int{9, 10}, ids("SELECT id, name FROM users WHERE id > $1 ORDER BY id FETCH FIRST 3 ROWS ONLY", pgx.QueryExecModeExec, 8))
+
+	mock.ExpectQuery("SELECT").WillReturnPages(users).Times(2)
+	a.Equal([]int{2, 3}, ids("SELECT id, name FROM users WHERE name >= 'b' AND name < 'd' LIMIT 5"))
+	a.Empty(ids("SELECT id, name FROM users OFFSET 100"))
+	a.NoError(mock.ExpectationsWereMet())
+
+	mock.ExpectQuery("SELECT").WillReturnPages(users)
+	_, err := mock.Query(ctx, "SELECT * FROM users LIMIT $1")
+	a.ErrorContains(err, "failed to select the page: no argument for placeholder $1")
+}
@@ -14,10 +14,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	pgx "github.com/jackc/pgx/v5"
 	pgconn "github.com/jackc/pgx/v5/pgconn"
+	pgtype "github.com/jackc/pgx/v5/pgtype"
 	pgxpool "github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -27,7 +34,8 @@ import (
 type Expecter interface {
 	// ExpectationsWereMet checks whether all queued expectations
 	// were met in order (unless MatchExpectationsInOrder set to false).
-	// If any of them was not met - an error is returned.
+	// If any of them was not met - an error is returned. Violations, open
+	// transactions and leaked rows are joined into the same error.
 	ExpectationsWereMet() error
 
 	// ExpectBatch expects pgx.Batch to be called. The *ExpectedBatch
@@ -51,6 +59,11 @@ type Expecter interface {
 	// the *ExpectedQuery allows to mock database response.
 	ExpectQuery(expectedSQL string) *ExpectedQuery
 
+	// ExpectQueryAnyOf expects Query() or QueryRow() to be called with any
+	// of expectedSQL queries, e.g. during refactoring or for feature-flagged
+	// query shapes. The *ExpectedQuery allows to mock database response.
+	ExpectQueryAnyOf(expectedSQL ...string) *ExpectedQuery
+
 	// ExpectExec expects Exec() to be called with expectedSQL query.
 	// the *ExpectedExec allows to mock database response
 	ExpectExec(expectedSQL string) *ExpectedExec
@@ -67,6 +80,19 @@ type Expecter interface {
 	// the *ExpectedCommit allows to mock database response
 	ExpectCommit() *ExpectedCommit
 
+	// ExpectTx expects a transaction to be started with Begin(), then
+	// all expectations declared inside f to be met by calls issued in
+	// this transaction, and finally the transaction to be committed.
+	// The *ExpectedCommit allows to mock
+	// database response
+	ExpectTx(f func(tx TxExpecter)) *ExpectedCommit
+
+	// ExpectTxRollingBackAfter expects a transaction to be started with Begin(),
+	// then Exec() to be called with expectedSQL query failing with err, and
+	// finally the transaction to be rolled back. The *ExpectedExec allows
+	// to specify arguments of the failing statement
+	ExpectTxRollingBackAfter(expectedSQL string, err error) *ExpectedExec
+
 	// ExpectReset expects pgxpool.Reset() to be called.
 	// The *ExpectedReset allows to mock database response
 	ExpectReset() *ExpectedReset
@@ -79,6 +105,11 @@ type Expecter interface {
 	// The *ExpectedPing allows to mock database response
 	ExpectPing() *ExpectedPing
 
+	// ExpectCancelRequest expects the query to be cancelled with
+	// CancelRequest() of the mock or of *pgconn.PgConn connected to
+	// the mockserver package server.
+	ExpectCancelRequest() *ExpectedCancelRequest
+
 	// ExpectCopyFrom expects pgx.CopyFrom to be called.
 	// The *ExpectCopyFrom allows to mock database response
 	ExpectCopyFrom(expectedTableName pgx.Identifier, expectedColumns []string) *ExpectedCopyFrom
@@ -93,9 +124,79 @@ type Expecter interface {
 	// This option may be turned on anytime during tests. As soon
 	// as it is switched to false, expectations will be matched
 	// in any order. Or otherwise if switched to true, any unmatched
-	// expectations will be expected in order
+	// expectations will be expected in order.
+	//
+	// When many expectations match the call in any order, the most specific
+	// one is chosen: exact arguments are preferred over matchers and AnyArg,
+	// then the expected SQL with more literal characters is preferred.
+	// Expectations with higher Priority are preferred over all others.
 	MatchExpectationsInOrder(bool)
 
+	// ForbidQuery forbids any SQL matching the pattern with the mock
+	// QueryMatcher, e.g. "DELETE FROM .*". The matching call fails at once
+	// and ExpectationsWereMet reports it even if the error was ignored.
+	ForbidQuery(pattern string)
+
+	// SeedTable fills the named in-memory table with rows. Simple queries,
+	// like SELECT columns FROM table WHERE column = $1 AND ... ORDER BY column
+	// LIMIT n OFFSET n, matching no expectation are answered from the table.
+	SeedTable(name string, rows *Rows)
+
+	// TableRows returns rows of the in-memory table. Simple INSERT, UPDATE
+	// and DELETE statements matching no expectation are applied to the
+	// seeded tables, so the resulting state may be asserted. Changes made
+	// in the transaction are seen by it only, they are applied to the
+	// committed rows on Commit and discarded on Rollback or failed Commit.
+	TableRows(name string) [][]any
+
+	// CallLogJSON returns every call made to the mock in JSON format
+	// including the method, SQL, arguments, matched expectation,
+	// duration and error of the call.
+	CallLogJSON() ([]byte, error)
+
+	// TimingReport returns the timing summary of all calls made to the mock,
+	// including durations measured with the mock clock and simulated delays.
+	TimingReport() TimingReport
+
+	// WriteJUnitReport writes the JUnit XML test suite with every expectation
+	// as a test case, including the matched calls and their total duration,
+	// followed by calls matching no expectation and violations, so the mock
+	// coverage may be surfaced in CI dashboards.
+	WriteJUnitReport(w io.Writer, suite string) error
+
+	// WriteHTMLReport writes the same outcomes as WriteJUnitReport
+	// as the HTML page with the table titled with title.
+	WriteHTMLReport(w io.Writer, title string) error
+
+	// InState returns the expecter creating expectations which may be
+	// matched only when the mock is in the named state. The mock switches
+	// states when expectations declared with TransitionsTo are matched.
+	// The initial state of the mock is an empty string.
+	InState(state string) TxExpecter
+
+	// Group returns the named group creating expectations, which may be
+	// verified independently with the WereMet method of the group.
+	Group(name string) *ExpectationGroup
+
+	// PendingExpectations returns the information about all expectations
+	// which were not fulfilled yet, in the order they were declared.
+	PendingExpectations() []ExpectationInfo
+
+	// Summary returns the readable report of fulfilled and pending
+	// expectations with the numbers of calls made and planned,
+	// e.g. to be printed on failure or by debugging helpers.
+	Summary() string
+
+	// Use adds middlewares wrapping every call to the mock, so
+	// cross-cutting behavior may be added once for all calls.
+	Use(middlewares ...Middleware)
+
+	// SetTestReporter sets the test, which is failed with t.Errorf at the
+	// moment a call is unexpected or mismatched, in addition to the error
+	// returned, since the code under test may mask errors. Calls the mock
+	// still answers, e.g. from the SeedTable tables, are not reported.
+	SetTestReporter(t TestingT)
+
 	// NewRows allows Rows to be created from a []string slice.
 	NewRows(columns []string) *Rows
 
@@ -107,6 +208,28 @@ type Expecter interface {
 	NewColumn(name string) *pgconn.FieldDescription
 }
 
+// TxExpecter interface serves to create expectations
+// inside the transaction scope declared with ExpectTx.
+type TxExpecter interface {
+	// ExpectBatch expects pgx.Batch to be called within the transaction.
+	ExpectBatch() *ExpectedBatch
+
+	// ExpectCopyFrom expects pgx.CopyFrom to be called within the transaction.
+	ExpectCopyFrom(expectedTableName pgx.Identifier, expectedColumns []string) *ExpectedCopyFrom
+
+	// ExpectExec expects Exec() to be called within the transaction.
+	ExpectExec(expectedSQL string) *ExpectedExec
+
+	// ExpectQuery expects Query() or QueryRow() to be called within the transaction.
+	ExpectQuery(expectedSQL string) *ExpectedQuery
+
+	// ExpectPrepare expects Prepare() to be called within the transaction.
+	ExpectPrepare(expectedStmtName, expectedSQL string) *ExpectedPrepare
+
+	// ExpectTx expects a nested transaction (savepoint) to be started and committed.
+	ExpectTx(f func(tx TxExpecter)) *ExpectedCommit
+}
+
 // PgxCommonIface represents common interface for all pgx connection interfaces:
 // pgxpool.Pool, pgx.Conn and pgx.Tx
 type PgxCommonIface interface {
@@ -114,6 +237,10 @@ type PgxCommonIface interface {
 	pgx.Tx
 	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
 	Ping(context.Context) error
+	// CancelRequest simulates *pgconn.PgConn.CancelRequest, which cannot be
+	// mocked, since PgConn is a struct. Code cancelling queries may depend on
+	// the interface with this method or use the mockserver package instead.
+	CancelRequest(ctx context.Context) error
 }
 
 // PgxConnIface represents pgx.Conn specific interface
@@ -124,6 +251,12 @@ type PgxConnIface interface {
 	DeallocateAll(ctx context.Context) error
 	Config() *pgx.ConnConfig
 	PgConn() *pgconn.PgConn
+	// Scope returns the isolated child mock for the subtest, whose
+	// expectations are verified on the subtest cleanup
+	Scope(t TestingT) PgxConnIface
+	// CloneWithExpectations returns the independent mock
+	// preseeded with the same unfulfilled expectations
+	CloneWithExpectations() PgxConnIface
 }
 
 // PgxPoolIface represents pgxpool.Pool specific interface
@@ -133,16 +266,74 @@ type PgxPoolIface interface {
 	AcquireAllIdle(ctx context.Context) []*pgxpool.Conn
 	AcquireFunc(ctx context.Context, f func(*pgxpool.Conn) error) error
 	AsConn() PgxConnIface
+	// AcquireConn returns the mocked connection, blocking while
+	// the pool size limited with MaxConnsOption is exhausted
+	AcquireConn(ctx context.Context) (PgxPooledConnIface, error)
 	Close()
 	Stat() *pgxpool.Stat
 	Reset()
 	Config() *pgxpool.Config
+	// Scope returns the isolated child mock for the subtest, whose
+	// expectations are verified on the subtest cleanup
+	Scope(t TestingT) PgxPoolIface
+	// CloneWithExpectations returns the independent mock
+	// preseeded with the same unfulfilled expectations
+	CloneWithExpectations() PgxPoolIface
+}
+
+// PgxPooledConnIface is the mocked connection acquired from the pool
+type PgxPooledConnIface interface {
+	PgxConnIface
+	// Release returns the connection to the pool
+	Release()
 }
 
 type pgxmock struct {
-	ordered      bool
-	queryMatcher QueryMatcher
-	expectations []expectation
+	ordered             bool
+	queryMatcher        QueryMatcher
+	clock               Clock
+	middlewares         []Middleware
+	callLog             *callLog
+	csvParser           func(string) interface{}
+	types               []*pgtype.Type
+	state               string // set by TransitionsTo, guarded by mu
+	callStacks          bool
+	rowsMustBeClosed    bool
+	rowsMustBeFullyRead bool
+	strictConn          bool
+	tracer              pgx.QueryTracer
+	onMatched           func(ExpectationInfo, *Call)
+	encodableArgs       bool
+	rewriterConn        *pgx.Conn
+	forbidden           []string // patterns set by ForbidQuery
+	auditRules          []AuditRule
+	defaultDelay        time.Duration
+	orderByType         bool
+	verifyPoolClose     bool
+	detectRowsLeaks     bool
+	issuedRows          []issuedRows // rows tracked by the leak detector
+	verified            bool         // ExpectationsWereMet was called, guarded by mu
+	driverBytes         bool
+	statements          map[string]bool // prepared (true) and deallocated (false) statement names, guarded by mu
+	violations          []string        // forbidden calls and audit rules violations
+	mu                  *sync.Mutex     // guards state changed by concurrent calls, e.g. violations
+	createdAt           time.Time       // the origin of deadlines set with Within
+	store               *store          // in-memory tables seeded with SeedTable
+	readOnly            bool
+	txMu                *sync.Mutex // guards open transactions, which may be begun concurrently
+	verifyTx            bool
+	txs                 []*pgxmockTx // open transactions in the order they were begun
+	requireClose        bool
+	reporter            TestingT // fails the test on unexpected calls, see SetTestReporter
+	closed              bool     // Close was called, guarded by mu, see RequireCloseOption and VerifyPoolCloseOption
+	txPooling           bool
+	timeScale           float64
+	conns               chan struct{} // slots of the pool limited with MaxConnsOption
+	poolHooks           PoolHooks
+	errorFormatter      func(error) string
+	openRows            *rowSets         // the last rows returned by the strict connection, guarded by mu
+	expectations        *expectationList // shared with scopes, see Scope
+	scope               int              // key of expectations declared on the scope, zero for the mock
 }
 
 func (c *pgxmock) AcquireAllIdle(_ context.Context) []*pgxpool.Conn {
@@ -156,28 +347,148 @@ func (c *pgxmock) AcquireFunc(_ context.Context, _ func(*pgxpool.Conn) error) er
 // region Expectations
 func (c *pgxmock) ExpectBatch() *ExpectedBatch {
 	e := &ExpectedBatch{mock: c}
-	c.expectations = append(c.expectations, e)
+	c.addExpectation(e)
 	return e
 }
 
 func (c *pgxmock) ExpectClose() *ExpectedClose {
 	e := &ExpectedClose{}
-	c.expectations = append(c.expectations, e)
+	c.addExpectation(e)
 	return e
 }
 
+// addExpectation queues the expectation remembering where it was declared
+func (c *pgxmock) addExpectation(e expectation) {
+	e.setDeclaredAt(declarationSite())
+	e.setDelay(c.defaultDelay)
+	c.expectations.add(c.scope, e)
+}
+
+func (c *pgxmock) SetTestReporter(t TestingT) {
+	c.reporter = t
+}
+
 func (c *pgxmock) MatchExpectationsInOrder(b bool) {
 	c.ordered = b
 }
 
+// inOrder reports whether expectations are matched in order
+func (c *pgxmock) inOrder() bool {
+	return c.ordered
+}
+
+func (c *pgxmock) ForbidQuery(pattern string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.forbidden = append(c.forbidden, pattern)
+}
+
+// forbiddenPatterns returns the copy of patterns set by ForbidQuery
+func (c *pgxmock) forbiddenPatterns() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.forbidden)
+}
+
+// addViolation remembers the violation reported by ExpectationsWereMet
+func (c *pgxmock) addViolation(msg string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.violations = append(c.violations, msg)
+}
+
+// currentState returns the state the mock was switched to by TransitionsTo
+func (c *pgxmock) currentState() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// setState switches the mock to the state
+func (c *pgxmock) setState(state string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.state = state
+}
+
+// violationList returns the copy of violations remembered so far
+func (c *pgxmock) violationList() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.violations)
+}
+
+// forbiddenQuery returns an error if the SQL of the call matches
+// any of the patterns set by ForbidQuery
+func (c *pgxmock) forbiddenQuery(call *Call) error {
+	if call.SQL == "" {
+		return nil
+	}
+	for _, pattern := range c.forbiddenPatterns() {
+		if c.queryMatcher.Match(pattern, call.SQL) == nil {
+			msg := fmt.Sprintf("call to %s with query '%s' is forbidden by pattern '%s'", call.Method, call.SQL, pattern)
+			c.addViolation(msg)
+			return errors.New(msg)
+		}
+	}
+	return nil
+}
+
+// checkDeadline remembers the violation if the expectation
+// was fulfilled later than required by Within
+func (c *pgxmock) checkDeadline(e expectation) {
+	e.Lock()
+	within, at := e.deadline(), e.fulfilledAt()
+	e.Unlock()
+	if within <= 0 || at.IsZero() {
+		return
+	}
+	since := c.createdAt
+	expectations := c.declared()
+	for i, next := range expectations {
+		if next != e {
+			continue
+		}
+		if i > 0 {
+			prev := expectations[i-1]
+			prev.Lock()
+			if t := prev.fulfilledAt(); !t.IsZero() {
+				since = t
+			}
+			prev.Unlock()
+		}
+		break
+	}
+	if elapsed := at.Sub(since); elapsed > within {
+		c.addViolation(fmt.Sprintf("expectation%s was fulfilled in %v, but expected within %v:\n%s", declaredAt(e), elapsed, within, e))
+	}
+}
+
 func (c *pgxmock) ExpectationsWereMet() error {
-	for _, e := range c.expectations {
+	c.mu.Lock()
+	c.verified = true
+	c.mu.Unlock()
+	var violationsErr, closeErr error
+	if violations := c.violationList(); len(violations) > 0 {
+		violationsErr = fmt.Errorf("there were %d violations:\n\t- %s", len(violations), strings.Join(violations, "\n\t- "))
+	}
+	if (c.requireClose || c.verifyPoolClose) && !c.wasClosed() {
+		closeErr = errors.New("Close() was never called")
+	}
+	// report all problems at once, so fixing one does not reveal another
+	err := errors.Join(violationsErr, c.expectationsWereMet(c.declared()), c.openTransactions(), closeErr, c.rowsLeaks())
+	return c.formatError(markError(ErrUnmetExpectations, err))
+}
+
+func (c *pgxmock) expectationsWereMet(expectations []expectation) error {
+	var unclosed, unread []string
+	for _, e := range expectations {
 		e.Lock()
 		fulfilled := e.fulfilled() || !e.required()
 		e.Unlock()
 
 		if !fulfilled {
-			return fmt.Errorf("there is a remaining expectation which was not matched: %s", e)
+			return fmt.Errorf("there is a remaining expectation%s which was not matched: %s", declaredAt(e), e)
 		}
 
 		// must check whether all expected queried rows are closed
@@ -185,83 +496,145 @@ func (c *pgxmock) ExpectationsWereMet() error {
 			if query.rowsMustBeClosed && !query.rowsWereClosed {
 				return fmt.Errorf("expected query rows to be closed, but it was not: %s", query)
 			}
+			if c.rowsMustBeClosed && query.triggered > 0 && query.err == nil && !query.rowsWereClosed {
+				unclosed = append(unclosed, fmt.Sprintf("\t- '%s'%s", query.expectSQL, declaredAt(query)))
+			}
+			if (c.rowsMustBeFullyRead || query.rowsMustBeFullyRead) && query.triggered > 0 && query.err == nil {
+				if rs, ok := query.rows.(*rowSets); ok && !rs.fullyRead() {
+					unread = append(unread, fmt.Sprintf("\t- '%s'%s", query.expectSQL, declaredAt(query)))
+				}
+			}
 		}
 	}
+	if len(unclosed) > 0 {
+		return fmt.Errorf("expected all query rows to be closed, but rows of %d queries were not:\n%s",
+			len(unclosed), strings.Join(unclosed, "\n"))
+	}
+	if len(unread) > 0 {
+		return fmt.Errorf("expected query rows to be fully read, but rows of %d queries were not:\n%s",
+			len(unread), strings.Join(unread, "\n"))
+	}
 	return nil
 }
 
 func (c *pgxmock) ExpectQuery(expectedSQL string) *ExpectedQuery {
 	e := &ExpectedQuery{}
 	e.expectSQL = expectedSQL
-	c.expectations = append(c.expectations, e)
+	e.compile(c.queryMatcher)
+	c.addExpectation(e)
+	return e
+}
+
+func (c *pgxmock) ExpectQueryAnyOf(expectedSQL ...string) *ExpectedQuery {
+	e := &ExpectedQuery{}
+	if len(expectedSQL) > 0 {
+		e.expectSQL = expectedSQL[0]
+	}
+	e.anyOfSQL = expectedSQL
+	e.compile(c.queryMatcher)
+	c.addExpectation(e)
 	return e
 }
 
 func (c *pgxmock) ExpectCommit() *ExpectedCommit {
 	e := &ExpectedCommit{}
-	c.expectations = append(c.expectations, e)
+	c.addExpectation(e)
+	return e
+}
+
+// ExpectTx wraps expectations declared by f into ExpectBegin and ExpectCommit.
+// The expectations declared by f and the commit match only the calls issued
+// in the begun transaction, the same calls issued on the mock itself, e.g.
+// on the pool, are not matched.
+func (c *pgxmock) ExpectTx(f func(tx TxExpecter)) *ExpectedCommit {
+	begin := c.ExpectBegin()
+	if f != nil {
+		f(&scopedExpecter{mock: c, apply: func(e expectation) {
+			if e.requiredTx() == nil { // nested transactions keep their own
+				e.setRequiredTx(begin)
+			}
+		}})
+	}
+	commit := c.ExpectCommit()
+	commit.setRequiredTx(begin)
+	return commit
+}
+
+// ExpectTxRollingBackAfter declares begin, the failing Exec() and the rollback in one call.
+func (c *pgxmock) ExpectTxRollingBackAfter(expectedSQL string, err error) *ExpectedExec {
+	c.ExpectBegin()
+	e := c.ExpectExec(expectedSQL)
+	e.WillReturnError(err)
+	c.ExpectRollback()
 	return e
 }
 
 func (c *pgxmock) ExpectRollback() *ExpectedRollback {
 	e := &ExpectedRollback{}
-	c.expectations = append(c.expectations, e)
+	c.addExpectation(e)
 	return e
 }
 
 func (c *pgxmock) ExpectBegin() *ExpectedBegin {
-	e := &ExpectedBegin{}
-	c.expectations = append(c.expectations, e)
+	e := &ExpectedBegin{mock: c}
+	c.addExpectation(e)
 	return e
 }
 
 func (c *pgxmock) ExpectBeginTx(txOptions pgx.TxOptions) *ExpectedBegin {
-	e := &ExpectedBegin{opts: txOptions}
-	c.expectations = append(c.expectations, e)
+	e := &ExpectedBegin{mock: c, opts: txOptions}
+	c.addExpectation(e)
 	return e
 }
 
 func (c *pgxmock) ExpectExec(expectedSQL string) *ExpectedExec {
 	e := &ExpectedExec{}
 	e.expectSQL = expectedSQL
-	c.expectations = append(c.expectations, e)
+	e.compile(c.queryMatcher)
+	c.addExpectation(e)
 	return e
 }
 
 func (c *pgxmock) ExpectCopyFrom(expectedTableName pgx.Identifier, expectedColumns []string) *ExpectedCopyFrom {
 	e := &ExpectedCopyFrom{expectedTableName: expectedTableName, expectedColumns: expectedColumns}
-	c.expectations = append(c.expectations, e)
+	c.addExpectation(e)
 	return e
 }
 
 // ExpectReset expects Reset to be called.
 func (c *pgxmock) ExpectReset() *ExpectedReset {
 	e := &ExpectedReset{}
-	c.expectations = append(c.expectations, e)
+	c.addExpectation(e)
 	return e
 }
 
 func (c *pgxmock) ExpectPing() *ExpectedPing {
 	e := &ExpectedPing{}
-	c.expectations = append(c.expectations, e)
+	c.addExpectation(e)
+	return e
+}
+
+func (c *pgxmock) ExpectCancelRequest() *ExpectedCancelRequest {
+	e := &ExpectedCancelRequest{}
+	c.addExpectation(e)
 	return e
 }
 
 func (c *pgxmock) ExpectPrepare(expectedStmtName, expectedSQL string) *ExpectedPrepare {
-	e := &ExpectedPrepare{expectSQL: expectedSQL, expectStmtName: expectedStmtName}
-	c.expectations = append(c.expectations, e)
+	e := &ExpectedPrepare{expectSQL: expectedSQL, stmtNameExpectation: stmtNameExpectation{expectStmtName: expectedStmtName}}
+	c.addExpectation(e)
 	return e
 }
 
 func (c *pgxmock) ExpectDeallocate(expectedStmtName string) *ExpectedDeallocate {
-	e := &ExpectedDeallocate{expectStmtName: expectedStmtName}
-	c.expectations = append(c.expectations, e)
+	e := &ExpectedDeallocate{stmtNameExpectation: stmtNameExpectation{expectStmtName: expectedStmtName}}
+	c.addExpectation(e)
 	return e
 }
 
 func (c *pgxmock) ExpectDeallocateAll() *ExpectedDeallocate {
 	e := &ExpectedDeallocate{expectAll: true}
-	c.expectations = append(c.expectations, e)
+	c.addExpectation(e)
 	return e
 }
 
@@ -272,6 +645,8 @@ func (c *pgxmock) ExpectDeallocateAll() *ExpectedDeallocate {
 // to be used as sql driver.Rows.
 func (c *pgxmock) NewRows(columns []string) *Rows {
 	r := NewRows(columns)
+	r.csvParser = c.csvParser
+	r.types = c.types
 	return r
 }
 
@@ -286,6 +661,8 @@ func (c *pgxmock) PgConn() *pgconn.PgConn {
 // sql driver.Value slice with a definition of sql metadata
 func (c *pgxmock) NewRowsWithColumnDefinition(columns ...pgconn.FieldDescription) *Rows {
 	r := NewRowsWithColumnDefinition(columns...)
+	r.csvParser = c.csvParser
+	r.types = c.types
 	return r
 }
 
@@ -295,8 +672,37 @@ func (c *pgxmock) NewColumn(name string) *pgconn.FieldDescription {
 	return &pgconn.FieldDescription{Name: name}
 }
 
+// newTypeMap returns the pgx type map with the mock types registered
+func (c *pgxmock) newTypeMap() *pgtype.Map {
+	return (&Rows{types: c.types}).newTypeMap()
+}
+
+// argsEncodable checks whether every argument could be encoded by pgx.
+// Leading query options, e.g. pgx.QueryExecMode, are skipped and
+// pgx.QueryRewriter arguments are rewritten the same way as for matching.
+func (c *pgxmock) argsEncodable(sql string, args []any) error {
+	m := c.newTypeMap()
+	_, args, err := rewriteQuery(c.rewriterConn, sql, args)
+	if err != nil {
+		return fmt.Errorf("rewrite query failed: %w", err)
+	}
+	for i, arg := range queryArgs(args) {
+		if _, err := m.Encode(0, pgtype.TextFormatCode, arg, nil); err != nil {
+			return fmt.Errorf("failed to encode args[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
 // open a mock database driver connection
 func (c *pgxmock) open(options []func(*pgxmock) error) error {
+	c.clock = realClock{}
+	c.callLog = &callLog{}
+	c.expectations = &expectationList{}
+	c.store = &store{}
+	c.txMu = &sync.Mutex{}
+	c.mu = &sync.Mutex{}
+
 	for _, option := range options {
 		err := option(c)
 		if err != nil {
@@ -307,6 +713,10 @@ func (c *pgxmock) open(options []func(*pgxmock) error) error {
 	if c.queryMatcher == nil {
 		c.queryMatcher = QueryMatcherRegexp
 	}
+	if c.timeScale > 0 && c.timeScale != 1 {
+		c.clock = scaledClock{Clock: c.clock, scale: c.timeScale}
+	}
+	c.createdAt = c.clock.Now()
 
 	return nil
 }
@@ -315,61 +725,129 @@ func (c *pgxmock) open(options []func(*pgxmock) error) error {
 // be called depending on the circumstances, but if it is called
 // there must be an *ExpectedClose expectation satisfied.
 func (c *pgxmock) Close(ctx context.Context) error {
-	ex, err := findExpectation[*ExpectedClose](c, "Close()")
+	return c.handle(ctx, &Call{Method: "Close()"}, c.close)
+}
+
+func (c *pgxmock) close(ctx context.Context, call *Call) error {
+	ex, err := findExpectation[*ExpectedClose](ctx, c, call)
+	if c.requireClose && c.markClosed() && err != nil && !c.closeExpected() {
+		return nil // the first Close is expected implicitly, if not declared
+	}
 	if err != nil {
 		return err
 	}
-	return ex.waitForDelay(ctx)
+	return ex.waitForDelay(ctx, c.clock, call.number)
+}
+
+// markClosed remembers that Close was called
+// and reports whether it was called for the first time
+func (c *pgxmock) markClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	first := !c.closed
+	c.closed = true
+	return first
+}
+
+// wasVerified reports whether ExpectationsWereMet was called
+func (c *pgxmock) wasVerified() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.verified
+}
+
+// wasClosed reports whether Close was called
+func (c *pgxmock) wasClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// closeExpected reports whether ExpectClose was declared
+func (c *pgxmock) closeExpected() bool {
+	for _, e := range c.declared() {
+		if _, ok := e.(*ExpectedClose); ok {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *pgxmock) Conn() *pgx.Conn {
 	panic("Conn() is not available in pgxmock")
 }
 
-func (c *pgxmock) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, _ pgx.CopyFromSource) (int64, error) {
-	ex, err := findExpectationFunc[*ExpectedCopyFrom](c, "BeginTx()", func(copyExp *ExpectedCopyFrom) error {
-		if !reflect.DeepEqual(copyExp.expectedTableName, tableName) {
-			return fmt.Errorf("CopyFrom: table name '%s' was not expected, expected table name is '%s'", tableName, copyExp.expectedTableName)
+func (c *pgxmock) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return c.copyFrom(ctx, tableName, columnNames, rowSrc, nil)
+}
+
+// copyFrom copies rows in the transaction tx or on the mock if tx is nil
+func (c *pgxmock) copyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource, tx *pgxmockTx) (int64, error) {
+	ctx = c.traceCopyFromStart(ctx, tableName, columnNames)
+	var rowsAffected int64 = -1
+	err := c.handle(ctx, &Call{Method: "CopyFrom()", tx: tx}, func(ctx context.Context, call *Call) error {
+		if err := c.writeAllowed(call.tx, "COPY FROM"); err != nil {
+			return err
+		}
+		ex, err := findExpectationFunc[*ExpectedCopyFrom](ctx, c, call, func(copyExp *ExpectedCopyFrom) error {
+			if !reflect.DeepEqual(copyExp.expectedTableName, tableName) {
+				return fmt.Errorf("CopyFrom: table name '%s' was not expected, expected table name is '%s'", tableName, copyExp.expectedTableName)
+			}
+			if !reflect.DeepEqual(copyExp.expectedColumns, columnNames) {
+				return fmt.Errorf("CopyFrom: column names '%v' were not expected, expected column names are '%v'", columnNames, copyExp.expectedColumns)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
-		if !reflect.DeepEqual(copyExp.expectedColumns, columnNames) {
-			return fmt.Errorf("CopyFrom: column names '%v' were not expected, expected column names are '%v'", columnNames, copyExp.expectedColumns)
+		rowsAffected = ex.rowsAffected
+		if err := ex.validateRows(c.newTypeMap(), rowSrc); err != nil {
+			return err
 		}
-		return nil
+		return ex.waitForDelay(ctx, c.clock, call.number)
 	})
-	if err != nil {
-		return -1, err
-	}
-	return ex.rowsAffected, ex.waitForDelay(ctx)
+	c.traceCopyFromEnd(ctx, rowsAffected, err)
+	return rowsAffected, err
 }
 
 func (c *pgxmock) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
-	ex, err := findExpectationFunc[*ExpectedBatch](c, "Batch()", func(batchExp *ExpectedBatch) error {
-		if len(batchExp.expectedQueries) != len(b.QueuedQueries) {
-			return fmt.Errorf("SendBatch: number of queries in batch '%d' was not expected, expected number of queries is '%d'",
-				len(b.QueuedQueries), len(batchExp.expectedQueries))
-		}
-		if !c.ordered { // postpone the check of every query until/if it is called
-			return nil
-		}
-		for i, query := range b.QueuedQueries {
-			if err := c.queryMatcher.Match(batchExp.expectedQueries[i].expectSQL, query.SQL); err != nil {
-				return err
+	return c.sendBatch(ctx, b, nil)
+}
+
+// sendBatch sends the batch in the transaction tx or on the mock if tx is nil
+func (c *pgxmock) sendBatch(ctx context.Context, b *pgx.Batch, tx *pgxmockTx) pgx.BatchResults {
+	ctx = c.traceBatchStart(ctx, b)
+	br := &batchResults{mock: c, batch: b, ctx: ctx, tx: tx}
+	br.err = c.handle(ctx, &Call{Method: "SendBatch()", tx: tx}, func(ctx context.Context, call *Call) error {
+		ex, err := findExpectationFunc[*ExpectedBatch](ctx, c, call, func(batchExp *ExpectedBatch) error {
+			if len(batchExp.expectedQueries) != len(b.QueuedQueries) {
+				return fmt.Errorf("SendBatch: number of queries in batch '%d' was not expected, expected number of queries is '%d'",
+					len(b.QueuedQueries), len(batchExp.expectedQueries))
 			}
-			if rewrittenSQL, err := batchExp.expectedQueries[i].argsMatches(query.SQL, query.Arguments); err != nil {
-				return err
-			} else if rewrittenSQL != "" && batchExp.expectedQueries[i].expectRewrittenSQL != "" {
-				if err := c.queryMatcher.Match(batchExp.expectedQueries[i].expectRewrittenSQL, rewrittenSQL); err != nil {
+			if !c.ordered { // postpone the check of every query until/if it is called
+				return nil
+			}
+			for i, query := range b.QueuedQueries {
+				if err := batchExp.expectedQueries[i].sqlMatches(c.queryMatcher, query.SQL); err != nil {
 					return err
 				}
+				if rewrittenSQL, err := batchExp.expectedQueries[i].argsMatches(c.rewriterConn, query.SQL, query.Arguments); err != nil {
+					return err
+				} else if rewrittenSQL != "" && batchExp.expectedQueries[i].expectRewrittenSQL != "" {
+					if err := c.queryMatcher.Match(batchExp.expectedQueries[i].expectRewrittenSQL, rewrittenSQL); err != nil {
+						return sqlMismatch(batchExp.expectedQueries[i].expectRewrittenSQL, rewrittenSQL, err)
+					}
+				}
 			}
+			return nil
+		})
+		br.expectedBatch = ex
+		if err != nil {
+			return err
 		}
-		return nil
+		return ex.waitForDelay(ctx, c.clock, call.number)
 	})
-	br := &batchResults{mock: c, batch: b, expectedBatch: ex, err: err}
-	if err != nil {
-		return br
-	}
-	br.err = ex.waitForDelay(ctx)
 	return br
 }
 
@@ -382,107 +860,386 @@ func (c *pgxmock) Begin(ctx context.Context) (pgx.Tx, error) {
 }
 
 func (c *pgxmock) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
-	ex, err := findExpectationFunc[*ExpectedBegin](c, "BeginTx()", func(beginExp *ExpectedBegin) error {
-		if beginExp.opts != txOptions {
-			return fmt.Errorf("BeginTx: call with transaction options '%v' was not expected: %s", txOptions, beginExp)
+	return c.beginIn(ctx, txOptions, nil)
+}
+
+// beginIn begins the transaction, or the savepoint if parent is not nil
+func (c *pgxmock) beginIn(ctx context.Context, txOptions pgx.TxOptions, parent *pgxmockTx) (pgx.Tx, error) {
+	var begin *ExpectedBegin
+	err := c.handle(ctx, &Call{Method: "BeginTx()", tx: parent}, func(ctx context.Context, call *Call) error {
+		ex, err := findExpectationFunc[*ExpectedBegin](ctx, c, call, func(beginExp *ExpectedBegin) error {
+			if beginExp.opts != txOptions {
+				return lazyErrorf("BeginTx: call with transaction options '%v' was not expected: %s", txOptions, beginExp)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
 		}
-		return nil
+		begin = ex
+		return ex.waitForDelay(ctx, c.clock, call.number)
 	})
 	if err != nil {
 		return nil, err
 	}
-	if err = ex.waitForDelay(ctx); err != nil {
-		return nil, err
-	}
-	return c, nil
+	tx := &pgxmockTx{pgxmock: c, begin: begin, parent: parent, prepared: make(map[string]bool)}
+	c.store.begin(tx)
+	c.beginTx(tx, txOptions.AccessMode == pgx.ReadOnly)
+	return tx, nil
 }
 
 func (c *pgxmock) Prepare(ctx context.Context, name, query string) (*pgconn.StatementDescription, error) {
-	ex, err := findExpectationFunc[*ExpectedPrepare](c, "Prepare()", func(prepareExp *ExpectedPrepare) error {
-		if err := c.queryMatcher.Match(prepareExp.expectSQL, query); err != nil {
+	return c.prepare(ctx, nil, name, query)
+}
+
+// prepare prepares the statement in the transaction tx or on the mock if tx is nil
+func (c *pgxmock) prepare(ctx context.Context, tx *pgxmockTx, name, query string) (*pgconn.StatementDescription, error) {
+	ctx = c.tracePrepareStart(ctx, name, query)
+	call := &Call{Method: "Prepare()", SQL: query, tx: tx}
+	err := c.handle(ctx, call, func(ctx context.Context, call *Call) error {
+		ex, err := findExpectationFunc[*ExpectedPrepare](ctx, c, call, func(prepareExp *ExpectedPrepare) error {
+			if err := c.queryMatcher.Match(prepareExp.expectSQL, call.SQL); err != nil {
+				return sqlMismatch(prepareExp.expectSQL, call.SQL, err)
+			}
+			if err := prepareExp.stmtNameMatches("Prepare", name); err != nil {
+				return err
+			}
+			return nil
+		})
+		if err != nil {
 			return err
 		}
-		if prepareExp.expectStmtName != name {
-			return fmt.Errorf("Prepare: prepared statement name '%s' was not expected, expected name is '%s'", name, prepareExp.expectStmtName)
+		if err = ex.waitForDelay(ctx, c.clock, call.number); err == nil && name != "" {
+			c.setStatement(name, true)
+			if c.txPooling && call.tx != nil {
+				call.tx.keepStatement(name)
+			}
 		}
-		return nil
+		return err
 	})
+	c.tracePrepareEnd(ctx, err)
 	if err != nil {
 		return nil, err
 	}
-	if err = ex.waitForDelay(ctx); err != nil {
-		return nil, err
-	}
-	return &pgconn.StatementDescription{Name: name, SQL: query}, nil
+	return &pgconn.StatementDescription{Name: name, SQL: call.SQL}, nil
 }
 
 func (c *pgxmock) Deallocate(ctx context.Context, name string) error {
-	ex, err := findExpectationFunc[*ExpectedDeallocate](c, "Deallocate()", func(deallocateExp *ExpectedDeallocate) error {
-		if deallocateExp.expectAll {
-			return fmt.Errorf("Deallocate: all prepared statements were expected to be deallocated, instead only '%s' specified", name)
+	return c.handle(ctx, &Call{Method: "Deallocate()"}, func(ctx context.Context, call *Call) error {
+		ex, err := findExpectationFunc[*ExpectedDeallocate](ctx, c, call, func(deallocateExp *ExpectedDeallocate) error {
+			if deallocateExp.expectAll {
+				return fmt.Errorf("Deallocate: all prepared statements were expected to be deallocated, instead only '%s' specified", name)
+			}
+			return deallocateExp.stmtNameMatches("Deallocate", name)
+		})
+		if err != nil {
+			return err
 		}
-		if deallocateExp.expectStmtName != name {
-			return fmt.Errorf("Deallocate: prepared statement name '%s' was not expected, expected name is '%s'", name, deallocateExp.expectStmtName)
+		if err = ex.waitForDelay(ctx, c.clock, call.number); err == nil {
+			c.setStatement(name, false)
 		}
-		return nil
-	})
-	if err != nil {
 		return err
-	}
-	return ex.waitForDelay(ctx)
+	})
 }
 
 func (c *pgxmock) DeallocateAll(ctx context.Context) error {
-	ex, err := findExpectationFunc[*ExpectedDeallocate](c, "DeallocateAll()", func(deallocateExp *ExpectedDeallocate) error {
-		if !deallocateExp.expectAll {
-			return fmt.Errorf("Deallocate: deallocate all prepared statements was not expected, expected name is '%s'", deallocateExp.expectStmtName)
+	return c.handle(ctx, &Call{Method: "DeallocateAll()"}, func(ctx context.Context, call *Call) error {
+		ex, err := findExpectationFunc[*ExpectedDeallocate](ctx, c, call, func(deallocateExp *ExpectedDeallocate) error {
+			if !deallocateExp.expectAll {
+				return fmt.Errorf("Deallocate: deallocate all prepared statements was not expected, expected name is %s", deallocateExp.stmtNameString())
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err = ex.waitForDelay(ctx, c.clock, call.number); err == nil {
+			c.deallocateStatements()
 		}
-		return nil
-	})
-	if err != nil {
 		return err
+	})
+}
+
+// setStatement registers the statement name as prepared or deallocated
+func (c *pgxmock) setStatement(name string, prepared bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.statements == nil {
+		c.statements = make(map[string]bool)
+	}
+	c.statements[name] = prepared
+}
+
+// deallocateStatements registers all prepared statements as deallocated
+func (c *pgxmock) deallocateStatements() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name := range c.statements {
+		c.statements[name] = false
 	}
-	return ex.waitForDelay(ctx)
+}
+
+// switchServerConn simulates the transaction pooling, e.g. by PgBouncer,
+// where every call issued outside of the open transaction may be executed
+// on another server connection, which knows no statements prepared before
+func (c *pgxmock) switchServerConn(call *Call) {
+	if !c.txPooling {
+		return
+	}
+	if !c.inOpenTx(call) {
+		c.deallocateStatements()
+	}
+}
+
+// statementExists returns an error if the SQL is the name of the
+// deallocated statement, e.g. by DeallocateAll, the same way as
+// PostgreSQL does after DISCARD ALL or the pgbouncer reset. Statements
+// prepared in the transaction are kept by its server connection.
+func (c *pgxmock) statementExists(call *Call) error {
+	sql := call.SQL
+	if c.inOpenTx(call) && call.tx.keepsStatement(sql) {
+		return nil
+	}
+	c.mu.Lock()
+	prepared, ok := c.statements[sql]
+	c.mu.Unlock()
+	if ok && !prepared {
+		return &pgconn.PgError{
+			Severity: "ERROR",
+			Code:     "26000",
+			Message:  fmt.Sprintf("prepared statement \"%s\" does not exist", sql),
+		}
+	}
+	return nil
 }
 
 func (c *pgxmock) Commit(ctx context.Context) error {
-	ex, err := findExpectation[*ExpectedCommit](c, "Commit()")
+	return c.commit(ctx, nil)
+}
+
+// commit commits the transaction tx or the last begun one if tx is nil
+func (c *pgxmock) commit(ctx context.Context, tx *pgxmockTx) error {
+	tx, err := c.openTx(tx)
 	if err != nil {
 		return err
 	}
-	return ex.waitForDelay(ctx)
+	err = c.handle(ctx, &Call{Method: "Commit()", tx: tx}, func(ctx context.Context, call *Call) error {
+		ex, err := findExpectation[*ExpectedCommit](ctx, c, call)
+		if err != nil {
+			return err
+		}
+		return ex.waitForDelay(ctx, c.clock, call.number)
+	})
+	c.endTx(tx)
+	// the failed commit rolls the transaction back
+	if err != nil {
+		c.store.rollback(tx)
+	} else {
+		c.store.commit(tx)
+	}
+	return err
 }
 
 func (c *pgxmock) Rollback(ctx context.Context) error {
-	ex, err := findExpectation[*ExpectedRollback](c, "Rollback()")
+	return c.rollback(ctx, nil)
+}
+
+// rollback rolls back the transaction tx or the last begun one if tx is nil
+func (c *pgxmock) rollback(ctx context.Context, tx *pgxmockTx) error {
+	tx, err := c.openTx(tx)
 	if err != nil {
 		return err
 	}
-	return ex.waitForDelay(ctx)
+	defer c.store.rollback(tx)
+	defer c.endTx(tx)
+	return c.handle(ctx, &Call{Method: "Rollback()", tx: tx}, func(ctx context.Context, call *Call) error {
+		ex, err := findExpectation[*ExpectedRollback](ctx, c, call)
+		if err != nil {
+			return err
+		}
+		return ex.waitForDelay(ctx, c.clock, call.number)
+	})
 }
 
 // Implement the "QueryerContext" interface
 func (c *pgxmock) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
-	ex, err := findExpectationFunc[*ExpectedQuery](c, "Query()", func(queryExp *ExpectedQuery) error {
-		if err := c.queryMatcher.Match(queryExp.expectSQL, sql); err != nil {
+	return c.queryIn(ctx, nil, sql, args...)
+}
+
+// queryIn issues the traced query in the transaction tx or on the mock if tx is nil
+func (c *pgxmock) queryIn(ctx context.Context, tx *pgxmockTx, sql string, args ...interface{}) (pgx.Rows, error) {
+	ctx = c.traceQueryStart(ctx, sql, args)
+	rows, err := c.query(ctx, tx, sql, args...)
+	c.traceQueryEnd(ctx, rowsCommandTag(rows), err)
+	return rows, err
+}
+
+func (c *pgxmock) query(ctx context.Context, tx *pgxmockTx, sql string, args ...interface{}) (pgx.Rows, error) {
+	var rows pgx.Rows
+	err := c.handle(ctx, &Call{Method: "Query()", SQL: sql, Args: args, tx: tx}, func(ctx context.Context, call *Call) error {
+		if err := c.statementAllowed(call); err != nil {
 			return err
 		}
-		if rewrittenSQL, err := queryExp.argsMatches(sql, args); err != nil {
-			return err
-		} else if rewrittenSQL != "" && queryExp.expectRewrittenSQL != "" {
-			if err := c.queryMatcher.Match(queryExp.expectRewrittenSQL, rewrittenSQL); err != nil {
+		ex, err := findExpectationFunc[*ExpectedQuery](ctx, c, call, func(queryExp *ExpectedQuery) error {
+			if err := c.statementMatches(&queryExp.queryBasedExpectation, call); err != nil {
 				return err
 			}
+			if queryExp.err == nil && queryExp.rows == nil {
+				return lazyErrorf("Query must return a result rows or raise an error: %v", queryExp)
+			}
+			return nil
+		})
+		if err != nil {
+			rows, err = c.queryTables(call, err)
+			return err
 		}
-		if queryExp.err == nil && queryExp.rows == nil {
-			return fmt.Errorf("Query must return a result rows or raise an error: %v", queryExp)
+		if rows, err = ex.issueRows(call); err != nil {
+			return err
 		}
-		return nil
+		return ex.waitForDelay(ctx, c.clock, call.number)
 	})
+	if rs, ok := rows.(*rowSets); ok && err == nil && c.strictConn {
+		c.mu.Lock()
+		c.openRows = rs
+		c.mu.Unlock()
+		rs.done = func() { c.releaseRows(rs) }
+	}
+	if rs, ok := rows.(*rowSets); ok && err == nil && c.detectRowsLeaks {
+		issued := issuedRows{rows: rs, sql: sql, site: declarationSite()}
+		c.mu.Lock()
+		c.issuedRows = append(c.issuedRows, issued)
+		c.mu.Unlock()
+	}
+	return rows, err
+}
+
+// statementAllowed checks the statement of the Query() or Exec() call
+// before looking for the matching expectation
+func (c *pgxmock) statementAllowed(call *Call) error {
+	if c.encodableArgs {
+		if err := c.argsEncodable(call.SQL, call.Args); err != nil {
+			return err
+		}
+	}
+	if err := c.statementExists(call); err != nil {
+		return err
+	}
+	return c.writeAllowed(call.tx, writeStatement(call.SQL))
+}
+
+// statementMatches checks the SQL and arguments of the call against the
+// expectation, including the SQL rewritten by the pgx.QueryRewriter argument
+func (c *pgxmock) statementMatches(e *queryBasedExpectation, call *Call) error {
+	if err := e.sqlMatches(c.queryMatcher, call.SQL); err != nil {
+		return err
+	}
+	rewrittenSQL, err := e.argsMatches(c.rewriterConn, call.SQL, call.Args)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if rewrittenSQL != "" && e.expectRewrittenSQL != "" {
+		if err := c.queryMatcher.Match(e.expectRewrittenSQL, rewrittenSQL); err != nil {
+			return sqlMismatch(e.expectRewrittenSQL, rewrittenSQL, err)
+		}
+	}
+	return nil
+}
+
+// queryTables answers the query from the in-memory tables, see SeedTable,
+// if no expectation matches, otherwise returns the mismatch error
+func (c *pgxmock) queryTables(call *Call, mismatch error) (pgx.Rows, error) {
+	if rows, ok, err := c.store.query(call.tx, call.SQL, call.Args); ok {
+		return rows, err
 	}
-	return ex.rows, ex.waitForDelay(ctx)
+	return nil, mismatch
+}
+
+// issueRows returns the rows of the expectation matched by the call. Every call
+// of the repeated query, the query with result formats or pages gets the fresh copy
+func (e *ExpectedQuery) issueRows(call *Call) (pgx.Rows, error) {
+	e.Lock()
+	defer e.Unlock()
+	rs, ok := e.rows.(*rowSets)
+	if !ok {
+		return e.rows, nil
+	}
+	formats, formatsByOID := resultFormats(call.Args)
+	if formats == nil && formatsByOID == nil {
+		formats = e.resultFormats
+	}
+	if e.triggered > 1 || formats != nil || formatsByOID != nil || e.pages != nil {
+		rs = rs.reissue()
+		if e.pages != nil {
+			page, err := e.pages.page(call.SQL, call.Args)
+			if err != nil {
+				return nil, fmt.Errorf("failed to select the page: %w", err)
+			}
+			rs.sets = []*Rows{page}
+		}
+		rs.setFormats(formats, formatsByOID)
+		e.rows = rs
+	}
+	return e.rows, nil
+}
+
+// resultFormats returns result formats passed to the query the same
+// way as to pgx with leading pgx.QueryResultFormats and
+// pgx.QueryResultFormatsByOID arguments
+func resultFormats(args []any) (formats pgx.QueryResultFormats, formatsByOID pgx.QueryResultFormatsByOID) {
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case pgx.QueryResultFormats:
+			formats = arg
+		case pgx.QueryResultFormatsByOID:
+			formatsByOID = arg
+		case pgx.QueryExecMode:
+		default:
+			return
+		}
+	}
+	return
+}
+
+// issuedRows are the rows returned by the query and the query call site
+type issuedRows struct {
+	rows *rowSets
+	sql  string
+	site string
+}
+
+// openTransactions returns an error listing all transactions neither
+// committed nor rolled back, if enabled by VerifyTxCompletionOption
+func (c *pgxmock) openTransactions() error {
+	if !c.verifyTx {
+		return nil
+	}
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+	if len(c.txs) == 0 {
+		return nil
+	}
+	open := make([]string, len(c.txs))
+	for i, tx := range c.txs {
+		open[i] = "\t- begun at " + tx.site
+	}
+	return fmt.Errorf("%d transactions were neither committed nor rolled back:\n%s", len(open), strings.Join(open, "\n"))
+}
+
+// rowsLeaks returns an error listing all rows returned by queries, but
+// never closed, if the leak detector is enabled by RowsLeakDetectionOption
+func (c *pgxmock) rowsLeaks() error {
+	var leaks []string
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, issued := range c.issuedRows {
+		if !issued.rows.closed && !issued.rows.singleRow {
+			leaks = append(leaks, fmt.Sprintf("\t- '%s' queried at %s", issued.sql, issued.site))
+		}
+	}
+	if len(leaks) > 0 {
+		return fmt.Errorf("rows of %d queries were never closed:\n%s", len(leaks), strings.Join(leaks, "\n"))
+	}
+	return nil
 }
 
 type errRow struct {
@@ -494,48 +1251,98 @@ func (er errRow) Scan(...interface{}) error {
 }
 
 func (c *pgxmock) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
-	rows, err := c.Query(ctx, sql, args...)
+	return c.queryRowIn(ctx, nil, sql, args...)
+}
+
+// queryRowIn issues the query in the transaction tx or on the mock if tx is nil
+func (c *pgxmock) queryRowIn(ctx context.Context, tx *pgxmockTx, sql string, args ...interface{}) pgx.Row {
+	rows, err := c.queryIn(ctx, tx, sql, args...)
 	if err != nil {
 		return errRow{err: err}
 	}
-	return (*connRow)(rows.(*rowSets))
+	rs, ok := rows.(*rowSets)
+	if !ok {
+		if rows == nil {
+			return errRow{err: fmt.Errorf("QueryRow: no result is available for query '%s', the middleware must call the next handler or return an error", sql)}
+		}
+		rows.Close()
+		return errRow{err: fmt.Errorf("QueryRow: rows of type %T are not supported", rows)}
+	}
+	rs.singleRow = true
+	rs.driverBytes = c.driverBytes
+	c.releaseRows(rs) // the row never keeps the connection busy
+	return (*connRow)(rs)
 }
 
 func (c *pgxmock) Exec(ctx context.Context, query string, args ...interface{}) (pgconn.CommandTag, error) {
-	ex, err := findExpectationFunc[*ExpectedExec](c, "Exec()", func(execExp *ExpectedExec) error {
-		if err := c.queryMatcher.Match(execExp.expectSQL, query); err != nil {
+	return c.execIn(ctx, nil, query, args...)
+}
+
+// execIn executes the traced statement in the transaction tx or on the mock if tx is nil
+func (c *pgxmock) execIn(ctx context.Context, tx *pgxmockTx, query string, args ...interface{}) (pgconn.CommandTag, error) {
+	ctx = c.traceQueryStart(ctx, query, args)
+	result, err := c.exec(ctx, tx, query, args...)
+	c.traceQueryEnd(ctx, result, err)
+	return result, err
+}
+
+func (c *pgxmock) exec(ctx context.Context, tx *pgxmockTx, query string, args ...interface{}) (pgconn.CommandTag, error) {
+	result := pgconn.NewCommandTag("")
+	err := c.handle(ctx, &Call{Method: "Exec()", SQL: query, Args: args, tx: tx}, func(ctx context.Context, call *Call) error {
+		if err := c.statementAllowed(call); err != nil {
 			return err
 		}
-		if rewrittenSQL, err := execExp.argsMatches(query, args); err != nil {
-			return err
-		} else if rewrittenSQL != "" && execExp.expectRewrittenSQL != "" {
-			if err := c.queryMatcher.Match(execExp.expectRewrittenSQL, rewrittenSQL); err != nil {
+		ex, err := findExpectationFunc[*ExpectedExec](ctx, c, call, func(execExp *ExpectedExec) error {
+			if err := c.statementMatches(&execExp.queryBasedExpectation, call); err != nil {
 				return err
 			}
+			if execExp.result.String() == "" && execExp.err == nil {
+				return lazyErrorf("Exec must return a result or raise an error: %s", execExp)
+			}
+			return nil
+		})
+		if err != nil {
+			// apply to the in-memory tables if no expectation matches
+			if tag, ok, tableErr := c.store.exec(call.tx, call.SQL, call.Args); ok {
+				result = tag
+				return tableErr
+			}
+			return err
 		}
-		if execExp.result.String() == "" && execExp.err == nil {
-			return fmt.Errorf("Exec must return a result or raise an error: %s", execExp)
-		}
-		return nil
+		result = ex.result
+		return ex.waitForDelay(ctx, c.clock, call.number)
 	})
-	if err != nil {
-		return pgconn.NewCommandTag(""), err
-	}
-	return ex.result, ex.waitForDelay(ctx)
+	return result, err
 }
 
 func (c *pgxmock) Ping(ctx context.Context) (err error) {
-	ex, err := findExpectation[*ExpectedPing](c, "Ping()")
-	if err != nil {
-		return err
-	}
-	return ex.waitForDelay(ctx)
+	return c.handle(ctx, &Call{Method: "Ping()"}, func(ctx context.Context, call *Call) error {
+		ex, err := findExpectation[*ExpectedPing](ctx, c, call)
+		if err != nil {
+			return err
+		}
+		return ex.waitForDelay(ctx, c.clock, call.number)
+	})
+}
+
+func (c *pgxmock) CancelRequest(ctx context.Context) error {
+	return c.handle(ctx, &Call{Method: "CancelRequest()"}, func(ctx context.Context, call *Call) error {
+		ex, err := findExpectation[*ExpectedCancelRequest](ctx, c, call)
+		if err != nil {
+			return err
+		}
+		return ex.waitForDelay(ctx, c.clock, call.number)
+	})
 }
 
 func (c *pgxmock) Reset() {
-	if ex, err := findExpectation[*ExpectedReset](c, "Reset()"); err == nil {
-		_ = ex.waitForDelay(context.Background())
-	}
+	_ = c.handle(context.Background(), &Call{Method: "Reset()"}, func(ctx context.Context, call *Call) error {
+		ex, err := findExpectation[*ExpectedReset](ctx, c, call)
+		if err != nil {
+			return err
+		}
+		return ex.waitForDelay(ctx, c.clock, call.number)
+	})
 }
 
 type expectationType[t any] interface {
@@ -543,49 +1350,168 @@ type expectationType[t any] interface {
 	expectation
 }
 
-func findExpectationFunc[ET expectationType[t], t any](c *pgxmock, method string, cmp func(ET) error) (ET, error) {
+func findExpectationFunc[ET expectationType[t], t any](ctx context.Context, c *pgxmock, call *Call, cmp func(ET) error) (ET, error) {
 	var expected ET
 	var fulfilled int
-	var ok bool
-	var err error
-	for _, next := range c.expectations {
+	if c.ordered {
+		var err error
+		if expected, fulfilled, err = findOrderedExpectation(ctx, c, call, cmp); err != nil {
+			return nil, err
+		}
+	} else {
+		expected, fulfilled = findBestExpectation(ctx, c, call, cmp)
+	}
+	if expected == nil {
+		msg := fmt.Sprintf("call to method %s was not expected", call.Method)
+		if fulfilled == len(c.declared()) {
+			msg = "all expectations were already fulfilled, " + msg
+		}
+		return nil, c.failure(markError(ErrUnexpectedCall, errors.New(msg)))
+	}
+	expected.fulfill()
+	if state, ok := expected.transition(); ok {
+		c.setState(state)
+	}
+	call.expectation = expected
+	call.number, _ = expected.calls()
+	if expected.fulfilled() {
+		expected.setFulfilledAt(c.clock.Now())
+	}
+	expected.Unlock()
+	c.checkDeadline(expected)
+	if c.onMatched != nil {
+		c.onMatched(c.expectationInfo(expected), call)
+	}
+	return expected, nil
+}
+
+// findOrderedExpectation walks the expectations in the declaration order and returns
+// the first matching one locked, or the error if the next required one does not match
+func findOrderedExpectation[ET expectationType[t], t any](ctx context.Context, c *pgxmock, call *Call, cmp func(ET) error) (ET, int, error) {
+	var fulfilled int
+	for _, next := range c.declared() {
 		next.Lock()
 		if next.fulfilled() {
 			next.Unlock()
 			fulfilled++
 			continue
 		}
-		if expected, ok = next.(ET); ok {
-			if err = cmp(expected); err == nil {
-				break
-			}
+		expected, ok, err := matchCandidate(ctx, c, call, next, cmp)
+		if ok && err == nil {
+			return expected, fulfilled, nil
 		}
-		expected = nil
 		next.Unlock()
-		if c.ordered {
-			if !next.required() {
-				continue
-			}
-			if err != nil {
-				return nil, err
+		if !next.required() || c.orderByType && !ok {
+			continue
+		}
+		if err != nil {
+			var mismatch *MismatchError
+			if errors.As(err, &mismatch) {
+				mismatch.Method = call.Method
 			}
-			return nil, fmt.Errorf("call to method %s, was not expected, next expectation is: %s", method, next)
+			return nil, fulfilled, c.failure(markError(ErrUnexpectedCall, fmt.Errorf("%w%s", err, declaredAt(next))))
 		}
+		return nil, fulfilled, c.failure(markError(ErrUnexpectedCall, fmt.Errorf("call to method %s, was not expected, next expectation%s is: %s", call.Method, declaredAt(next), next)))
 	}
+	return nil, fulfilled, nil
+}
 
-	if expected == nil {
-		msg := fmt.Sprintf("call to method %s was not expected", method)
-		if fulfilled == len(c.expectations) {
-			msg = "all expectations were already fulfilled, " + msg
+// findBestExpectation returns the most specific matching expectation locked,
+// the first declared one wins ties, see scoreMatch
+func findBestExpectation[ET expectationType[t], t any](ctx context.Context, c *pgxmock, call *Call, cmp func(ET) error) (ET, int) {
+	var fulfilled int
+	var best ET
+	var bestScore matchScore
+	for _, next := range c.declared() {
+		next.Lock()
+		if next.fulfilled() {
+			next.Unlock()
+			fulfilled++
+			continue
 		}
-		return nil, errors.New(msg)
+		if expected, ok, err := matchCandidate(ctx, c, call, next, cmp); ok && err == nil {
+			if score := scoreMatch(expected); best == nil || score.better(bestScore) {
+				best, bestScore = expected, score
+			}
+		}
+		next.Unlock()
 	}
-	defer expected.Unlock()
+	if best != nil {
+		best.Lock()
+		if best.fulfilled() { // fulfilled by a concurrent call meanwhile
+			best.Unlock()
+			return findBestExpectation(ctx, c, call, cmp)
+		}
+	}
+	return best, fulfilled
+}
 
-	expected.fulfill()
-	return expected, nil
+// matchCandidate reports whether the locked expectation is of type ET
+// and returns the error if it does not match the call
+func matchCandidate[ET expectationType[t], t any](ctx context.Context, c *pgxmock, call *Call, next expectation, cmp func(ET) error) (ET, bool, error) {
+	expected, ok := next.(ET)
+	if !ok {
+		return nil, false, nil
+	}
+	if err := txMatches(call.Method, next.requiredTx(), call.tx); err != nil {
+		return expected, true, err
+	}
+	if err := next.matchesState(c.currentState()); err != nil {
+		return expected, true, err
+	}
+	if err := next.matchesContext(ctx); err != nil {
+		return expected, true, err
+	}
+	return expected, true, cmp(expected)
+}
+
+// matchScore describes how specific the matched expectation is
+type matchScore struct {
+	priority int // set by Priority, outweighs the specificity
+	args     int // exact arguments weigh more than matchers, AnyArg weighs nothing
+	sql      int // number of literal characters in the expected SQL
 }
 
-func findExpectation[ET expectationType[t], t any](c *pgxmock, method string) (ET, error) {
-	return findExpectationFunc[ET, t](c, method, func(_ ET) error { return nil })
+// better reports whether the score is higher than the other one
+func (s matchScore) better(other matchScore) bool {
+	if s.priority != other.priority {
+		return s.priority > other.priority
+	}
+	if s.args != other.args {
+		return s.args > other.args
+	}
+	return s.sql > other.sql
+}
+
+// scoreMatch scores the specificity of the matched expectation to choose
+// the best one in unordered mode, the first declared one wins ties
+func scoreMatch(e expectation) (score matchScore) {
+	score.priority = e.priority()
+	var q *queryBasedExpectation
+	switch e := e.(type) {
+	case *ExpectedQuery:
+		q = &e.queryBasedExpectation
+	case *ExpectedExec:
+		q = &e.queryBasedExpectation
+	default:
+		return
+	}
+	for _, arg := range q.args {
+		switch arg.(type) {
+		case anyArgument:
+		case Argument:
+			score.args++
+		default:
+			score.args += 2
+		}
+	}
+	score.sql = len(q.expectSQL) - len(reMeta.FindAllString(q.expectSQL, -1))
+	return
+}
+
+// reMeta matches regular expression metacharacters
+var reMeta = regexp.MustCompile(`[\\.+*?()|\[\]{}^$]`)
+
+func findExpectation[ET expectationType[t], t any](ctx context.Context, c *pgxmock, call *Call) (ET, error) {
+	return findExpectationFunc[ET, t](ctx, c, call, func(_ ET) error { return nil })
 }
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
 )
@@ -1229,3 +1230,454 @@ func TestDoubleUnlock(t *testing.T) {
 	a.Error(err)
 	a.NotPanics(func() { _ = mock.Ping(ctx) })
 }
+
+func TestExpectTx(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	mock.ExpectTx(func(tx TxExpecter) {
+		tx.ExpectExec("UPDATE products").WillReturnResult(NewResult("UPDATE", 1))
+		tx.ExpectQuery("SELECT views").WillReturnRows(NewRows([]string{"views"}).AddRow(42))
+	})
+
+	tx, err := mock.Begin(ctx)
+	a.NoError(err)
+	_, err = tx.Exec(ctx, "UPDATE products SET views = views + 1")
+	a.NoError(err)
+	var views int
+	a.NoError(tx.QueryRow(ctx, "SELECT views FROM products").Scan(&views))
+	a.Equal(42, views)
+	a.NoError(tx.Commit(ctx))
+	a.NoError(mock.ExpectationsWereMet())
+
+	mock.ExpectTx(nil).WillReturnError(errors.New("commit failed"))
+	tx, err = mock.Begin(ctx)
+	a.NoError(err)
+	a.Error(tx.Commit(ctx))
+	a.NoError(mock.ExpectationsWereMet())
+
+	mock.ExpectTx(func(tx TxExpecter) {
+		tx.ExpectExec("UPDATE products").WillReturnResult(NewResult("UPDATE", 1))
+	})
+	tx, err = mock.Begin(ctx)
+	a.NoError(err)
+	_, err = mock.Exec(ctx, "UPDATE products SET views = views + 1")
+	a.ErrorContains(err, "Exec: call was expected to be issued in the transaction begun at pgxmock_test.go:")
+	_, err = tx.Exec(ctx, "UPDATE products SET views = views + 1")
+	a.NoError(err)
+	a.NoError(tx.Commit(ctx))
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestExpectTxRollingBackAfter(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	mock.ExpectTxRollingBackAfter("INSERT INTO product_viewers", errors.New("some error")).
+		WithArgs(2, 3)
+
+	tx, err := mock.Begin(ctx)
+	a.NoError(err)
+	_, err = tx.Exec(ctx, "INSERT INTO product_viewers (user_id, product_id) VALUES ($1, $2)", 2, 3)
+	a.EqualError(err, "some error")
+	a.Error(mock.ExpectationsWereMet(), "rollback must be awaited")
+	a.NoError(tx.Rollback(ctx))
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestStrictConnOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn(StrictConnOption(true))
+	mock.ExpectQuery("SELECT").WillReturnRows(NewRows([]string{"a"}).AddRow(1).AddRow(2))
+	mock.ExpectExec("UPDATE").WillReturnResult(NewResult("UPDATE", 1))
+	mock.ExpectQuery("SELECT").WillReturnRows(NewRows([]string{"a"}).AddRow(1))
+	mock.ExpectExec("UPDATE").WillReturnResult(NewResult("UPDATE", 1))
+
+	rows, err := mock.Query(ctx, "SELECT")
+	a.NoError(err)
+	a.True(rows.Next())
+	_, err = mock.Exec(ctx, "UPDATE")
+	a.ErrorIs(err, errConnBusy)
+	rows.Close()
+	_, err = mock.Exec(ctx, "UPDATE")
+	a.NoError(err)
+
+	rows, _ = mock.Query(ctx, "SELECT")
+	for rows.Next() {
+	}
+	_, err = mock.Exec(ctx, "UPDATE")
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+
+	pool, _ := NewPool(StrictConnOption(true))
+	pool.ExpectQuery("SELECT").WillReturnRows(NewRows([]string{"a"}).AddRow(1))
+	pool.ExpectExec("UPDATE").WillReturnResult(NewResult("UPDATE", 1))
+	_, _ = pool.Query(ctx, "SELECT")
+	_, err = pool.Exec(ctx, "UPDATE")
+	a.NoError(err)
+}
+
+func TestEncodableArgsOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	type user struct{ ID int }
+	mock, _ := NewConn(EncodableArgsOption(true))
+	mock.ExpectExec("INSERT").WithArgs(1, "foo", time.Time{}, nil).WillReturnResult(NewResult("INSERT", 1))
+	mock.ExpectQuery("SELECT").WithArgs(pgx.NamedArgs{"id": 1}).WillReturnRows(NewRows([]string{"a"}))
+
+	_, err := mock.Exec(ctx, "INSERT", user{1})
+	a.ErrorContains(err, "failed to encode args[0]")
+	_, err = mock.Exec(ctx, "INSERT", 1, "foo", time.Time{}, nil)
+	a.NoError(err)
+	_, err = mock.Query(ctx, "SELECT", pgx.NamedArgs{"id": 1})
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+	a.NoError(mock.(*pgxmockConn).argsEncodable("SELECT", []any{pgx.QueryExecModeExec, pgx.NamedArgs{"id": 1}}))
+}
+
+func TestExpectQueryAnyOf(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn(QueryMatcherOption(QueryMatcherEqual))
+	mock.ExpectQueryAnyOf("SELECT id FROM users", "SELECT id FROM users_v2").
+		WillReturnRows(NewRows([]string{"id"}).AddRow(1)).
+		Times(2)
+	mock.ExpectQueryAnyOf("SELECT name FROM users", "SELECT name FROM users_v2").
+		WillReturnRows(NewRows([]string{"name"}))
+
+	_, err := mock.Query(ctx, "SELECT id FROM users_v2")
+	a.NoError(err)
+	_, err = mock.Query(ctx, "SELECT id FROM users")
+	a.NoError(err)
+	_, err = mock.Query(ctx, "SELECT name FROM users_v3")
+	a.ErrorContains(err, "users_v2")
+	a.Contains(mock.PendingExpectations()[0].Expectation.String(), "matches any of sql: 'SELECT name FROM users', 'SELECT name FROM users_v2'")
+}
+
+func TestForbidQuery(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	mock.ForbidQuery("DELETE FROM .*")
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(NewResult("DELETE", 1))
+	mock.ExpectExec("UPDATE users").WillReturnResult(NewResult("UPDATE", 1))
+
+	_, err := mock.Exec(ctx, "DELETE FROM users")
+	a.ErrorContains(err, "call to Exec() with query 'DELETE FROM users' is forbidden by pattern 'DELETE FROM .*'")
+	_, err = mock.Exec(ctx, "UPDATE users")
+	a.Error(err, "forbidden call must not fulfill the DELETE expectation")
+	err = mock.ExpectationsWereMet()
+	a.ErrorContains(err, "there were 1 violations")
+	a.ErrorContains(err, "there is a remaining expectation", "unmet expectations are reported together with violations")
+}
+
+func TestForbidQueryConcurrent(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewPool()
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mock.ForbidQuery("DELETE FROM t" + strconv.Itoa(i))
+			_, err := mock.Exec(ctx, "DELETE FROM t"+strconv.Itoa(i))
+			a.Error(err)
+		}()
+	}
+	wg.Wait()
+	a.ErrorContains(mock.ExpectationsWereMet(), "there were 20 violations")
+}
+
+func TestDefaultDelayOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn(DefaultDelayOption(10 * time.Millisecond))
+	mock.ExpectPing()
+	mock.ExpectPing().WillDelayFor(0)
+	mock.ExpectPing().WillDelayFor(time.Millisecond)
+
+	start := time.Now()
+	a.NoError(mock.Ping(ctx))
+	a.GreaterOrEqual(time.Since(start), 10*time.Millisecond)
+	a.NoError(mock.Ping(ctx))
+	a.NoError(mock.Ping(ctx))
+	report := mock.TimingReport()
+	a.Equal(11*time.Millisecond, report.TotalDelay)
+	a.Equal(time.Duration(0), report.Calls[1].Delay)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestUnorderedBestMatch(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectExec("INSERT").WithArgs(AnyArg()).WillReturnResult(NewResult("INSERT", 1))
+	mock.ExpectExec("INSERT").WithArgs(42).WillReturnResult(NewResult("INSERT", 42))
+	mock.ExpectQuery("SELECT").WillReturnRows(NewRows([]string{"any"}))
+	mock.ExpectQuery("SELECT name FROM users").WillReturnRows(NewRows([]string{"name"}))
+
+	tag, err := mock.Exec(ctx, "INSERT", 42)
+	a.NoError(err)
+	a.EqualValues(42, tag.RowsAffected(), "exact argument is preferred over AnyArg")
+	tag, err = mock.Exec(ctx, "INSERT", 1)
+	a.NoError(err)
+	a.EqualValues(1, tag.RowsAffected())
+
+	rows, err := mock.Query(ctx, "SELECT name FROM users")
+	a.NoError(err)
+	a.Equal("name", rows.FieldDescriptions()[0].Name, "more specific SQL is preferred")
+	rows, err = mock.Query(ctx, "SELECT id FROM users")
+	a.NoError(err)
+	a.Equal("any", rows.FieldDescriptions()[0].Name)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestExpectationPriority(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectExec("UPDATE users SET name").WithArgs(42).WillReturnResult(NewResult("UPDATE", 1)).Priority(-1)
+	mock.ExpectExec("UPDATE").WithArgs(AnyArg()).WillReturnResult(NewResult("UPDATE", 2)).Priority(1)
+
+	tag, err := mock.Exec(ctx, "UPDATE users SET name = 'john' WHERE id = $1", 42)
+	a.NoError(err)
+	a.EqualValues(2, tag.RowsAffected(), "priority outweighs specificity")
+	tag, err = mock.Exec(ctx, "UPDATE users SET name = 'john' WHERE id = $1", 42)
+	a.NoError(err)
+	a.EqualValues(1, tag.RowsAffected())
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestOrderByTypeOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn(OrderByTypeOption(true))
+	mock.ExpectExec("INSERT").WillReturnResult(NewResult("INSERT", 1))
+	mock.ExpectExec("UPDATE").WillReturnResult(NewResult("UPDATE", 1))
+	mock.ExpectQuery("SELECT").WillReturnRows(NewRows([]string{"id"}))
+
+	_, err := mock.Query(ctx, "SELECT")
+	a.NoError(err, "queries are ordered independently from execs")
+	_, err = mock.Exec(ctx, "UPDATE")
+	a.Error(err, "execs are still ordered")
+	_, err = mock.Exec(ctx, "INSERT")
+	a.NoError(err)
+	_, err = mock.Exec(ctx, "UPDATE")
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestRowsLeakDetectionOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn(RowsLeakDetectionOption(true))
+	mock.ExpectQuery("SELECT id").WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("SELECT name").WillReturnRows(NewRows([]string{"name"}).AddRow("john"))
+	mock.ExpectQuery("SELECT email").WillReturnRows(NewRows([]string{"email"}).AddRow("john@example.com"))
+
+	rows, err := mock.Query(ctx, "SELECT id FROM users")
+	a.NoError(err)
+	rows.Close()
+	_, err = mock.Query(ctx, "SELECT name FROM users")
+	a.NoError(err)
+	var email string
+	a.NoError(mock.QueryRow(ctx, "SELECT email FROM users").Scan(&email))
+
+	err = mock.ExpectationsWereMet()
+	a.ErrorContains(err, "rows of 1 queries were never closed")
+	a.ErrorContains(err, "'SELECT name FROM users' queried at pgxmock_test.go:")
+	a.NotContains(err.Error(), "SELECT id")
+}
+
+func TestRowsLeakDetectionConcurrent(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewPool(RowsLeakDetectionOption(true))
+	mock.MatchExpectationsInOrder(false)
+	for range 20 {
+		mock.ExpectQuery("SELECT id").WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+	}
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := mock.Query(ctx, "SELECT id FROM users")
+			a.NoError(err)
+		}()
+	}
+	wg.Wait()
+	a.ErrorContains(mock.ExpectationsWereMet(), "rows of 20 queries were never closed")
+}
+
+func TestDeallocatedStatements(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	mock.ExpectPrepare("get_user", "SELECT").Times(2)
+	mock.ExpectPrepare("del_user", "DELETE")
+	mock.ExpectDeallocate("get_user")
+	mock.ExpectDeallocateAll()
+
+	_, err := mock.Prepare(ctx, "get_user", "SELECT")
+	a.NoError(err)
+	_, err = mock.Prepare(ctx, "get_user", "SELECT")
+	a.NoError(err)
+	_, err = mock.Prepare(ctx, "del_user", "DELETE")
+	a.NoError(err)
+	a.NoError(mock.Deallocate(ctx, "get_user"))
+	_, err = mock.Query(ctx, "get_user")
+	var pgErr *pgconn.PgError
+	a.ErrorAs(err, &pgErr)
+	a.Equal("26000", pgErr.Code)
+	a.NoError(mock.DeallocateAll(ctx))
+	_, err = mock.Exec(ctx, "del_user")
+	a.ErrorContains(err, `prepared statement "del_user" does not exist`)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestStatementNameMatching(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	mock.ExpectPrepare(AnyStatementName, "SELECT")
+	mock.ExpectPrepare("", "INSERT").WithStmtNameRegexp(`^lrupsc_\d+$`)
+	mock.ExpectDeallocate(AnyStatementName).WithStmtNameRegexp(`^lrupsc_`)
+
+	_, err := mock.Prepare(ctx, "4f9b1c2e", "SELECT 1")
+	a.NoError(err)
+	_, err = mock.Prepare(ctx, "stmt_1", "INSERT INTO users")
+	a.ErrorContains(err, "expected name is matching '^lrupsc_\\d+$'")
+	_, err = mock.Prepare(ctx, "lrupsc_1_0", "INSERT INTO users")
+	a.Error(err)
+	_, err = mock.Prepare(ctx, "lrupsc_10", "INSERT INTO users")
+	a.NoError(err)
+	a.NoError(mock.Deallocate(ctx, "lrupsc_10"))
+	a.NoError(mock.ExpectationsWereMet())
+
+	mock.ExpectPrepare(AnyStatementName, "SELECT")
+	a.Contains(mock.PendingExpectations()[0].Expectation.String(), "matches statement name: any")
+
+	mock, _ = NewConn()
+	a.NotPanics(func() { mock.ExpectPrepare("", "SELECT").WithStmtNameRegexp(`^lrupsc_(`) })
+	_, err = mock.Prepare(ctx, "lrupsc_1", "SELECT 1")
+	a.ErrorContains(err, "Prepare: invalid statement name pattern: error parsing regexp")
+	a.Error(mock.ExpectationsWereMet())
+}
+
+func TestTransactionPoolingOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn(TransactionPoolingOption(true))
+	mock.ExpectPrepare("get_user", "SELECT")
+	mock.ExpectBegin()
+	mock.ExpectPrepare("get_user", "SELECT")
+	mock.ExpectExec("get_user").WithArgs(1).WillReturnResult(NewResult("SELECT", 1))
+	mock.ExpectCommit()
+
+	var pgErr *pgconn.PgError
+	_, err := mock.Prepare(ctx, "get_user", "SELECT * FROM users WHERE id = $1")
+	a.NoError(err)
+	_, err = mock.Query(ctx, "get_user", 1)
+	a.ErrorAs(err, &pgErr, "the statement is executed on another server connection")
+	a.Equal("26000", pgErr.Code)
+
+	tx, _ := mock.Begin(ctx)
+	_, err = tx.Prepare(ctx, "get_user", "SELECT * FROM users WHERE id = $1")
+	a.NoError(err)
+	_, err = mock.Query(ctx, "get_user", 1)
+	a.ErrorAs(err, &pgErr, "the pool call is executed on another server connection")
+	_, err = tx.Exec(ctx, "get_user", 1)
+	a.NoError(err, "the transaction keeps the server connection")
+	a.NoError(tx.Commit(ctx))
+
+	_, err = mock.Query(ctx, "get_user", 1)
+	a.ErrorContains(err, `prepared statement "get_user" does not exist`)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestCancelRequest(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewPool()
+	a.ErrorContains(mock.CancelRequest(ctx), "call to method CancelRequest() was not expected")
+	mock.ExpectCancelRequest().WillReturnError(errors.New("network error"))
+	a.EqualError(mock.CancelRequest(ctx), "network error")
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestVerifyTxCompletionOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn(VerifyTxCompletionOption(true))
+	mock.ExpectBegin()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	tx, err := mock.Begin(ctx)
+	a.NoError(err)
+	savepoint, err := tx.Begin(ctx)
+	a.NoError(err)
+	a.NoError(savepoint.Commit(ctx))
+	a.ErrorIs(savepoint.Rollback(ctx), pgx.ErrTxClosed, "deferred rollback of the committed savepoint")
+	err = mock.ExpectationsWereMet()
+	a.ErrorIs(err, ErrUnmetExpectations)
+	a.Regexp(`^1 transactions were neither committed nor rolled back:\n\t- begun at pgxmock_test\.go:\d+$`, err.Error())
+
+	mock.ExpectCommit().WillReturnError(errors.New("serialization failure"))
+	a.Error(tx.Commit(ctx))
+	a.ErrorIs(tx.Rollback(ctx), pgx.ErrTxClosed)
+	a.ErrorIs(tx.Commit(ctx), pgx.ErrTxClosed)
+	a.NoError(mock.ExpectationsWereMet(), "failed commit completes the transaction")
+
+	mock.ExpectBegin()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	tx, _ = mock.Begin(ctx)
+	savepoint, _ = tx.Begin(ctx)
+	a.NoError(tx.Rollback(ctx))
+	a.ErrorIs(savepoint.Rollback(ctx), pgx.ErrTxClosed, "savepoints end with the transaction")
+	a.NoError(mock.ExpectationsWereMet())
+
+	mock, _ = NewConn()
+	mock.ExpectBegin()
+	_, err = mock.Begin(ctx)
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet(), "open transactions are not verified by default")
+}
+
+func TestRequireCloseOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn(RequireCloseOption(true))
+	mock.ExpectPing()
+	a.NoError(mock.Ping(ctx))
+	err := mock.ExpectationsWereMet()
+	a.ErrorIs(err, ErrUnmetExpectations)
+	a.EqualError(err, "Close() was never called")
+	a.NoError(mock.Close(ctx))
+	a.NoError(mock.ExpectationsWereMet())
+	a.ErrorContains(mock.Close(ctx), "call to method Close() was not expected", "only the first Close is expected implicitly")
+
+	mock, _ = NewConn(RequireCloseOption(true))
+	mock.ExpectClose().WillReturnError(errors.New("close failed"))
+	a.EqualError(mock.Close(ctx), "close failed")
+	a.NoError(mock.ExpectationsWereMet())
+
+	mock, _ = NewConn(RequireCloseOption(true))
+	mock.ExpectPing()
+	mock.ExpectClose()
+	a.Error(mock.Close(ctx), "Close is matched against the declared expectations")
+
+	pool, _ := NewPool(RequireCloseOption(true), VerifyPoolCloseOption(true))
+	a.EqualError(pool.ExpectationsWereMet(), "Close() was never called")
+	pool.Close()
+	a.NoError(pool.ExpectationsWereMet())
+}
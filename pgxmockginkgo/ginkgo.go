@@ -0,0 +1,49 @@
+// Package pgxmockginkgo provides helpers and Gomega matchers to use pgxmock
+// in Ginkgo suites. It lives in a separate module, so pgxmock itself stays
+// free of third party dependencies.
+package pgxmockginkgo
+
+import (
+	"fmt"
+
+	"github.com/onsi/ginkgo/v2"
+)
+
+// Mock is the mock connection or pool, e.g. pgxmock.PgxPoolIface
+type Mock interface {
+	ExpectationsWereMet() error
+}
+
+// Setup registers BeforeEach creating the new mock with newMock
+// and options for every spec, and verifies its expectations after
+// the spec completes. It returns the pointer to the mock of the
+// current spec and should be called in a container node or at the
+// top level of the suite, e.g.
+//
+//	var _ = Describe("users", func() {
+//		mock := pgxmockginkgo.Setup(pgxmock.NewPool)
+//		It("adds the user", func() {
+//			(*mock).ExpectExec("INSERT INTO users").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+//			...
+//		})
+//	})
+func Setup[M Mock, O any](newMock func(...O) (M, error), options ...O) *M {
+	mock := new(M)
+	ginkgo.BeforeEach(func() {
+		m, err := newMock(options...)
+		if err != nil {
+			ginkgo.Fail(fmt.Sprintf("failed to create the mock: %s", err))
+		}
+		*mock = m
+		ginkgo.DeferCleanup(Verify, m)
+	})
+	return mock
+}
+
+// Verify fails the current spec if not all expectations of the mock were met
+func Verify(mock Mock) {
+	ginkgo.GinkgoHelper()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		ginkgo.Fail(fmt.Sprintf("there were unfulfilled expectations: %s", err))
+	}
+}
@@ -0,0 +1,38 @@
+package pgxmockginkgo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/pashagolub/pgxmock/v4/pgxmockginkgo"
+)
+
+func TestSuite(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "pgxmockginkgo")
+}
+
+var _ = ginkgo.Describe("Setup", func() {
+	var previous pgxmock.PgxPoolIface
+	mock := pgxmockginkgo.Setup(pgxmock.NewPool, pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+
+	ginkgo.It("creates the mock before the spec", func() {
+		(*mock).ExpectExec("DELETE FROM users").WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		if _, err := (*mock).Exec(context.Background(), "DELETE FROM users"); err != nil {
+			ginkgo.Fail(err.Error())
+		}
+		previous = *mock
+	})
+
+	ginkgo.It("creates the new mock for every spec", func() {
+		if *mock == previous {
+			ginkgo.Fail("the mock of the previous spec is reused")
+		}
+		if err := (*mock).ExpectationsWereMet(); err != nil {
+			ginkgo.Fail(err.Error())
+		}
+	})
+})
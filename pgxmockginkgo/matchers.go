@@ -0,0 +1,130 @@
+package pgxmockginkgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+// HaveMetAllExpectations succeeds if ExpectationsWereMet of the mock returns no error, e.g.
+//
+//	Expect(mock).To(HaveMetAllExpectations())
+func HaveMetAllExpectations() types.GomegaMatcher {
+	return &metMatcher{}
+}
+
+type metMatcher struct {
+	err error
+}
+
+func (m *metMatcher) Match(actual any) (bool, error) {
+	mock, ok := actual.(Mock)
+	if !ok {
+		return false, fmt.Errorf("HaveMetAllExpectations expects a pgxmock mock, got %T", actual)
+	}
+	m.err = mock.ExpectationsWereMet()
+	return m.err == nil, nil
+}
+
+func (m *metMatcher) FailureMessage(_ any) string {
+	return fmt.Sprintf("Expected all expectations to be met, but: %s", m.err)
+}
+
+func (m *metMatcher) NegatedFailureMessage(_ any) string {
+	return "Expected expectations not to be met, but all of them were"
+}
+
+// HaveExecuted succeeds if the mock was successfully called with SQL
+// matching the regular expression, e.g.
+//
+//	Expect(mock).To(HaveExecuted("INSERT INTO users"))
+//
+// Unexpected, forbidden and failed calls are not taken into account.
+func HaveExecuted(sql string) types.GomegaMatcher {
+	return &executedMatcher{sql: sql}
+}
+
+type executedMatcher struct {
+	sql   string
+	calls []string
+}
+
+func (m *executedMatcher) Match(actual any) (bool, error) {
+	mock, ok := actual.(interface{ CallLogJSON() ([]byte, error) })
+	if !ok {
+		return false, fmt.Errorf("HaveExecuted expects a pgxmock mock, got %T", actual)
+	}
+	data, err := mock.CallLogJSON()
+	if err != nil {
+		return false, err
+	}
+	var calls []struct {
+		SQL   string `json:"sql"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &calls); err != nil {
+		return false, err
+	}
+	m.calls = nil
+	for _, call := range calls {
+		if call.SQL == "" || call.Error != "" {
+			continue
+		}
+		if pgxmock.QueryMatcherRegexp.Match(m.sql, call.SQL) == nil {
+			return true, nil
+		}
+		m.calls = append(m.calls, call.SQL)
+	}
+	return false, nil
+}
+
+func (m *executedMatcher) FailureMessage(_ any) string {
+	return fmt.Sprintf("Expected query matching '%s' to be executed, but executed were:\n\t%s", m.sql, strings.Join(m.calls, "\n\t"))
+}
+
+func (m *executedMatcher) NegatedFailureMessage(_ any) string {
+	return fmt.Sprintf("Expected query matching '%s' not to be executed, but it was", m.sql)
+}
+
+// HavePendingExpectations succeeds if the mock has exactly n
+// expectations not fulfilled yet, including optional ones, e.g.
+//
+//	Expect(mock).To(HavePendingExpectations(2))
+func HavePendingExpectations(n int) types.GomegaMatcher {
+	return &pendingMatcher{n: n}
+}
+
+type pendingMatcher struct {
+	n       int
+	pending []pgxmock.ExpectationInfo
+}
+
+func (m *pendingMatcher) Match(actual any) (bool, error) {
+	mock, ok := actual.(interface {
+		PendingExpectations() []pgxmock.ExpectationInfo
+	})
+	if !ok {
+		return false, fmt.Errorf("HavePendingExpectations expects a pgxmock mock, got %T", actual)
+	}
+	m.pending = mock.PendingExpectations()
+	return len(m.pending) == m.n, nil
+}
+
+func (m *pendingMatcher) labels() string {
+	labels := make([]string, len(m.pending))
+	for i, e := range m.pending {
+		labels[i] = e.Label
+	}
+	return strings.Join(labels, ", ")
+}
+
+func (m *pendingMatcher) FailureMessage(_ any) string {
+	return fmt.Sprintf("Expected %d pending expectations, got %d: %s", m.n, len(m.pending), m.labels())
+}
+
+func (m *pendingMatcher) NegatedFailureMessage(_ any) string {
+	return fmt.Sprintf("Expected not %d pending expectations, got: %s", m.n, m.labels())
+}
@@ -0,0 +1,53 @@
+package pgxmockginkgo_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/pashagolub/pgxmock/v4/pgxmockginkgo"
+)
+
+var _ = ginkgo.Describe("matchers", func() {
+	mock := pgxmockginkgo.Setup(pgxmock.NewConn)
+
+	ginkgo.It("match the mock state", func() {
+		(*mock).ExpectExec("INSERT INTO users").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		(*mock).ExpectPing()
+		gomega.Expect(*mock).To(pgxmockginkgo.HavePendingExpectations(2))
+		gomega.Expect(*mock).NotTo(pgxmockginkgo.HaveMetAllExpectations())
+
+		_, err := (*mock).Exec(context.Background(), "INSERT INTO users(name) VALUES ('john')")
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		gomega.Expect(*mock).To(pgxmockginkgo.HaveExecuted("INSERT INTO users"))
+		gomega.Expect(*mock).NotTo(pgxmockginkgo.HaveExecuted("DELETE"))
+		gomega.Expect(*mock).To(pgxmockginkgo.HavePendingExpectations(1))
+
+		gomega.Expect((*mock).Ping(context.Background())).To(gomega.Succeed())
+		gomega.Expect(*mock).To(pgxmockginkgo.HaveMetAllExpectations())
+	})
+
+	ginkgo.It("describe failures", func() {
+		(*mock).ExpectPing().Maybe()
+		m := pgxmockginkgo.HavePendingExpectations(2)
+		gomega.Expect(m.Match(*mock)).To(gomega.BeFalse())
+		gomega.Expect(m.FailureMessage(*mock)).To(gomega.Equal("Expected 2 pending expectations, got 1: ExpectedPing #0"))
+
+		_, err := pgxmockginkgo.HaveExecuted("SELECT").Match(42)
+		gomega.Expect(err).To(gomega.MatchError("HaveExecuted expects a pgxmock mock, got int"))
+	})
+
+	ginkgo.It("ignore calls that failed", func() {
+		(*mock).ExpectExec("UPDATE users").WillReturnError(errors.New("conflict"))
+
+		_, err := (*mock).Exec(context.Background(), "UPDATE users SET name = 'john'")
+		gomega.Expect(err).To(gomega.MatchError("conflict"))
+		_, err = (*mock).Exec(context.Background(), "DELETE FROM users")
+		gomega.Expect(err).To(gomega.HaveOccurred())
+
+		gomega.Expect(*mock).NotTo(pgxmockginkgo.HaveExecuted("UPDATE users"))
+		gomega.Expect(*mock).NotTo(pgxmockginkgo.HaveExecuted("DELETE"))
+	})
+})
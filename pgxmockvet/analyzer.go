@@ -0,0 +1,315 @@
+// Package pgxmockvet provides the analyzer flagging common mistakes
+// in the test code using pgxmock. It may be run as a standalone tool
+// or with go vet:
+//
+//	go install github.com/pashagolub/pgxmock/v4/pgxmockvet/cmd/pgxmockvet@latest
+//	go vet -vettool=$(which pgxmockvet) ./...
+package pgxmockvet
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// Analyzer reports:
+//   - mocks with expectations, which are never verified with ExpectationsWereMet;
+//   - ExpectExec and ExpectQuery chains without a result or an error to return;
+//   - expected SQL with unescaped parentheses, which are regexp groups
+//     for the default QueryMatcherRegexp and hence never match literally,
+//     unless the mock is created with another QueryMatcherOption;
+//   - Times(0), which means the same as Times(1).
+var Analyzer = &analysis.Analyzer{
+	Name:     "pgxmockvet",
+	Doc:      "check for common mistakes using pgxmock",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+const pgxmockPath = "github.com/pashagolub/pgxmock"
+
+// results lists methods setting the result of the expectation
+var results = map[string][]string{
+	"ExpectExec":  {"WillReturnResult", "WillReturnError", "WillPanic", "WillPanicOnCall"},
+	"ExpectQuery": {"WillReturnRows", "WillReturnPages", "WillReturnError", "WillPanic", "WillPanicOnCall"},
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	literal := literalMatcherMocks(pass, insp)
+	insp.Preorder([]ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil), (*ast.ExprStmt)(nil), (*ast.CallExpr)(nil)}, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				checkVerified(pass, n.Body)
+			}
+		case *ast.FuncLit:
+			checkVerified(pass, n.Body)
+		case *ast.ExprStmt:
+			if call, ok := n.X.(*ast.CallExpr); ok {
+				checkResult(pass, call)
+			}
+		case *ast.CallExpr:
+			checkCall(pass, n, literal)
+		}
+	})
+	return nil, nil
+}
+
+// mockMethod returns the name of the pgxmock method or function called
+func mockMethod(pass *analysis.Pass, call *ast.CallExpr) string {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || !strings.HasPrefix(fn.Pkg().Path(), pgxmockPath) {
+		return ""
+	}
+	return fn.Name()
+}
+
+// literalMatcherMocks returns mocks created with QueryMatcherOption
+// setting another matcher than QueryMatcherRegexp, e.g. QueryMatcherEqual
+func literalMatcherMocks(pass *analysis.Pass, insp *inspector.Inspector) map[types.Object]bool {
+	mocks := make(map[types.Object]bool)
+	insp.Preorder([]ast.Node{(*ast.AssignStmt)(nil)}, func(n ast.Node) {
+		assign := n.(*ast.AssignStmt)
+		if len(assign.Rhs) != 1 || len(assign.Lhs) == 0 {
+			return
+		}
+		call, ok := assign.Rhs[0].(*ast.CallExpr)
+		if !ok || !isLiteralMatcherMock(pass, call) {
+			return
+		}
+		if id, ok := assign.Lhs[0].(*ast.Ident); ok {
+			if obj := pass.TypesInfo.ObjectOf(id); obj != nil {
+				mocks[obj] = true
+			}
+		}
+	})
+	return mocks
+}
+
+// isLiteralMatcherMock reports whether the call creates the mock
+// with QueryMatcherOption setting another matcher than QueryMatcherRegexp
+func isLiteralMatcherMock(pass *analysis.Pass, call *ast.CallExpr) bool {
+	if name := mockMethod(pass, call); name != "NewConn" && name != "NewPool" {
+		return false
+	}
+	for _, arg := range call.Args {
+		c, ok := arg.(*ast.CallExpr)
+		if !ok || mockMethod(pass, c) != "QueryMatcherOption" || len(c.Args) != 1 {
+			continue
+		}
+		return !isMockObject(pass, c.Args[0], "QueryMatcherRegexp")
+	}
+	return false
+}
+
+// isMockObject reports whether the expression is the named pgxmock object
+func isMockObject(pass *analysis.Pass, expr ast.Expr, name string) bool {
+	var id *ast.Ident
+	switch e := expr.(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+	default:
+		return false
+	}
+	obj := pass.TypesInfo.Uses[id]
+	return obj != nil && obj.Pkg() != nil && strings.HasPrefix(obj.Pkg().Path(), pgxmockPath) && obj.Name() == name
+}
+
+// receiverMock returns the mock the method chain, e.g.
+// mock.ExpectBatch().ExpectQuery("..."), is called on
+func receiverMock(pass *analysis.Pass, call *ast.CallExpr) types.Object {
+	for expr := call.Fun; ; {
+		switch e := expr.(type) {
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.CallExpr:
+			expr = e.Fun
+		case *ast.Ident:
+			return pass.TypesInfo.Uses[e]
+		default:
+			return nil
+		}
+	}
+}
+
+// checkCall checks SQL of expectations and Times arguments
+func checkCall(pass *analysis.Pass, call *ast.CallExpr, literal map[types.Object]bool) {
+	var sql ast.Expr
+	switch mockMethod(pass, call) {
+	case "ExpectExec", "ExpectQuery":
+		if len(call.Args) == 1 {
+			sql = call.Args[0]
+		}
+	case "ExpectPrepare":
+		if len(call.Args) == 2 {
+			sql = call.Args[1]
+		}
+	case "Times":
+		if len(call.Args) == 1 {
+			if tv := pass.TypesInfo.Types[call.Args[0]]; tv.Value != nil && constant.Sign(tv.Value) == 0 {
+				pass.Reportf(call.Args[0].Pos(), "Times(0) means the same as Times(1), use Maybe() for optional calls")
+			}
+		}
+	}
+	if sql == nil || literal[receiverMock(pass, call)] {
+		return
+	}
+	if tv := pass.TypesInfo.Types[sql]; tv.Value != nil && tv.Value.Kind() == constant.String {
+		if hasUnescapedParens(constant.StringVal(tv.Value)) {
+			pass.Reportf(sql.Pos(), "expected SQL contains unescaped parentheses, which are regexp groups never matching literally, use regexp.QuoteMeta or escape them")
+		}
+	}
+}
+
+// hasUnescapedParens reports whether the regexp contains a group, which is
+// likely a literal parenthesis, i.e. neither an alternation nor a special group
+func hasUnescapedParens(re string) bool {
+	var groups []int
+	for i := 0; i < len(re); i++ {
+		switch re[i] {
+		case '\\':
+			i++
+		case '(':
+			groups = append(groups, i)
+		case ')':
+			if len(groups) == 0 {
+				return true
+			}
+			start := groups[len(groups)-1]
+			groups = groups[:len(groups)-1]
+			if group := re[start+1 : i]; !strings.HasPrefix(group, "?") && !strings.Contains(group, "|") {
+				return true
+			}
+		}
+	}
+	return len(groups) > 0
+}
+
+// checkResult checks that the expectation chain, e.g.
+// mock.ExpectExec("...").WithArgs(1), sets the result to return
+func checkResult(pass *analysis.Pass, call *ast.CallExpr) {
+	var methods []string
+	for next := call; next != nil; {
+		sel, ok := next.Fun.(*ast.SelectorExpr)
+		if !ok {
+			break
+		}
+		call = next
+		methods = append(methods, mockMethod(pass, call))
+		next, _ = sel.X.(*ast.CallExpr)
+	}
+	if len(methods) == 0 {
+		return
+	}
+	expect := methods[len(methods)-1]
+	required, ok := results[expect]
+	if !ok {
+		return
+	}
+	for _, method := range methods {
+		for _, r := range required {
+			if method == r {
+				return
+			}
+		}
+	}
+	pass.Reportf(call.Pos(), "%s without %s or WillReturnError fails when matched", expect, required[0])
+}
+
+// mockState tracks the use of the mock created in the checked body
+type mockState struct {
+	pos      token.Pos
+	expected bool
+	verified bool
+}
+
+// checkVerified reports mocks created in the body with expectations, but never
+// verified. Mocks passed elsewhere or created with RequireVerificationOption
+// are not reported, since they may be verified by other code.
+func checkVerified(pass *analysis.Pass, body *ast.BlockStmt) {
+	mocks := createdMocks(pass, body)
+	if len(mocks) == 0 {
+		return
+	}
+	trackMockUses(pass, body, mocks)
+	for obj, m := range mocks {
+		if m.expected && !m.verified {
+			pass.Reportf(m.pos, "expectations of %s are never verified with ExpectationsWereMet", obj.Name())
+		}
+	}
+}
+
+// createdMocks returns mocks assigned the result of NewConn or NewPool
+// in the body, unless created with RequireVerificationOption
+func createdMocks(pass *analysis.Pass, body *ast.BlockStmt) map[types.Object]*mockState {
+	mocks := make(map[types.Object]*mockState)
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false // checked separately
+		case *ast.AssignStmt:
+			if len(n.Rhs) != 1 || len(n.Lhs) == 0 {
+				return true
+			}
+			if call, ok := n.Rhs[0].(*ast.CallExpr); !ok || !createsMock(pass, call) {
+				return true
+			}
+			if id, ok := n.Lhs[0].(*ast.Ident); ok {
+				if obj := pass.TypesInfo.ObjectOf(id); obj != nil {
+					mocks[obj] = &mockState{pos: id.Pos()}
+				}
+			}
+		}
+		return true
+	})
+	return mocks
+}
+
+// createsMock reports whether the call creates the mock,
+// which is not verified by RequireVerificationOption
+func createsMock(pass *analysis.Pass, call *ast.CallExpr) bool {
+	if name := mockMethod(pass, call); name != "NewConn" && name != "NewPool" {
+		return false
+	}
+	for _, arg := range call.Args {
+		if c, ok := arg.(*ast.CallExpr); ok && mockMethod(pass, c) == "RequireVerificationOption" {
+			return false
+		}
+	}
+	return true
+}
+
+// trackMockUses marks mocks setting expectations in the body
+// and mocks verified with ExpectationsWereMet or passed elsewhere
+func trackMockUses(pass *analysis.Pass, body *ast.BlockStmt, mocks map[types.Object]*mockState) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			id, ok := n.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if m := mocks[pass.TypesInfo.Uses[id]]; m != nil {
+				m.expected = m.expected || strings.HasPrefix(n.Sel.Name, "Expect") && n.Sel.Name != "ExpectationsWereMet"
+				m.verified = m.verified || n.Sel.Name == "ExpectationsWereMet"
+			}
+			return false
+		case *ast.Ident:
+			// any other use, e.g. as an argument, may verify the mock
+			if m := mocks[pass.TypesInfo.Uses[n]]; m != nil {
+				m.verified = true
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,14 @@
+// Command pgxmockvet checks the test code for common mistakes using pgxmock.
+// Run it with go vet:
+//
+//	go vet -vettool=$(which pgxmockvet) ./...
+package main
+
+import (
+	"github.com/pashagolub/pgxmock/v4/pgxmockvet"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() {
+	unitchecker.Main(pgxmockvet.Analyzer)
+}
@@ -0,0 +1,63 @@
+package example
+
+import (
+	"testing"
+
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestUnverified(t *testing.T) {
+	mock, _ := pgxmock.NewConn() // want `expectations of mock are never verified with ExpectationsWereMet`
+	mock.ExpectExec("DELETE").WillReturnResult(nil)
+}
+
+func TestVerified(t *testing.T) {
+	mock, _ := pgxmock.NewConn()
+	mock.ExpectExec("DELETE").WillReturnResult(nil)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	guarded, _ := pgxmock.NewConn(pgxmock.RequireVerificationOption(t))
+	guarded.ExpectExec("DELETE").WillReturnResult(nil)
+	passed, _ := pgxmock.NewConn()
+	passed.ExpectExec("DELETE").WillReturnResult(nil)
+	verify(t, passed)
+}
+
+func verify(t *testing.T, mock pgxmock.PgxConnIface) {
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResults(t *testing.T) {
+	mock, _ := pgxmock.NewConn()
+	mock.ExpectExec("DELETE").WithArgs(1)   // want `ExpectExec without WillReturnResult or WillReturnError fails when matched`
+	mock.ExpectQuery("SELECT").WithArgs(1)  // want `ExpectQuery without WillReturnRows or WillReturnError fails when matched`
+	mock.ExpectQuery("SELECT").WillReturnError(nil)
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnPages(nil)
+	mock.ExpectExec("DELETE").WithArgs(1).WillReturnResult(nil).Times(2)
+	e := mock.ExpectExec("UPDATE")
+	e.WillReturnResult(nil)
+	_ = mock.ExpectationsWereMet()
+}
+
+func TestSQL(t *testing.T) {
+	mock, _ := pgxmock.NewConn()
+	mock.ExpectExec("INSERT INTO users(name) VALUES ($1)").WillReturnResult(nil) // want `expected SQL contains unescaped parentheses`
+	mock.ExpectExec(`INSERT INTO users\(name\) VALUES \(\$1\)`).WillReturnResult(nil)
+	mock.ExpectQuery("SELECT (id|name) FROM users").WillReturnRows()
+	mock.ExpectQuery(`SELECT (?i)id FROM users`).WillReturnRows()
+	mock.ExpectPrepare("stmt", "SELECT count(*) FROM users") // want `expected SQL contains unescaped parentheses`
+	mock.ExpectExec("DELETE").WillReturnResult(nil).Times(0) // want `Times\(0\) means the same as Times\(1\)`
+	_ = mock.ExpectationsWereMet()
+
+	regexpMock, _ := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherRegexp))
+	regexpMock.ExpectQuery("SELECT count(*) FROM users").WillReturnRows() // want `expected SQL contains unescaped parentheses`
+	_ = regexpMock.ExpectationsWereMet()
+
+	equalMock, _ := pgxmock.NewConn(pgxmock.QueryMatcherOption(pgxmock.QueryMatcherEqual))
+	equalMock.ExpectQuery("SELECT count(*) FROM users").WillReturnRows()
+	equalMock.ExpectPrepare("stmt", "SELECT count(*) FROM users")
+	_ = equalMock.ExpectationsWereMet()
+}
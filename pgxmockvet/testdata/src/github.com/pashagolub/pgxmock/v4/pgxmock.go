@@ -0,0 +1,50 @@
+// Package pgxmock is the stub of pgxmock API used by the analyzer tests
+package pgxmock
+
+type PgxConnIface interface {
+	ExpectExec(expectedSQL string) *ExpectedExec
+	ExpectQuery(expectedSQL string) *ExpectedQuery
+	ExpectPrepare(expectedStmtName, expectedSQL string) *ExpectedPrepare
+	ExpectationsWereMet() error
+}
+
+type CallModifier interface {
+	Maybe() CallModifier
+	Times(n uint) CallModifier
+}
+
+type commonExpectation struct{}
+
+func (e *commonExpectation) Maybe() CallModifier       { return e }
+func (e *commonExpectation) Times(uint) CallModifier   { return e }
+func (e *commonExpectation) WillReturnError(error)     {}
+func (e *commonExpectation) WillPanic(any)             {}
+func (e *commonExpectation) WillPanicOnCall(uint, any) {}
+
+type ExpectedExec struct{ commonExpectation }
+
+func (e *ExpectedExec) WithArgs(...any) *ExpectedExec      { return e }
+func (e *ExpectedExec) WillReturnResult(any) *ExpectedExec { return e }
+
+type ExpectedQuery struct{ commonExpectation }
+
+func (e *ExpectedQuery) WithArgs(...any) *ExpectedQuery       { return e }
+func (e *ExpectedQuery) WillReturnRows(...any) *ExpectedQuery { return e }
+func (e *ExpectedQuery) WillReturnPages(any) *ExpectedQuery   { return e }
+
+type ExpectedPrepare struct{ commonExpectation }
+
+func NewConn(...func(*pgxmock) error) (PgxConnIface, error) { return nil, nil }
+
+func RequireVerificationOption(any) func(*pgxmock) error { return nil }
+
+type QueryMatcher interface{}
+
+var (
+	QueryMatcherRegexp QueryMatcher
+	QueryMatcherEqual  QueryMatcher
+)
+
+func QueryMatcherOption(QueryMatcher) func(*pgxmock) error { return nil }
+
+type pgxmock struct{}
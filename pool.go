@@ -0,0 +1,156 @@
+package pgxmock
+
+import (
+	"context"
+	"sync/atomic"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// PoolHooks are the pgxpool.Config lifecycle hooks invoked by the pool mock
+// for connections acquired with AcquireConn, see PoolHooksOption. Since the
+// mock has no *pgx.Conn, hooks get the mocked connection instead, so the
+// connection initialization logic, e.g. SET ROLE or search_path, should be
+// written against an interface satisfied by both *pgx.Conn and PgxConnIface.
+type PoolHooks struct {
+	// BeforeConnect is called before a new connection is made
+	BeforeConnect func(context.Context, *pgx.ConnConfig) error
+	// AfterConnect is called after a new connection is made, the error
+	// fails the acquisition and the connection is discarded
+	AfterConnect func(context.Context, PgxConnIface) error
+	// BeforeAcquire is called before an idle connection is acquired,
+	// if false is returned the connection is closed and another one is used
+	BeforeAcquire func(context.Context, PgxConnIface) bool
+	// AfterRelease is called after a connection is released,
+	// if false is returned the connection is closed instead of reused
+	AfterRelease func(PgxConnIface) bool
+	// BeforeClose is called right before a connection is closed
+	BeforeClose func(PgxConnIface)
+}
+
+// pgxmockPooledConn is the mocked connection acquired from the pool. It
+// shares the mock of the pool, so expectations declared on the pool after
+// the acquisition are matched by the connection and vice versa.
+type pgxmockPooledConn struct {
+	*pgxmock
+	pool     *pgxmockPool
+	released atomic.Bool
+}
+
+func (c *pgxmockPooledConn) Config() *pgx.ConnConfig {
+	return &pgx.ConnConfig{}
+}
+
+// Scope returns the child mock for the subtest, see pgxmockConn.Scope
+func (c *pgxmockPooledConn) Scope(t TestingT) PgxConnIface {
+	child := &pgxmockConn{pgxmock: c.child()}
+	child.verifyOnCleanup(t)
+	return child
+}
+
+// CloneWithExpectations returns the independent mock having the same
+// expectations as the pool, see pgxmockConn.CloneWithExpectations
+func (c *pgxmockPooledConn) CloneWithExpectations() PgxConnIface {
+	clone := &pgxmockConn{pgxmock: c.child()}
+	c.cloneExpectationsTo(&clone.pgxmock)
+	return clone
+}
+
+// AcquireConn is similar to Acquire but returns proper mocking interface.
+// If the pool size is limited with MaxConnsOption, it blocks until
+// a previously acquired connection is released or ctx is done.
+// Released connections are reused, and PoolHooks are invoked the same
+// way pgxpool does.
+func (p *pgxmockPool) AcquireConn(ctx context.Context) (PgxPooledConnIface, error) {
+	if p.conns != nil {
+		select {
+		case p.conns <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	conn, err := p.acquire(ctx)
+	if err != nil {
+		p.releaseSlot()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// acquire returns the idle connection accepted by
+// the BeforeAcquire hook or connects the new one
+func (p *pgxmockPool) acquire(ctx context.Context) (*pgxmockPooledConn, error) {
+	for {
+		p.mu.Lock()
+		var conn *pgxmockPooledConn
+		if n := len(p.idle); n > 0 {
+			conn, p.idle = p.idle[n-1], p.idle[:n-1]
+		}
+		p.mu.Unlock()
+		if conn == nil {
+			return p.connect(ctx)
+		}
+		if p.poolHooks.BeforeAcquire == nil || p.poolHooks.BeforeAcquire(ctx, conn) {
+			conn.released.Store(false)
+			return conn, nil
+		}
+		p.closeConn(conn)
+	}
+}
+
+// connect returns the new connection initialized by the connect hooks
+func (p *pgxmockPool) connect(ctx context.Context) (*pgxmockPooledConn, error) {
+	if p.poolHooks.BeforeConnect != nil {
+		if err := p.poolHooks.BeforeConnect(ctx, &pgx.ConnConfig{}); err != nil {
+			return nil, err
+		}
+	}
+	conn := &pgxmockPooledConn{pgxmock: &p.pgxmock, pool: p}
+	if p.poolHooks.AfterConnect != nil {
+		if err := p.poolHooks.AfterConnect(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// closeConn discards the connection
+func (p *pgxmockPool) closeConn(conn *pgxmockPooledConn) {
+	if p.poolHooks.BeforeClose != nil {
+		p.poolHooks.BeforeClose(conn)
+	}
+}
+
+// closeIdle discards all released connections
+func (p *pgxmockPool) closeIdle() {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+	for _, conn := range idle {
+		p.closeConn(conn)
+	}
+}
+
+// releaseSlot frees the slot of the pool limited with MaxConnsOption
+func (p *pgxmockPool) releaseSlot() {
+	if p.conns != nil {
+		<-p.conns
+	}
+}
+
+// Release returns the connection to the pool. Subsequent calls are ignored.
+func (c *pgxmockPooledConn) Release() {
+	if c.released.Swap(true) {
+		return
+	}
+	p := c.pool
+	if p.poolHooks.AfterRelease == nil || p.poolHooks.AfterRelease(c) {
+		p.mu.Lock()
+		p.idle = append(p.idle, c)
+		p.mu.Unlock()
+	} else {
+		p.closeConn(c)
+	}
+	p.releaseSlot()
+}
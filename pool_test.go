@@ -0,0 +1,99 @@
+package pgxmock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoolHooksOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	var events []string
+	acceptIdle := true
+	mock, err := NewPool(PoolHooksOption(PoolHooks{
+		BeforeConnect: func(context.Context, *pgx.ConnConfig) error {
+			events = append(events, "connect")
+			return nil
+		},
+		AfterConnect: func(ctx context.Context, conn PgxConnIface) error {
+			_, err := conn.Exec(ctx, "SET ROLE app")
+			return err
+		},
+		BeforeAcquire: func(context.Context, PgxConnIface) bool {
+			events = append(events, "acquire")
+			return acceptIdle
+		},
+		AfterRelease: func(PgxConnIface) bool {
+			events = append(events, "release")
+			return true
+		},
+		BeforeClose: func(PgxConnIface) {
+			events = append(events, "close")
+		},
+	}))
+	a.NoError(err)
+	ctx := context.Background()
+
+	mock.ExpectExec("SET ROLE app").WillReturnResult(NewResult("SET", 0))
+	conn, err := mock.AcquireConn(ctx)
+	a.NoError(err)
+	conn.Release()
+	again, err := mock.AcquireConn(ctx)
+	a.NoError(err)
+	a.Same(conn, again, "released connection must be reused")
+	again.Release()
+
+	acceptIdle = false
+	mock.ExpectExec("SET ROLE app").WillReturnResult(NewResult("SET", 0))
+	fresh, err := mock.AcquireConn(ctx)
+	a.NoError(err)
+	a.NotSame(conn, fresh)
+	fresh.Release()
+
+	mock.Close()
+	a.NoError(mock.ExpectationsWereMet())
+	a.Equal([]string{"connect", "release", "acquire", "release", "acquire", "close", "connect", "release", "close"}, events)
+}
+
+func TestPoolHooksAfterConnectError(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, err := NewPool(MaxConnsOption(1), PoolHooksOption(PoolHooks{
+		AfterConnect: func(ctx context.Context, conn PgxConnIface) error {
+			_, err := conn.Exec(ctx, "SET search_path TO tenant")
+			return err
+		},
+	}))
+	a.NoError(err)
+	mock.ExpectExec("SET search_path").WillReturnError(errors.New("schema does not exist"))
+	_, err = mock.AcquireConn(context.Background())
+	a.EqualError(err, "schema does not exist")
+
+	mock.ExpectExec("SET search_path").WillReturnResult(NewResult("SET", 0))
+	conn, err := mock.AcquireConn(context.Background())
+	a.NoError(err, "failed acquisition must free the pool slot")
+	conn.Release()
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestAcquireConnSharesMock(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	pool, _ := NewPool(VerifyTxCompletionOption(true))
+	conn, err := pool.AcquireConn(ctx)
+	a.NoError(err)
+	defer conn.Release()
+
+	// expectations declared on the pool after the acquisition are matched
+	pool.ExpectExec("UPDATE").WillReturnResult(NewResult("UPDATE", 1))
+	pool.ExpectBegin()
+	_, err = conn.Exec(ctx, "UPDATE t SET x = 1")
+	a.NoError(err)
+	_, err = conn.Begin(ctx)
+	a.NoError(err)
+	a.ErrorContains(pool.ExpectationsWereMet(), "1 transactions were neither committed nor rolled back")
+}
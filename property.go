@@ -0,0 +1,130 @@
+package pgxmock
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// QuickSQL is the random SQL statement for property-based tests of custom
+// QueryMatchers. It implements the testing/quick Generator interface and
+// produces statements with keywords, quoted identifiers and literals,
+// placeholders, unicode and regular expression metacharacters, e.g.
+//
+//	quick.Check(func(sql pgxmock.QuickSQL) bool {
+//		return myMatcher.Match(string(sql), string(sql)) == nil
+//	}, nil)
+//
+// For other frameworks, e.g. rapid, the Generate method may be used with
+// the random source seeded by the framework.
+type QuickSQL string
+
+var quickSQLTokens = []string{
+	"SELECT", "INSERT INTO", "UPDATE", "DELETE FROM", "WHERE", "VALUES", "SET", "AND", "OR",
+	"users", "\"Users\"", "name", "id", "*", ",", "(", ")", "=", "<>", "$1", "$2", "$10",
+	"'john'", "'it''s'", "'ünïcødé'", "NULL", "42", "3.14", "--", "\n", "\t", ";",
+	".", "+", "?", "[", "]", "{", "}", "|", "^", "\\",
+}
+
+// Generate returns the random QuickSQL value of at most size tokens
+func (QuickSQL) Generate(rnd *rand.Rand, size int) reflect.Value {
+	tokens := make([]string, rnd.Intn(size+1))
+	for i := range tokens {
+		tokens[i] = quickSQLTokens[rnd.Intn(len(quickSQLTokens))]
+	}
+	return reflect.ValueOf(QuickSQL(strings.Join(tokens, " ")))
+}
+
+// Shrink returns simpler statements to narrow the failing case down,
+// i.e. the statement without each of its tokens
+func (s QuickSQL) Shrink() []QuickSQL {
+	tokens := strings.Split(string(s), " ")
+	if string(s) == "" || len(tokens) == 0 {
+		return nil
+	}
+	shrunk := make([]QuickSQL, 0, len(tokens))
+	for i := range tokens {
+		rest := append(append([]string(nil), tokens[:i]...), tokens[i+1:]...)
+		shrunk = append(shrunk, QuickSQL(strings.Join(rest, " ")))
+	}
+	return shrunk
+}
+
+// QuickArgs are the random query arguments for property-based tests of
+// custom Argument matchers. It implements the testing/quick Generator
+// interface and produces nils, numbers including extreme ones, strings,
+// byte slices, booleans, times and pgtype values.
+type QuickArgs []any
+
+var quickArgs = []func(rnd *rand.Rand) any{
+	func(*rand.Rand) any { return nil },
+	func(rnd *rand.Rand) any { return rnd.Int() - rnd.Int() },
+	func(rnd *rand.Rand) any { return []int64{math.MinInt64, math.MaxInt64, 0}[rnd.Intn(3)] },
+	func(rnd *rand.Rand) any { return int32(rnd.Int31()) },
+	func(rnd *rand.Rand) any { return rnd.NormFloat64() },
+	func(rnd *rand.Rand) any {
+		return []float64{math.Inf(1), math.Inf(-1), math.SmallestNonzeroFloat64}[rnd.Intn(3)]
+	},
+	func(rnd *rand.Rand) any { return string(QuickSQL("").Generate(rnd, 3).Interface().(QuickSQL)) },
+	func(rnd *rand.Rand) any {
+		b := make([]byte, rnd.Intn(8))
+		_, _ = rnd.Read(b)
+		return b
+	},
+	func(rnd *rand.Rand) any { return rnd.Intn(2) == 1 },
+	func(rnd *rand.Rand) any { return time.Unix(rnd.Int63n(1<<33), rnd.Int63n(int64(time.Second))).UTC() },
+	func(rnd *rand.Rand) any { return pgtype.Text{String: fmt.Sprint(rnd.Int()), Valid: rnd.Intn(2) == 1} },
+	func(rnd *rand.Rand) any { return pgtype.Int8{Int64: rnd.Int63(), Valid: rnd.Intn(2) == 1} },
+}
+
+// Generate returns the random QuickArgs value of at most size arguments
+func (QuickArgs) Generate(rnd *rand.Rand, size int) reflect.Value {
+	args := make(QuickArgs, rnd.Intn(size+1))
+	for i := range args {
+		args[i] = quickArgs[rnd.Intn(len(quickArgs))](rnd)
+	}
+	return reflect.ValueOf(args)
+}
+
+// Shrink returns simpler arguments to narrow the failing case down,
+// i.e. the arguments without each of them and with each of them set to nil
+func (a QuickArgs) Shrink() []QuickArgs {
+	var shrunk []QuickArgs
+	for i := range a {
+		shrunk = append(shrunk, append(append(QuickArgs(nil), a[:i]...), a[i+1:]...))
+		if a[i] != nil {
+			nilled := append(QuickArgs(nil), a...)
+			nilled[i] = nil
+			shrunk = append(shrunk, nilled)
+		}
+	}
+	return shrunk
+}
+
+// QuickRows are the random Rows for property-based tests of the code
+// scanning results. It implements the testing/quick Generator interface
+// and produces the columns of common types filled with FakeByType values.
+type QuickRows struct {
+	*Rows
+}
+
+var quickRowsOIDs = []uint32{
+	pgtype.BoolOID, pgtype.Int2OID, pgtype.Int4OID, pgtype.Int8OID, pgtype.Float8OID,
+	pgtype.TextOID, pgtype.TimestamptzOID, pgtype.ByteaOID, pgtype.UUIDOID,
+}
+
+// Generate returns the random QuickRows value of at most size rows
+func (QuickRows) Generate(rnd *rand.Rand, size int) reflect.Value {
+	columns := make([]pgconn.FieldDescription, 1+rnd.Intn(4))
+	for i := range columns {
+		columns[i] = pgconn.FieldDescription{Name: fmt.Sprintf("col%d", i), DataTypeOID: quickRowsOIDs[rnd.Intn(len(quickRowsOIDs))]}
+	}
+	rows := NewRowsGenerated(columns, rnd.Intn(size+1), FakeByType, WithSeed(rnd.Uint64()))
+	return reflect.ValueOf(QuickRows{rows})
+}
@@ -0,0 +1,55 @@
+package pgxmock
+
+import (
+	"regexp"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuickSQL(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.NoError(quick.Check(func(sql QuickSQL) bool {
+		return QueryMatcherEqual.Match(string(sql), string(sql)) == nil &&
+			QueryMatcherRegexp.Match(regexp.QuoteMeta(string(sql)), string(sql)) == nil
+	}, nil))
+	a.Equal([]QuickSQL{"id", "SELECT"}, QuickSQL("SELECT id").Shrink())
+	a.Empty(QuickSQL("").Shrink())
+}
+
+func TestQuickArgs(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.NoError(quick.Check(func(args QuickArgs) bool {
+		for _, arg := range args {
+			if !AnyArg().Match(arg) {
+				return false
+			}
+		}
+		return true
+	}, nil))
+	a.Equal([]QuickArgs{{nil}, {nil, nil}, {1}}, QuickArgs{1, nil}.Shrink())
+}
+
+func TestQuickRows(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.NoError(quick.Check(func(rows QuickRows) bool {
+		mock, _ := NewConn()
+		mock.ExpectQuery("SELECT").WillReturnRows(rows.Rows)
+		rs, err := mock.Query(ctx, "SELECT")
+		if err != nil {
+			return false
+		}
+		defer rs.Close()
+		for rs.Next() {
+			values, err := rs.Values()
+			if err != nil || len(values) != len(rs.FieldDescriptions()) {
+				return false
+			}
+		}
+		return rs.Err() == nil
+	}, nil))
+}
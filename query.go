@@ -41,19 +41,38 @@ func (f QueryMatcherFunc) Match(expectedSQL, actualSQL string) error {
 
 // QueryMatcherRegexp is the default SQL query matcher
 // used by pgxmock. It parses expectedSQL to a regular
-// expression and attempts to match actualSQL.
-var QueryMatcherRegexp QueryMatcher = QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
-	expect := stripQuery(expectedSQL)
-	actual := stripQuery(actualSQL)
-	re, err := regexp.Compile(expect)
+// expression and attempts to match actualSQL. The mock
+// compiles the expected SQL once, when the expectation
+// is declared, and returns the compilation error when
+// the call is matched.
+var QueryMatcherRegexp QueryMatcher = regexpMatcher{}
+
+// regexpMatcher is the type of QueryMatcherRegexp, so the mock
+// may recognize it to compile the expected SQL at declaration
+type regexpMatcher struct{}
+
+// Match implements the QueryMatcher
+func (regexpMatcher) Match(expectedSQL, actualSQL string) error {
+	re, err := compileQuery(expectedSQL)
 	if err != nil {
 		return err
 	}
+	return matchRegexp(re, actualSQL)
+}
+
+// compileQuery compiles the expected SQL without whitespace
+func compileQuery(expectedSQL string) (*regexp.Regexp, error) {
+	return regexp.Compile(stripQuery(expectedSQL))
+}
+
+// matchRegexp matches the actual SQL without whitespace against the compiled expected SQL
+func matchRegexp(re *regexp.Regexp, actualSQL string) error {
+	actual := stripQuery(actualSQL)
 	if !re.MatchString(actual) {
 		return fmt.Errorf(`could not match actual sql: "%s" with expected regexp "%s"`, actual, re.String())
 	}
 	return nil
-})
+}
 
 // QueryMatcherEqual is the SQL query matcher
 // which simply tries a case sensitive match of
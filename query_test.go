@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func ExampleQueryMatcher() {
@@ -91,6 +93,30 @@ func TestQueryMatcherRegexp(t *testing.T) {
 	}
 }
 
+func TestQueryMatcherRegexpCompiledAtDeclaration(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	e := mock.ExpectQuery("SELECT (.+) FROM users").WillReturnRows(NewRows([]string{"name"}))
+	a.Len(e.compiledSQL, 1)
+	a.Len(mock.ExpectQueryAnyOf("SELECT 1", "SELECT 2").compiledSQL, 2)
+	a.NotPanics(func() { mock.ExpectBatch().ExpectQuery("SELECT count(*)") })
+
+	mock, _ = NewConn()
+	mock.ExpectExec("?cached")
+	_, err := mock.Exec(context.Background(), "cached")
+	a.ErrorContains(err, "error parsing regexp: missing argument to repetition operator: `?`", "reported when the call is matched")
+
+	mock, _ = NewConn(QueryMatcherOption(QueryMatcherEqual))
+	a.Nil(mock.ExpectExec("?cached").compiledSQL, "patterns are compiled for QueryMatcherRegexp only")
+}
+
+func BenchmarkQueryMatcherRegexp(b *testing.B) {
+	for range b.N {
+		_ = QueryMatcherRegexp.Match("SELECT (.+) FROM users WHERE id = \\$1", "SELECT name, email FROM users WHERE id = $1")
+	}
+}
+
 func TestQueryMatcherEqual(t *testing.T) {
 	type testCase struct {
 		expected string
@@ -0,0 +1,47 @@
+package pgxmock
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	pgconn "github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	reWriteStmt = regexp.MustCompile(`(?is)^\s*(INSERT|UPDATE|DELETE|MERGE|TRUNCATE|CREATE|ALTER|DROP|GRANT|REVOKE|COMMENT|REINDEX|VACUUM|CLUSTER|REFRESH|COPY\s+\S+\s+FROM)\b`)
+	reWithWrite = regexp.MustCompile(`(?is)^\s*WITH\b.*\b(INSERT|UPDATE|DELETE|MERGE)\b`)
+)
+
+// writeStatement returns the command of the statement writing data
+// or changing the schema, or an empty string for read-only statements
+func writeStatement(sql string) string {
+	if m := reWriteStmt.FindStringSubmatch(sql); m != nil {
+		if cmd := strings.ToUpper(m[1]); !strings.HasPrefix(cmd, "COPY") {
+			return cmd
+		}
+		return "COPY FROM"
+	}
+	if m := reWithWrite.FindStringSubmatch(sql); m != nil {
+		return strings.ToUpper(m[1])
+	}
+	return ""
+}
+
+// inReadOnly reports whether the mock or the transaction tx is read-only,
+// calls issued outside of transactions have nil tx
+func (c *pgxmock) inReadOnly(tx *pgxmockTx) bool {
+	return c.readOnly || tx != nil && tx.readOnly
+}
+
+// writeAllowed returns an error if the command is executed in read-only mode
+func (c *pgxmock) writeAllowed(tx *pgxmockTx, cmd string) error {
+	if cmd == "" || !c.inReadOnly(tx) {
+		return nil
+	}
+	return &pgconn.PgError{
+		Severity: "ERROR",
+		Code:     "25006",
+		Message:  fmt.Sprintf("cannot execute %s in a read-only transaction", cmd),
+	}
+}
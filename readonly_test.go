@@ -0,0 +1,95 @@
+package pgxmock
+
+import (
+	"errors"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteStatement(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	for sql, cmd := range map[string]string{
+		"SELECT * FROM users":                                    "",
+		"  insert into users VALUES (1)":                         "INSERT",
+		"WITH d AS (DELETE FROM users RETURNING *) SELECT 1":     "DELETE",
+		"WITH u AS (SELECT 1) SELECT * FROM u":                   "",
+		"CREATE TABLE t (id int)":                                "CREATE",
+		"COPY users FROM STDIN":                                  "COPY FROM",
+		"COPY users TO STDOUT":                                   "",
+		"SELECT updated_at FROM users WHERE deleted IS NOT NULL": "",
+	} {
+		a.Equal(cmd, writeStatement(sql), sql)
+	}
+}
+
+func TestReadOnlyOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewPool(ReadOnlyOption(true))
+	mock.ExpectQuery("SELECT").WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+
+	_, err := mock.Exec(ctx, "UPDATE users SET name = $1", "john")
+	var pgErr *pgconn.PgError
+	a.True(errors.As(err, &pgErr))
+	a.Equal("25006", pgErr.Code)
+	a.Equal("cannot execute UPDATE in a read-only transaction", pgErr.Message)
+
+	_, err = mock.CopyFrom(ctx, pgx.Identifier{"users"}, []string{"id"}, pgx.CopyFromRows(nil))
+	a.ErrorContains(err, "cannot execute COPY FROM")
+
+	rows, err := mock.Query(ctx, "SELECT id FROM users")
+	a.NoError(err)
+	rows.Close()
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestReadOnlyTransaction(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	mock.ExpectBeginTx(pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectRollback()
+	mock.ExpectExec("DELETE").WillReturnResult(NewResult("DELETE", 1))
+
+	tx, err := mock.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	a.NoError(err)
+	_, err = tx.Exec(ctx, "DELETE FROM users")
+	a.ErrorContains(err, "cannot execute DELETE in a read-only transaction")
+	savepoint, _ := tx.Begin(ctx)
+	_, err = savepoint.Exec(ctx, "DELETE FROM users")
+	a.ErrorContains(err, "read-only transaction", "savepoints are read-only too")
+	a.NoError(savepoint.Rollback(ctx))
+	a.NoError(tx.Rollback(ctx))
+
+	_, err = mock.Exec(ctx, "DELETE FROM users")
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestReadOnlyTransactionScope(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewPool()
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectBeginTx(pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE").WillReturnResult(NewResult("DELETE", 1)).Times(2)
+
+	readOnly, err := mock.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	a.NoError(err)
+	tx, err := mock.Begin(ctx)
+	a.NoError(err)
+	_, err = mock.Exec(ctx, "DELETE FROM users")
+	a.NoError(err, "the pool is not in the read-only transaction")
+	_, err = tx.Exec(ctx, "DELETE FROM users")
+	a.NoError(err, "the independent transaction is not read-only")
+	_, err = readOnly.Exec(ctx, "DELETE FROM users")
+	a.ErrorContains(err, "read-only transaction")
+	a.NoError(mock.ExpectationsWereMet())
+}
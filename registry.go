@@ -0,0 +1,134 @@
+package pgxmock
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Registry keeps named mocks, so tests orchestrating several mocked
+// databases, e.g. primary, replica and analytics, may manage and
+// verify them uniformly.
+type Registry struct {
+	sync.Mutex
+	mocks map[string]Expecter
+}
+
+// NewRegistry creates an empty registry of named mocks. Prefer own
+// registries over the package level one for tests running in parallel.
+func NewRegistry() *Registry {
+	return &Registry{mocks: make(map[string]Expecter)}
+}
+
+// Register makes the mock available by the name.
+// If Register is called twice with the same name it panics.
+func (r *Registry) Register(name string, mock Expecter) {
+	r.Lock()
+	defer r.Unlock()
+	if mock == nil {
+		panic("pgxmock: Register mock is nil")
+	}
+	if _, dup := r.mocks[name]; dup {
+		panic("pgxmock: Register called twice for mock " + name)
+	}
+	r.mocks[name] = mock
+}
+
+// Unregister removes the mock registered by the name, if any.
+func (r *Registry) Unregister(name string) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.mocks, name)
+}
+
+// Lookup returns the mock registered by the name.
+func (r *Registry) Lookup(name string) (Expecter, bool) {
+	r.Lock()
+	defer r.Unlock()
+	mock, ok := r.mocks[name]
+	return mock, ok
+}
+
+// Conn returns the connection mock registered by the name.
+// Panics if there is no such connection mock.
+func (r *Registry) Conn(name string) PgxConnIface {
+	mock, _ := r.Lookup(name)
+	conn, ok := mock.(PgxConnIface)
+	if !ok {
+		panic("pgxmock: no connection mock registered as " + name)
+	}
+	return conn
+}
+
+// Pool returns the pool mock registered by the name.
+// Panics if there is no such pool mock.
+func (r *Registry) Pool(name string) PgxPoolIface {
+	mock, _ := r.Lookup(name)
+	pool, ok := mock.(PgxPoolIface)
+	if !ok {
+		panic("pgxmock: no pool mock registered as " + name)
+	}
+	return pool
+}
+
+// Names returns the sorted names of registered mocks.
+func (r *Registry) Names() []string {
+	r.Lock()
+	defer r.Unlock()
+	names := make([]string, 0, len(r.mocks))
+	for name := range r.mocks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExpectationsWereMet checks whether all expectations of every registered
+// mock were met. The errors of all mocks are joined and prefixed by names.
+func (r *Registry) ExpectationsWereMet() error {
+	var errs []error
+	for _, name := range r.Names() {
+		mock, _ := r.Lookup(name)
+		if err := mock.ExpectationsWereMet(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var defaultRegistry = NewRegistry()
+
+// Register makes the mock available by the name in the package level registry.
+// If Register is called twice with the same name it panics.
+func Register(name string, mock Expecter) {
+	defaultRegistry.Register(name, mock)
+}
+
+// Unregister removes the mock from the package level registry.
+func Unregister(name string) {
+	defaultRegistry.Unregister(name)
+}
+
+// Lookup returns the mock registered by the name in the package level registry.
+func Lookup(name string) (Expecter, bool) {
+	return defaultRegistry.Lookup(name)
+}
+
+// LookupConn returns the connection mock registered by the name in the
+// package level registry. Panics if there is no such connection mock.
+func LookupConn(name string) PgxConnIface {
+	return defaultRegistry.Conn(name)
+}
+
+// LookupPool returns the pool mock registered by the name in the
+// package level registry. Panics if there is no such pool mock.
+func LookupPool(name string) PgxPoolIface {
+	return defaultRegistry.Pool(name)
+}
+
+// RegisteredExpectationsWereMet checks whether all expectations of
+// every mock in the package level registry were met.
+func RegisteredExpectationsWereMet() error {
+	return defaultRegistry.ExpectationsWereMet()
+}
@@ -0,0 +1,53 @@
+package pgxmock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	r := NewRegistry()
+	primary, _ := NewPool()
+	analytics, _ := NewConn()
+	r.Register("primary", primary)
+	r.Register("analytics", analytics)
+	a.Panics(func() { r.Register("primary", primary) })
+	a.Panics(func() { r.Register("nil", nil) })
+	a.Equal([]string{"analytics", "primary"}, r.Names())
+
+	a.Equal(primary, r.Pool("primary"))
+	a.Equal(analytics, r.Conn("analytics"))
+	a.Panics(func() { r.Conn("primary") })
+	a.Panics(func() { r.Pool("replica") })
+
+	r.Pool("primary").ExpectPing()
+	r.Conn("analytics").ExpectPing()
+	a.NoError(primary.Ping(ctx))
+	err := r.ExpectationsWereMet()
+	a.ErrorContains(err, "analytics: there is a remaining expectation")
+	a.NotContains(err.Error(), "primary:")
+
+	r.Unregister("analytics")
+	_, ok := r.Lookup("analytics")
+	a.False(ok)
+	a.NoError(r.ExpectationsWereMet())
+}
+
+func TestPackageRegistry(t *testing.T) {
+	a := assert.New(t)
+	replica, _ := NewPool()
+	Register("replica", replica)
+	defer Unregister("replica")
+	mock, ok := Lookup("replica")
+	a.True(ok)
+	a.Equal(replica, mock)
+	a.Equal(replica, LookupPool("replica"))
+	a.Panics(func() { LookupConn("replica") })
+	replica.ExpectPing()
+	a.Error(RegisteredExpectationsWereMet())
+	a.NoError(replica.Ping(ctx))
+	a.NoError(RegisteredExpectationsWereMet())
+}
@@ -0,0 +1,186 @@
+package pgxmock
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// reportCase is the outcome of a single expectation, unexpected
+// call or violation included into the JUnit and HTML reports
+type reportCase struct {
+	Name       string
+	DeclaredAt string
+	Calls      string // made of planned calls, e.g. "2/3"
+	Duration   time.Duration
+	Status     string // "passed", "failed" or "skipped"
+	Failure    string
+	Details    string // the expectation description of the failure
+	Matched    []string
+}
+
+// reportCases returns outcomes of all expectations in the order they were
+// declared followed by failed calls matching no expectation and violations
+func (c *pgxmock) reportCases() []reportCase {
+	c.callLog.Lock()
+	calls := append([]loggedCall(nil), c.callLog.calls...)
+	c.callLog.Unlock()
+	durations := make(map[string]time.Duration)
+	matched := make(map[string][]string)
+	for _, lc := range calls {
+		if lc.Expectation != "" {
+			durations[lc.Expectation] += lc.Duration
+			matched[lc.Expectation] = append(matched[lc.Expectation], strings.TrimSpace(lc.Method+" "+lc.SQL))
+		}
+	}
+
+	expectations := c.declared()
+	cases := make([]reportCase, 0, len(expectations))
+	for _, e := range expectations {
+		e.Lock()
+		made, planned := e.calls()
+		fulfilled := e.fulfilled()
+		e.Unlock()
+		label := c.expectationLabel(e)
+		rc := reportCase{
+			Name:       label,
+			DeclaredAt: e.declaredAt(),
+			Calls:      fmt.Sprintf("%d/%d", made, planned),
+			Duration:   durations[label],
+			Status:     "passed",
+			Matched:    matched[label],
+		}
+		if sql := expectationSQL(e); sql != "" {
+			rc.Name += ": " + stripQuery(sql)
+		}
+		switch {
+		case fulfilled:
+		case !e.required():
+			rc.Status = "skipped"
+		default:
+			rc.Status = "failed"
+			rc.Failure = fmt.Sprintf("expected %d calls, but got %d", planned, made)
+			rc.Details = e.String()
+		}
+		cases = append(cases, rc)
+	}
+	for _, lc := range calls {
+		if lc.Expectation == "" && lc.Error != "" {
+			cases = append(cases, reportCase{
+				Name:     strings.TrimSpace("call to " + lc.Method + " " + lc.SQL),
+				Duration: lc.Duration,
+				Status:   "failed",
+				Failure:  lc.Error,
+			})
+		}
+	}
+	for i, v := range c.violationList() {
+		cases = append(cases, reportCase{Name: fmt.Sprintf("violation #%d", i), Status: "failed", Failure: v})
+	}
+	return cases
+}
+
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// seconds formats the duration in seconds as JUnit reports do
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// WriteJUnitReport writes the JUnit XML test suite with every
+// expectation as a test case, see Expecter.WriteJUnitReport
+func (c *pgxmock) WriteJUnitReport(w io.Writer, suite string) error {
+	s := junitSuite{Name: suite}
+	var total time.Duration
+	for _, rc := range c.reportCases() {
+		jc := junitCase{
+			Name:      rc.Name,
+			Classname: suite,
+			Time:      seconds(rc.Duration),
+			File:      rc.DeclaredAt,
+			SystemOut: strings.Join(rc.Matched, "\n"),
+		}
+		switch rc.Status {
+		case "failed":
+			jc.Failure = &junitFailure{Message: rc.Failure, Text: rc.Details}
+			s.Failures++
+		case "skipped":
+			jc.Skipped = &struct{}{}
+			s.Skipped++
+		}
+		total += rc.Duration
+		s.Cases = append(s.Cases, jc)
+	}
+	s.Tests = len(s.Cases)
+	s.Time = seconds(total)
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+.passed { background: #e6ffe6; }
+.failed { background: #ffe6e6; }
+.skipped { background: #f2f2f2; }
+pre { margin: 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+<tr><th>Expectation</th><th>Declared at</th><th>Calls</th><th>Duration</th><th>Status</th><th>Details</th></tr>
+{{range .Cases}}<tr class="{{.Status}}"><td>{{.Name}}</td><td>{{.DeclaredAt}}</td><td>{{.Calls}}</td><td>{{.Duration}}</td><td>{{.Status}}</td><td>{{.Failure}}{{if .Details}}<pre>{{.Details}}</pre>{{end}}{{range .Matched}}<div>{{.}}</div>{{end}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// WriteHTMLReport writes the HTML page with the table of
+// all expectations outcomes, see Expecter.WriteHTMLReport
+func (c *pgxmock) WriteHTMLReport(w io.Writer, title string) error {
+	return htmlReport.Execute(w, struct {
+		Title string
+		Cases []reportCase
+	}{title, c.reportCases()})
+}
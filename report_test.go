@@ -0,0 +1,64 @@
+package pgxmock
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func reportMock(t *testing.T) PgxConnIface {
+	mock, err := NewConn()
+	assert.NoError(t, err)
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectExec("INSERT INTO users").WillReturnResult(NewResult("INSERT", 1))
+	mock.ExpectQuery("SELECT <name>").WillReturnRows(NewRows([]string{"name"})).Times(2)
+	mock.ExpectPing().Maybe()
+	_, _ = mock.Exec(context.Background(), "INSERT INTO users(name) VALUES ('john')")
+	_, _ = mock.Query(context.Background(), "SELECT <name>")
+	_, _ = mock.Exec(context.Background(), "DELETE FROM users")
+	return mock
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock := reportMock(t)
+	var buf bytes.Buffer
+	a.NoError(mock.WriteJUnitReport(&buf, "TestUsers"))
+	a.True(strings.HasPrefix(buf.String(), xml.Header))
+
+	var suite junitSuite
+	a.NoError(xml.Unmarshal(buf.Bytes(), &suite))
+	a.Equal("TestUsers", suite.Name)
+	a.Equal(4, suite.Tests)
+	a.Equal(2, suite.Failures)
+	a.Equal(1, suite.Skipped)
+	a.Equal("ExpectedExec #0: INSERT INTO users", suite.Cases[0].Name)
+	a.Nil(suite.Cases[0].Failure)
+	a.Equal("Exec() INSERT INTO users(name) VALUES ('john')", suite.Cases[0].SystemOut)
+	a.Contains(suite.Cases[0].File, "report_test.go:")
+	a.Equal("expected 2 calls, but got 1", suite.Cases[1].Failure.Message)
+	a.Contains(suite.Cases[1].Failure.Text, "ExpectedQuery =>")
+	a.NotNil(suite.Cases[2].Skipped)
+	a.Equal("call to Exec() DELETE FROM users", suite.Cases[3].Name)
+	a.Contains(suite.Cases[3].Failure.Message, "was not expected")
+}
+
+func TestWriteHTMLReport(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock := reportMock(t)
+	var buf bytes.Buffer
+	a.NoError(mock.WriteHTMLReport(&buf, "Users <suite>"))
+	html := buf.String()
+	a.Contains(html, "<title>Users &lt;suite&gt;</title>")
+	a.Contains(html, `<tr class="passed"><td>ExpectedExec #0: INSERT INTO users</td>`)
+	a.Contains(html, `<td>ExpectedQuery #1: SELECT &lt;name&gt;</td>`)
+	a.Contains(html, "<td>1/2</td>")
+	a.Equal(2, strings.Count(html, `<tr class="failed">`))
+	a.Equal(1, strings.Count(html, `<tr class="skipped">`))
+}
@@ -11,3 +11,26 @@ import (
 func NewResult(op string, rowsAffected int64) pgconn.CommandTag {
 	return pgconn.NewCommandTag(fmt.Sprintf("%s %d", op, rowsAffected))
 }
+
+// NewCachedPlanError creates the error PostgreSQL returns when the
+// result type of a cached (prepared) statement was changed, e.g. by
+// ALTER TABLE, so the plan cache invalidation path may be tested.
+func NewCachedPlanError() *pgconn.PgError {
+	return &pgconn.PgError{
+		Severity: "ERROR",
+		Code:     "0A000",
+		Message:  "cached plan must not change result type",
+		Routine:  "RevalidateCachedQuery",
+	}
+}
+
+// NewDeadlockError creates the error PostgreSQL returns to the transaction
+// aborted to resolve the deadlock, so the retry logic may be tested.
+func NewDeadlockError() *pgconn.PgError {
+	return &pgconn.PgError{
+		Severity: "ERROR",
+		Code:     "40P01",
+		Message:  "deadlock detected",
+		Routine:  "DeadLockReport",
+	}
+}
@@ -1,12 +1,15 @@
 package pgxmock
 
 import (
+	"bytes"
+	"database/sql/driver"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -16,7 +19,13 @@ import (
 // CSVColumnParser is a function which converts trimmed csv
 // column string to a []byte representation. Currently
 // transforms NULL to nil
-var CSVColumnParser = func(s string) interface{} {
+//
+// Deprecated: mutating the package level parser affects all tests
+// running in parallel. Use CSVColumnParserOption for the mock or
+// Rows.WithCSVColumnParser for the specific rows instead.
+var CSVColumnParser = defaultCSVColumnParser
+
+func defaultCSVColumnParser(s string) interface{} {
 	switch {
 	case strings.ToLower(s) == "null":
 		return nil
@@ -35,26 +44,38 @@ func (r *connRow) Scan(dest ...any) (err error) {
 	}
 
 	for _, d := range dest {
-		if _, ok := d.(*pgtype.DriverBytes); ok {
+		if _, ok := d.(*pgtype.DriverBytes); ok && !rows.driverBytes {
 			rows.Close()
 			return fmt.Errorf("cannot scan into *pgtype.DriverBytes from QueryRow")
 		}
 	}
 
+	defer rows.Close()
 	if !rows.Next() {
 		if rows.Err() == nil {
 			return pgx.ErrNoRows
 		}
 		return rows.Err()
 	}
-	defer rows.Close()
-	return errors.Join(rows.Scan(dest...), rows.Err())
+	err = rows.Scan(dest...)
+	if rows.Err() != nil {
+		return rows.Err()
+	}
+	return err
 }
 
+// errRowsClosed is returned when rows are used after Close(), same as pgx does
+var errRowsClosed = errors.New("rows is closed")
+
 type rowSets struct {
-	sets     []*Rows
-	RowSetNo int
-	ex       *ExpectedQuery
+	sets        []*Rows
+	RowSetNo    int
+	ex          *ExpectedQuery
+	singleRow   bool // rows are returned by QueryRow
+	driverBytes bool // QueryRow may scan into *pgtype.DriverBytes
+	closed      bool
+	err         error  // fatal error, e.g. returned by pgx.RowScanner
+	done        func() // called when rows are closed or fully read, see StrictConnOption
 }
 
 func (rs *rowSets) Conn() *pgx.Conn {
@@ -62,6 +83,9 @@ func (rs *rowSets) Conn() *pgx.Conn {
 }
 
 func (rs *rowSets) Err() error {
+	if rs.err != nil {
+		return rs.err
+	}
 	r := rs.sets[rs.RowSetNo]
 	return r.nextErr[r.recNo-1]
 }
@@ -79,16 +103,26 @@ func (rs *rowSets) FieldDescriptions() []pgconn.FieldDescription {
 // }
 
 func (rs *rowSets) Close() {
+	rs.closed = true
 	if rs.ex != nil {
 		rs.ex.rowsWereClosed = true
 	}
+	if rs.done != nil {
+		rs.done()
+	}
 	// return rs.sets[rs.pos].closeErr
 }
 
 // advances to next row
 func (rs *rowSets) Next() bool {
+	if rs.closed {
+		return false
+	}
 	r := rs.sets[rs.RowSetNo]
 	r.recNo++
+	if rs.done != nil && rs.fullyRead() {
+		rs.done()
+	}
 	return r.recNo <= len(r.rows)
 }
 
@@ -96,65 +130,294 @@ func (rs *rowSets) Next() bool {
 // call Values without first calling Next() and checking that it returned
 // true.
 func (rs *rowSets) Values() ([]interface{}, error) {
+	if rs.closed {
+		return nil, errRowsClosed
+	}
 	r := rs.sets[rs.RowSetNo]
-	return r.rows[r.recNo-1], r.nextErr[r.recNo-1]
+	if !r.wire {
+		return r.rows[r.recNo-1], r.nextErr[r.recNo-1]
+	}
+	values := make([]interface{}, len(r.defs))
+	for i, col := range r.rows[r.recNo-1] {
+		value, err := r.wireValue(r.defs[i], col)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, r.nextErr[r.recNo-1]
+}
+
+// wireValue returns the column value decoded from the column format the same
+// way pgx does, i.e. values of unknown types are strings in the text format
+// and []byte in the binary one
+func (r *Rows) wireValue(def pgconn.FieldDescription, col any) (any, error) {
+	buf, err := r.rawValue(def, col)
+	if err != nil || buf == nil {
+		return nil, err
+	}
+	if r.typeMap == nil {
+		r.typeMap = r.newTypeMap()
+	}
+	if t, ok := r.typeMap.TypeForOID(def.DataTypeOID); ok {
+		return t.Codec.DecodeValue(r.typeMap, def.DataTypeOID, def.Format, buf)
+	}
+	if def.Format == pgtype.TextFormatCode {
+		return string(buf), nil
+	}
+	return buf, nil
 }
 
 func (rs *rowSets) Scan(dest ...interface{}) error {
+	if rs.closed {
+		return errRowsClosed
+	}
 	r := rs.sets[rs.RowSetNo]
 	if len(dest) == 1 {
 		if rc, ok := dest[0].(pgx.RowScanner); ok {
-			return rc.ScanRow(rs)
+			// the same as pgx, the scanner error closes rows
+			if err := rc.ScanRow(rs); err != nil {
+				rs.err = err
+				rs.Close()
+				return err
+			}
+			return nil
 		}
 	}
-	if len(dest) != len(r.defs) {
-		return fmt.Errorf("Incorrect argument number %d for columns %d", len(dest), len(r.defs))
-	}
-	if len(r.rows) == 0 {
+	if r.recNo < 1 || r.recNo > len(r.rows) {
+		// no current row, e.g. Next was not called or returned false
 		return pgx.ErrNoRows
 	}
+	if len(dest) != len(r.defs) {
+		// the same as pgx, the wrong number of destinations closes rows
+		rs.err = fmt.Errorf("number of field descriptions must equal number of destinations, got %d and %d", len(r.defs), len(dest))
+		rs.Close()
+		return rs.err
+	}
 	for i, col := range r.rows[r.recNo-1] {
-		if dest[i] == nil {
-			//behave compatible with pgx
-			continue
+		if err := r.scanColumn(i, col, dest); err != nil {
+			return err
 		}
-		destVal := reflect.ValueOf(dest[i])
-		if destVal.Kind() != reflect.Ptr {
-			return fmt.Errorf("Destination argument must be a pointer for column %s", r.defs[i].Name)
+	}
+	return r.nextErr[r.recNo-1]
+}
+
+// scanColumn converts the column value col into the destination dest[i]
+func (r *Rows) scanColumn(i int, col any, dest []any) error {
+	if dest[i] == nil {
+		//behave compatible with pgx
+		return nil
+	}
+	if db, ok := dest[i].(*pgtype.DriverBytes); ok {
+		if err := r.scanDriverBytes(r.defs[i], col, db); err != nil {
+			return pgx.ScanArgError{ColumnIndex: i, Err: err}
 		}
-		if col == nil {
-			dest[i] = nil
-			continue
+		return nil
+	}
+	destVal := reflect.ValueOf(dest[i])
+	if destVal.Kind() != reflect.Ptr {
+		// e.g. pgtype.CompositeFields are scanned by value
+		if err := r.scanWithTypeMap(r.defs[i], col, dest[i]); err != nil {
+			return pgx.ScanArgError{ColumnIndex: i, Err: err}
 		}
-		val := reflect.ValueOf(col)
-		if _, ok := dest[i].(*interface{}); ok || val.Type().AssignableTo(destVal.Elem().Type()) {
-			if destElem := destVal.Elem(); destElem.CanSet() {
-				destElem.Set(val)
-			} else {
-				return fmt.Errorf("Cannot set destination value for column %s", r.defs[i].Name)
-			}
-		} else {
-			// Try to use Scanner interface
-			scanner, ok := destVal.Interface().(interface{ Scan(interface{}) error })
+		return nil
+	}
+	if col == nil {
+		dest[i] = nil
+		return nil
+	}
+	val := reflect.ValueOf(col)
+	if _, ok := dest[i].(*interface{}); ok || val.Type().AssignableTo(destVal.Elem().Type()) {
+		if destElem := destVal.Elem(); destElem.CanSet() {
+			destElem.Set(val)
+			return nil
+		}
+		return pgx.ScanArgError{ColumnIndex: i, Err: r.scanFailedError(r.defs[i], dest[i])}
+	}
+	// Try to use Scanner interface
+	scanner, ok := destVal.Interface().(interface{ Scan(interface{}) error })
+	if !ok {
+		// Fallback to the pgx type map, the same way pgx decodes values
+		if err := r.scanWithTypeMap(r.defs[i], col, dest[i]); err != nil {
+			return pgx.ScanArgError{ColumnIndex: i, Err: err}
+		}
+		return nil
+	}
+	src := val.Interface()
+	if valuer, ok := src.(driver.Valuer); ok {
+		// e.g. pgtype.Numeric is passed to scanners as a string like pgx does
+		if v, err := valuer.Value(); err == nil {
+			src = v
+		}
+	}
+	if err := scanner.Scan(src); err != nil {
+		if r.scanWithTypeMap(r.defs[i], col, dest[i]) != nil {
+			return pgx.ScanArgError{ColumnIndex: i, Err: err}
+		}
+	}
+	return nil
+}
 
-			if !ok {
-				return fmt.Errorf("Destination kind '%v' not supported for value kind '%v' of column '%s'",
-					destVal.Elem().Kind(), val.Kind(), string(r.defs[i].Name))
-			}
-			if err := scanner.Scan(val.Interface()); err != nil {
-				return fmt.Errorf("Scanning value error for column '%s': %w", string(r.defs[i].Name), err)
+// scanDriverBytes copies the raw column value into dest. Unlike pgx,
+// the bytes are not reused, so they remain valid after Scan
+func (r *Rows) scanDriverBytes(def pgconn.FieldDescription, col any, dest *pgtype.DriverBytes) error {
+	buf, err := r.rawValue(def, col)
+	if err != nil {
+		return err
+	}
+	*dest = bytes.Clone(buf)
+	return nil
+}
+
+// rawValue returns the column value encoded in the column format,
+// unless it is already a string or []byte
+func (r *Rows) rawValue(def pgconn.FieldDescription, col any) ([]byte, error) {
+	switch v := col.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	}
+	if r.typeMap == nil {
+		r.typeMap = r.newTypeMap()
+	}
+	oid := def.DataTypeOID
+	if _, ok := r.typeMap.TypeForOID(oid); !ok {
+		oid = guessOID(r.typeMap, col, nil)
+	}
+	return r.typeMap.Encode(oid, def.Format, col, nil)
+}
+
+// scanWithTypeMap encodes the column value to the wire format, unless it is
+// already a string or []byte, and scans it into dest using the type map
+func (r *Rows) scanWithTypeMap(def pgconn.FieldDescription, col any, dest any) error {
+	if r.typeMap == nil {
+		r.typeMap = r.newTypeMap()
+	}
+	oid := def.DataTypeOID
+	if _, ok := r.typeMap.TypeForOID(oid); !ok {
+		oid = guessOID(r.typeMap, col, dest)
+	}
+	var src []byte
+	switch v := col.(type) {
+	case string:
+		src = []byte(v)
+	case []byte:
+		src = v
+	default:
+		var err error
+		if src, err = r.typeMap.Encode(oid, def.Format, col, nil); err != nil {
+			return err
+		}
+	}
+	return r.typeMap.Scan(oid, def.Format, src, dest)
+}
+
+// guessOID finds the data type of the untyped column by its value. JSON
+// documents, e.g. maps, structs or strings scanned into a struct, are jsonb,
+// strings scanned into slices are arrays of the destination element type
+func guessOID(m *pgtype.Map, col any, dest any) uint32 {
+	switch col.(type) {
+	case string, []byte:
+		t := reflect.TypeOf(dest)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct, reflect.Map:
+			return pgtype.JSONBOID
+		case reflect.Slice:
+			// text-form arrays like {1,2,3} are typed by the destination
+			if dt, ok := m.TypeForValue(reflect.New(t).Elem().Interface()); ok && t.Elem().Kind() != reflect.Uint8 {
+				return dt.OID
 			}
+		}
+	}
+	if t, ok := m.TypeForValue(col); ok {
+		return t.OID
+	}
+	switch reflect.ValueOf(col).Kind() {
+	case reflect.Map, reflect.Struct, reflect.Slice:
+		return pgtype.JSONBOID
+	}
+	return 0
+}
+
+// newTypeMap returns the pgx type map with extra types registered
+func (r *Rows) newTypeMap() *pgtype.Map {
+	m := pgtype.NewMap()
+	for _, t := range r.types {
+		m.RegisterType(t)
+	}
+	return m
+}
 
+// WithTypes registers extra data types, e.g. extension or custom types, used
+// to scan values of these rows and to parse them with typed CSV parsing.
+// return the same instance to perform subsequent actions.
+func (r *Rows) WithTypes(types ...*pgtype.Type) *Rows {
+	r.types = append(r.types, types...)
+	r.typeMap = nil
+	return r
+}
+
+// SensitiveColumns marks columns containing sensitive data, e.g. passwords,
+// so their values are printed as [REDACTED] in errors and expectation dumps.
+// return the same instance to perform subsequent actions.
+func (r *Rows) SensitiveColumns(columns ...string) *Rows {
+	sensitive := make(map[string]bool, len(r.sensitive)+len(columns))
+	for column := range r.sensitive {
+		sensitive[column] = true
+	}
+	for _, column := range columns {
+		sensitive[column] = true
+	}
+	r.sensitive = sensitive
+	return r
+}
+
+// rowString formats the row values with sensitive columns redacted
+func (r *Rows) rowString(row []interface{}) string {
+	if len(r.sensitive) == 0 {
+		return fmt.Sprintf("%+v", row)
+	}
+	values := make([]interface{}, len(row))
+	for i, v := range row {
+		values[i] = v
+		if i < len(r.defs) && r.sensitive[r.defs[i].Name] {
+			values[i] = Redacted
 		}
 	}
-	return r.nextErr[r.recNo-1]
+	return fmt.Sprintf("%+v", values)
+}
+
+// scanFailedError mimics the error pgx returns when no scan plan exists for the destination
+func (r *Rows) scanFailedError(def pgconn.FieldDescription, dst any) error {
+	dataTypeName := "unknown type"
+	if t, ok := r.newTypeMap().TypeForOID(def.DataTypeOID); ok {
+		dataTypeName = t.Name
+	}
+	format := "text"
+	if def.Format == pgtype.BinaryFormatCode {
+		format = "binary"
+	}
+	return fmt.Errorf("cannot scan %s (OID %d) in %v format into %T", dataTypeName, def.DataTypeOID, format, dst)
 }
 
 func (rs *rowSets) RawValues() [][]byte {
+	if rs.closed {
+		return nil
+	}
 	r := rs.sets[rs.RowSetNo]
 	dest := make([][]byte, len(r.defs))
 
 	for i, col := range r.rows[r.recNo-1] {
+		if r.wire {
+			dest[i], _ = r.rawValue(r.defs[i], col)
+			continue
+		}
 		if b, ok := rawBytes(col); ok {
 			dest[i] = b
 			continue
@@ -165,26 +428,79 @@ func (rs *rowSets) RawValues() [][]byte {
 	return dest
 }
 
+// maxStringRows is the number of rows printed per result set by String
+// methods, the rest are summarized with the count
+const maxStringRows = 10
+
 // transforms to debuggable printable string
 func (rs *rowSets) String() string {
 	if rs.empty() {
 		return "\t- returns no data"
 	}
 
-	msg := "\t- returns data:\n"
+	w := new(strings.Builder)
+	w.WriteString("\t- returns data:\n")
 	if len(rs.sets) == 1 {
-		for n, row := range rs.sets[0].rows {
-			msg += fmt.Sprintf("\t\trow %d - %+v\n", n, row)
-		}
-		return msg
+		writeRows(w, rs.sets[0], "\t\trow %d - %s\n", "\t\t... and %d more rows\n")
+		return w.String()
 	}
 	for i, set := range rs.sets {
-		msg += fmt.Sprintf("\t\tresult set: %d\n", i)
-		for n, row := range set.rows {
-			msg += fmt.Sprintf("\t\t\trow %d: %+v\n", n, row)
+		fmt.Fprintf(w, "\t\tresult set: %d\n", i)
+		writeRows(w, set, "\t\t\trow %d: %s\n", "\t\t\t... and %d more rows\n")
+	}
+	return w.String()
+}
+
+// writeRows prints at most maxStringRows rows and the count of the rest
+func writeRows(w *strings.Builder, r *Rows, rowFormat, restFormat string) {
+	for n, row := range r.rows {
+		if n == maxStringRows {
+			fmt.Fprintf(w, restFormat, len(r.rows)-n)
+			return
 		}
+		fmt.Fprintf(w, rowFormat, n, r.rowString(row))
+	}
+}
+
+// reissue returns the fresh copy of rows to be returned by the repeated query
+func (rs *rowSets) reissue() *rowSets {
+	sets := make([]*Rows, len(rs.sets))
+	for i, r := range rs.sets {
+		sets[i] = r.Clone()
+	}
+	return &rowSets{sets: sets, ex: rs.ex}
+}
+
+// setFormats sets formats of the columns, see ExpectedQuery.WithResultFormats
+func (rs *rowSets) setFormats(formats []int16, formatsByOID map[uint32]int16) {
+	if formats == nil && formatsByOID == nil {
+		return
+	}
+	for _, r := range rs.sets {
+		for i := range r.defs {
+			switch {
+			case len(formats) == 1:
+				r.defs[i].Format = formats[0]
+			case i < len(formats):
+				r.defs[i].Format = formats[i]
+			default:
+				if format, ok := formatsByOID[r.defs[i].DataTypeOID]; ok {
+					r.defs[i].Format = format
+				}
+			}
+		}
+		r.wire = true
+	}
+}
+
+// fullyRead reports whether rows were iterated until the end or
+// until the row error, or were returned by QueryRow
+func (rs *rowSets) fullyRead() bool {
+	if rs.singleRow {
+		return true
 	}
-	return msg
+	r := rs.sets[rs.RowSetNo]
+	return r.recNo > len(r.rows) || r.recNo > 0 && r.nextErr[r.recNo-1] != nil
 }
 
 func (rs *rowSets) empty() bool {
@@ -216,6 +532,12 @@ type Rows struct {
 	recNo      int
 	nextErr    map[int]error
 	closeErr   error
+	csvParser  func(string) interface{}
+	types      []*pgtype.Type // extra types registered for scanning and parsing
+	typeMap    *pgtype.Map    // lazily built type map used for scanning
+	sensitive  map[string]bool
+	wire       bool // values are exchanged in the column formats
+	shared     bool // rows are shared by copies, see AddRowsNoCopy
 }
 
 // NewRows allows Rows to be created from a
@@ -277,6 +599,42 @@ func (r *Rows) AddRows(values ...[]any) *Rows {
 	return r
 }
 
+// AddRowsNoCopy adds multiple rows taking the ownership of the slices
+// instead of copying them, e.g. for benchmarks and high-volume tests with
+// large pre-built datasets, and returns the same instance to perform
+// subsequent actions. Rows are shared by all copies made by WillReturnRows
+// and similar methods, so the slices must not be modified afterwards.
+func (r *Rows) AddRowsNoCopy(values ...[]any) *Rows {
+	for _, row := range values {
+		if len(row) != len(r.defs) {
+			panic("Expected number of values to match number of columns")
+		}
+	}
+	r.rows = append(r.rows, values...)
+	r.shared = true
+	return r
+}
+
+// RepeatRow adds n identical rows composed from values and
+// returns the same instance to perform subsequent actions.
+func (r *Rows) RepeatRow(n int, values ...any) *Rows {
+	for i := 0; i < n; i++ {
+		r.AddRow(values...)
+	}
+	return r
+}
+
+// AddRowsFunc adds n rows composed from values returned by f
+// for each row number starting with zero, e.g. to generate
+// large patterned result sets, and returns the same instance
+// to perform subsequent actions.
+func (r *Rows) AddRowsFunc(n int, f func(i int) []any) *Rows {
+	for i := 0; i < n; i++ {
+		r.AddRow(f(i)...)
+	}
+	return r
+}
+
 // AddCommandTag will add a command tag to the result set
 func (r *Rows) AddCommandTag(tag pgconn.CommandTag) *Rows {
 	r.commandTag = tag
@@ -299,13 +657,159 @@ func (r *Rows) FromCSVString(s string) *Rows {
 
 		row := make([]interface{}, len(r.defs))
 		for i, v := range res {
-			row[i] = CSVColumnParser(strings.TrimSpace(v))
+			row[i] = r.parseCSVColumn(strings.TrimSpace(v))
+		}
+		r.rows = append(r.rows, row)
+	}
+	return r
+}
+
+// CSVOptions allows to customize the parsing of CSV strings
+// by FromCSVStringWithOptions
+type CSVOptions struct {
+	// Delimiter is the field delimiter, ',' by default
+	Delimiter rune
+	// LazyQuotes allows a quote to appear in an unquoted field and
+	// a non-doubled quote to appear in a quoted field
+	LazyQuotes bool
+	// NoTrim disables trimming of leading and trailing spaces of fields
+	NoTrim bool
+	// NullToken is the case insensitive value transformed to nil,
+	// "NULL" by default
+	NullToken string
+	// ColumnParsers allows to specify a parser for the column by its name
+	ColumnParsers map[string]func(string) interface{}
+	// TypedParsing enables parsing of values according to the DataTypeOID
+	// of the column, e.g. int4 column values are parsed to int32
+	TypedParsing bool
+	// TimeLayouts allows to specify the time layout for the column by its
+	// name, e.g. time.RFC3339 or time.DateOnly, so its values are parsed
+	// to time.Time in UTC, unless the layout includes the zone
+	TimeLayouts map[string]string
+}
+
+// FromCSVStringWithOptions build rows from csv string using options.
+// Column parsers are applied first, then time layouts, then typed parsing if enabled,
+// otherwise the rows CSV column parser is used. Panics if the value cannot be
+// parsed according to the column type.
+// return the same instance to perform subsequent actions.
+// Note that the number of values must match the number
+// of columns
+func (r *Rows) FromCSVStringWithOptions(s string, opts CSVOptions) *Rows {
+	if opts.NullToken == "" {
+		opts.NullToken = "NULL"
+	}
+	if !opts.NoTrim {
+		s = strings.TrimSpace(s)
+	}
+	csvReader := csv.NewReader(strings.NewReader(s))
+	if opts.Delimiter != 0 {
+		csvReader.Comma = opts.Delimiter
+	}
+	csvReader.LazyQuotes = opts.LazyQuotes
+	csvReader.TrimLeadingSpace = !opts.NoTrim
+
+	var typeMap *pgtype.Map
+	if opts.TypedParsing {
+		typeMap = r.newTypeMap()
+	}
+	for {
+		res, err := csvReader.Read()
+		if err != nil || res == nil {
+			break
+		}
+
+		row := make([]interface{}, len(r.defs))
+		for i, v := range res {
+			if !opts.NoTrim {
+				v = strings.TrimSpace(v)
+			}
+			row[i] = r.parseCSVValue(r.defs[i], v, opts, typeMap)
 		}
 		r.rows = append(r.rows, row)
 	}
 	return r
 }
 
+func (r *Rows) parseCSVValue(col pgconn.FieldDescription, v string, opts CSVOptions, typeMap *pgtype.Map) interface{} {
+	if strings.EqualFold(v, opts.NullToken) {
+		return nil
+	}
+	if parser, ok := opts.ColumnParsers[col.Name]; ok {
+		return parser(v)
+	}
+	if layout, ok := opts.TimeLayouts[col.Name]; ok {
+		t, err := time.Parse(layout, v)
+		if err != nil {
+			panic(fmt.Sprintf("Cannot parse value '%s' for column '%s' with layout '%s': %v", v, col.Name, layout, err))
+		}
+		return t
+	}
+	if typeMap != nil && col.DataTypeOID != 0 {
+		if dt, ok := typeMap.TypeForOID(col.DataTypeOID); ok {
+			val, err := dt.Codec.DecodeValue(typeMap, col.DataTypeOID, pgtype.TextFormatCode, []byte(v))
+			if err != nil {
+				panic(fmt.Sprintf("Cannot parse value '%s' for column '%s' of type '%s': %v", v, col.Name, dt.Name, err))
+			}
+			return val
+		}
+	}
+	return r.parseCSVColumn(v)
+}
+
+// parseCSVColumn uses the rows own CSV column parser if set,
+// otherwise the package level CSVColumnParser
+func (r *Rows) parseCSVColumn(s string) interface{} {
+	if r.csvParser != nil {
+		return r.csvParser(s)
+	}
+	return CSVColumnParser(s)
+}
+
+// WithCSVColumnParser sets the function which converts trimmed csv
+// column string to a value for FromCSVString and FromCSVStringWithOptions
+// methods of these rows only.
+// return the same instance to perform subsequent actions.
+func (r *Rows) WithCSVColumnParser(parser func(string) interface{}) *Rows {
+	r.csvParser = parser
+	return r
+}
+
+// Clone returns a deep copy of the rows with the iteration state
+// reset, so the same fixture can safely back many expectations.
+func (r *Rows) Clone() *Rows {
+	return r.clone(!r.shared)
+}
+
+// clone returns the copy of rows with the row slices
+// copied if deep is set or shared otherwise
+func (r *Rows) clone(deep bool) *Rows {
+	if r == nil {
+		return nil
+	}
+	c := &Rows{
+		commandTag: r.commandTag,
+		defs:       append([]pgconn.FieldDescription(nil), r.defs...),
+		rows:       make([][]interface{}, len(r.rows)),
+		nextErr:    make(map[int]error, len(r.nextErr)),
+		closeErr:   r.closeErr,
+		csvParser:  r.csvParser,
+		types:      r.types,
+		sensitive:  r.sensitive,
+		shared:     !deep,
+	}
+	for i, row := range r.rows {
+		if deep {
+			row = append([]interface{}(nil), row...)
+		}
+		c.rows[i] = row
+	}
+	for k, v := range r.nextErr {
+		c.nextErr[k] = v
+	}
+	return c
+}
+
 // Kind returns rows corresponding to the interface pgx.Rows
 // useful for testing entities that implement an interface pgx.RowScanner
 func (r *Rows) Kind() pgx.Rows {
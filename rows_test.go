@@ -2,10 +2,14 @@ package pgxmock
 
 import (
 	"context"
+	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -785,3 +789,559 @@ func TestInvalidsQueryRow(t *testing.T) {
 	err = mock.QueryRow(ctx, "SELECT").Scan(&d)
 	a.Error(err)
 }
+
+func TestCSVRowParserWithOptions(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	rs := NewRowsWithColumnDefinition(
+		pgconn.FieldDescription{Name: "id", DataTypeOID: pgtype.Int4OID},
+		pgconn.FieldDescription{Name: "name", DataTypeOID: pgtype.TextOID},
+		pgconn.FieldDescription{Name: "active", DataTypeOID: pgtype.BoolOID},
+		pgconn.FieldDescription{Name: "tags"},
+	).FromCSVStringWithOptions(`
+		1; "Doe; John";t;a|b
+		2;\N;false;c`,
+		CSVOptions{
+			Delimiter:    ';',
+			NullToken:    `\N`,
+			TypedParsing: true,
+			ColumnParsers: map[string]func(string) interface{}{
+				"tags": func(s string) interface{} { return strings.Split(s, "|") },
+			},
+		})
+	a.Equal([][]any{
+		{int32(1), "Doe; John", true, []string{"a", "b"}},
+		{int32(2), nil, false, []string{"c"}},
+	}, rs.rows)
+
+	rs = NewRows([]string{"col1", "col2"}).FromCSVStringWithOptions(" a , NULL ", CSVOptions{NoTrim: true})
+	a.Equal([][]any{{" a ", " NULL "}}, rs.rows)
+
+	a.Panics(func() {
+		NewRowsWithColumnDefinition(pgconn.FieldDescription{Name: "id", DataTypeOID: pgtype.Int4OID}).
+			FromCSVStringWithOptions("foo", CSVOptions{TypedParsing: true})
+	})
+}
+
+func TestCSVTimeLayouts(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	opts := CSVOptions{TimeLayouts: map[string]string{"created": time.RFC3339, "birthday": time.DateOnly}}
+	rs := NewRows([]string{"created", "birthday"}).FromCSVStringWithOptions(`
+		2024-01-02T03:04:05+02:00,1990-05-06
+		2024-01-02T03:04:05Z,NULL`, opts)
+	a.Equal(time.Date(2024, 1, 2, 1, 4, 5, 0, time.UTC), rs.rows[0][0].(time.Time).UTC())
+	a.Equal(time.Date(1990, 5, 6, 0, 0, 0, 0, time.UTC), rs.rows[0][1])
+	a.Nil(rs.rows[1][1])
+
+	mock, err := NewConn()
+	a.NoError(err)
+	mock.ExpectQuery("SELECT").WillReturnRows(rs)
+	var created, birthday time.Time
+	a.NoError(mock.QueryRow(context.Background(), "SELECT").Scan(&created, &birthday))
+	a.Equal(1990, birthday.Year())
+
+	a.Panics(func() {
+		NewRows([]string{"created"}).FromCSVStringWithOptions("yesterday", opts)
+	})
+}
+
+func TestCSVColumnParserOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	upper := func(s string) interface{} { return strings.ToUpper(s) }
+
+	mock, err := NewConn(CSVColumnParserOption(upper))
+	a.NoError(err)
+	rs := mock.NewRows([]string{"col1", "col2"}).FromCSVString("a,null")
+	a.Equal([][]any{{"A", "NULL"}}, rs.rows)
+	rs = mock.NewRowsWithColumnDefinition(pgconn.FieldDescription{Name: "col1"}).FromCSVString("b")
+	a.Equal([][]any{{"B"}}, rs.rows)
+
+	rs = NewRows([]string{"col1", "col2"}).WithCSVColumnParser(upper).FromCSVString("c,null")
+	a.Equal([][]any{{"C", "NULL"}}, rs.rows)
+
+	rs = NewRows([]string{"col1", "col2"}).FromCSVString("d,null")
+	a.Equal([][]any{{"d", nil}}, rs.rows)
+}
+
+func TestRowsClone(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	fixture := NewRows([]string{"id"}).AddRow(1).AddRow(2).RowError(1, errors.New("error"))
+	clone := fixture.Clone()
+	clone.AddRow(3)
+	a.Len(fixture.rows, 2)
+	a.Len(clone.rows, 3)
+	a.Equal(fixture.nextErr, clone.nextErr)
+
+	mock.ExpectQuery("SELECT").WillReturnRows(fixture)
+	mock.ExpectQuery("SELECT").WillReturnRows(fixture)
+	for range 2 {
+		var ids []int
+		rows, err := mock.Query(ctx, "SELECT")
+		a.NoError(err)
+		for rows.Next() {
+			var id int
+			_ = rows.Scan(&id)
+			ids = append(ids, id)
+		}
+		a.Equal([]int{1, 2}, ids)
+		rows.Close()
+	}
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestRowsWillBeClosedOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn(RowsWillBeClosedOption(true))
+
+	mock.ExpectQuery("SELECT 1").WillReturnRows(NewRows([]string{"a"}).AddRow(1))
+	mock.ExpectQuery("SELECT 2").WillReturnRows(NewRows([]string{"a"}).AddRow(2))
+	mock.ExpectQuery("SELECT 3").WillReturnRows(NewRows([]string{"a"}).AddRow(3))
+	mock.ExpectQuery("SELECT 4").WillReturnError(errors.New("failed"))
+	mock.ExpectQuery("SELECT 5").WillReturnRows(NewRows([]string{"a"})).Maybe()
+
+	_, _ = mock.Query(ctx, "SELECT 1")
+	rows, _ := mock.Query(ctx, "SELECT 2")
+	rows.Close()
+	_, _ = mock.Query(ctx, "SELECT 3")
+	_, _ = mock.Query(ctx, "SELECT 4")
+
+	err := mock.ExpectationsWereMet()
+	a.ErrorContains(err, "rows of 2 queries were not")
+	a.ErrorContains(err, "'SELECT 1' declared at rows_test.go:")
+	a.ErrorContains(err, "'SELECT 3' declared at rows_test.go:")
+}
+
+func TestRowsMustBeFullyRead(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	rs := NewRows([]string{"a"}).AddRow(1).AddRow(2)
+
+	mock, _ := NewConn()
+	mock.ExpectQuery("SELECT").WillReturnRows(rs).RowsMustBeFullyRead()
+	rows, _ := mock.Query(ctx, "SELECT")
+	rows.Next()
+	rows.Close()
+	a.ErrorContains(mock.ExpectationsWereMet(), "rows of 1 queries were not")
+
+	mock, _ = NewConn(RowsMustBeFullyReadOption(true))
+	mock.ExpectQuery("SELECT 1").WillReturnRows(rs)
+	mock.ExpectQuery("SELECT 2").WillReturnRows(rs)
+	mock.ExpectQuery("SELECT 3").WillReturnRows(rs.Clone().RowError(0, errors.New("error")))
+	rows, _ = mock.Query(ctx, "SELECT 1")
+	for rows.Next() {
+	}
+	var a1 int
+	a.NoError(mock.QueryRow(ctx, "SELECT 2").Scan(&a1))
+	rows, _ = mock.Query(ctx, "SELECT 3")
+	a.True(rows.Next())
+	a.Error(rows.Err())
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestRowsUseAfterClose(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	mock.ExpectQuery("SELECT").WillReturnRows(NewRows([]string{"a"}).AddRow(1).AddRow(2))
+	rows, err := mock.Query(ctx, "SELECT")
+	a.NoError(err)
+	a.True(rows.Next())
+	rows.Close()
+	var v int
+	a.ErrorContains(rows.Scan(&v), "rows is closed")
+	_, err = rows.Values()
+	a.ErrorContains(err, "rows is closed")
+	a.Nil(rows.RawValues())
+	a.False(rows.Next())
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestScanErrorsParity(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	mock.ExpectQuery("SELECT").WillReturnRows(NewRows([]string{"id", "name"}).AddRow(1, "john")).Times(2)
+	rows, _ := mock.Query(ctx, "SELECT")
+	a.True(rows.Next())
+
+	var id int
+	var name string
+	a.EqualError(rows.Scan(&id), "number of field descriptions must equal number of destinations, got 2 and 1")
+	a.EqualError(rows.Err(), "number of field descriptions must equal number of destinations, got 2 and 1")
+	a.False(rows.Next(), "rows are closed")
+
+	rows, _ = mock.Query(ctx, "SELECT")
+	defer rows.Close()
+	a.True(rows.Next())
+	err := rows.Scan(&id, name)
+	var scanErr pgx.ScanArgError
+	a.ErrorAs(err, &scanErr)
+	a.Equal(1, scanErr.ColumnIndex)
+	a.ErrorContains(err, "can't scan into dest[1]: cannot scan")
+
+	a.ErrorAs(rows.Scan(&id, &id), &scanErr)
+	a.Equal(1, scanErr.ColumnIndex)
+
+	a.NoError(rows.Scan(nil, &name))
+	a.Equal("john", name)
+}
+
+// vectorCodec is the minimal text codec of the pgvector extension type
+type vectorCodec struct{}
+
+func (vectorCodec) FormatSupported(format int16) bool { return format == pgtype.TextFormatCode }
+
+func (vectorCodec) PreferredFormat() int16 { return pgtype.TextFormatCode }
+
+func (vectorCodec) PlanEncode(*pgtype.Map, uint32, int16, any) pgtype.EncodePlan { return nil }
+
+func (vectorCodec) PlanScan(_ *pgtype.Map, _ uint32, _ int16, target any) pgtype.ScanPlan {
+	if _, ok := target.(*[]float32); ok {
+		return vectorScanPlan{}
+	}
+	return nil
+}
+
+func (vectorCodec) DecodeDatabaseSQLValue(_ *pgtype.Map, _ uint32, _ int16, src []byte) (driver.Value, error) {
+	return string(src), nil
+}
+
+func (vectorCodec) DecodeValue(_ *pgtype.Map, _ uint32, _ int16, src []byte) (any, error) {
+	var v []float32
+	err := vectorScanPlan{}.Scan(src, &v)
+	return v, err
+}
+
+type vectorScanPlan struct{}
+
+func (vectorScanPlan) Scan(src []byte, target any) error {
+	v := target.(*[]float32)
+	*v = nil
+	for _, s := range strings.Split(strings.Trim(string(src), "[]"), ",") {
+		f, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return err
+		}
+		*v = append(*v, float32(f))
+	}
+	return nil
+}
+
+func TestTypesOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	const vectorOID = 90001
+	vector := &pgtype.Type{Name: "vector", OID: vectorOID, Codec: vectorCodec{}}
+	mock, _ := NewConn(TypesOption(vector))
+
+	col := pgconn.FieldDescription{Name: "embedding", DataTypeOID: vectorOID}
+	mock.ExpectQuery("SELECT embedding").WillReturnRows(
+		mock.NewRowsWithColumnDefinition(col).AddRow("[1,2.5,3]"),
+		mock.NewRowsWithColumnDefinition(col).FromCSVStringWithOptions(`"[4,5]"`, CSVOptions{TypedParsing: true}),
+	)
+	rows, err := mock.Query(ctx, "SELECT embedding")
+	a.NoError(err)
+	defer rows.Close()
+	var embedding []float32
+	a.True(rows.Next())
+	a.NoError(rows.Scan(&embedding))
+	a.Equal([]float32{1, 2.5, 3}, embedding)
+
+	rs := rows.(*rowSets)
+	rs.RowSetNo++
+	a.True(rows.Next())
+	a.NoError(rows.Scan(&embedding))
+	a.Equal([]float32{4, 5}, embedding)
+
+	r := NewRowsWithColumnDefinition(col).AddRow("[1]").WithTypes(vector).Kind()
+	a.True(r.Next())
+	a.NoError(r.Scan(&embedding))
+	a.Equal([]float32{1}, embedding)
+}
+
+func TestJSONScanIntoStruct(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	type user struct {
+		Name string `json:"name"`
+	}
+	jsonb := pgconn.FieldDescription{Name: "data", DataTypeOID: pgtype.JSONBOID}
+	for _, rows := range []*Rows{
+		NewRowsWithColumnDefinition(jsonb).AddRow(`{"name": "john"}`),
+		NewRowsWithColumnDefinition(jsonb).AddRow([]byte(`{"name": "john"}`)),
+		NewRowsWithColumnDefinition(jsonb).AddRow(map[string]any{"name": "john"}),
+		NewRows([]string{"data"}).AddRow(`{"name": "john"}`),
+		NewRows([]string{"data"}).AddRow(map[string]any{"name": "john"}),
+		NewRows([]string{"data"}).AddRow(struct {
+			Name string `json:"name"`
+		}{"john"}),
+	} {
+		r := rows.Kind()
+		a.True(r.Next())
+		var u user
+		a.NoError(r.Scan(&u))
+		a.Equal("john", u.Name)
+		var pu *user
+		a.NoError(r.Scan(&pu))
+		a.Equal("john", pu.Name)
+	}
+
+	r := NewRowsWithColumnDefinition(jsonb).AddRow(`{"name": 42}`).Kind()
+	a.True(r.Next())
+	var u user
+	a.ErrorContains(r.Scan(&u), "can't scan into dest[0]")
+}
+
+func newCompositeType() *pgtype.Type {
+	m := pgtype.NewMap()
+	int4, _ := m.TypeForOID(pgtype.Int4OID)
+	text, _ := m.TypeForOID(pgtype.TextOID)
+	return &pgtype.Type{Name: "item", OID: 90002, Codec: &pgtype.CompositeCodec{
+		Fields: []pgtype.CompositeCodecField{{Name: "id", Type: int4}, {Name: "name", Type: text}},
+	}}
+}
+
+func TestCompositeTypeScan(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	type item struct {
+		ID   int32
+		Name string
+	}
+	mock, _ := NewConn(TypesOption(newCompositeType()))
+	col := pgconn.FieldDescription{Name: "item", DataTypeOID: 90002}
+	for _, v := range []any{"(1,foo)", item{1, "foo"}, pgtype.CompositeFields{int32(1), "foo"}} {
+		r := mock.NewRowsWithColumnDefinition(col).AddRow(v).Kind()
+		a.True(r.Next())
+		var i item
+		a.NoError(r.Scan(&i))
+		a.Equal(item{1, "foo"}, i)
+		var id int32
+		var name string
+		a.NoError(r.Scan(pgtype.CompositeFields{&id, &name}))
+		a.Equal(int32(1), id)
+		a.Equal("foo", name)
+	}
+}
+
+// userScanner implements pgx.RowScanner
+type userScanner struct {
+	ID   int
+	Name string
+	err  error
+}
+
+func (u *userScanner) ScanRow(rows pgx.Rows) error {
+	if u.err != nil {
+		return u.err
+	}
+	return rows.Scan(&u.ID, &u.Name)
+}
+
+func TestRowScannerParity(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	rs := NewRows([]string{"id", "name"}).AddRow(1, "john")
+	mock.ExpectQuery("SELECT").WillReturnRows(rs).Times(3)
+	mock.ExpectQuery("SELECT").WillReturnRows(NewRows([]string{"id", "name"}))
+
+	var u userScanner
+	a.NoError(mock.QueryRow(ctx, "SELECT").Scan(&u))
+	a.Equal(userScanner{ID: 1, Name: "john"}, u)
+
+	rows, _ := mock.Query(ctx, "SELECT")
+	u, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[userScanner])
+	a.NoError(err)
+	a.Equal(userScanner{ID: 1, Name: "john"}, u)
+
+	scanErr := errors.New("scanner failed")
+	err = mock.QueryRow(ctx, "SELECT").Scan(&userScanner{err: scanErr})
+	a.ErrorIs(err, scanErr)
+
+	users, err := pgx.CollectRows(rs.Kind(), pgx.RowToStructByPos[userScanner])
+	a.NoError(err)
+	a.Len(users, 1)
+
+	kind := rs.Kind()
+	var id int
+	var name string
+	a.ErrorIs(kind.Scan(&id, &name), pgx.ErrNoRows)
+	for kind.Next() {
+	}
+	a.ErrorIs(kind.Scan(&id, &name), pgx.ErrNoRows)
+
+	a.ErrorIs(mock.QueryRow(ctx, "SELECT").Scan(&u), pgx.ErrNoRows)
+}
+
+func TestRepeatedQueryRows(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	mock.ExpectQuery("SELECT").WillReturnRows(NewRows([]string{"id"}).AddRow(1).AddRow(2)).Times(2)
+
+	for range 2 {
+		rows, _ := mock.Query(ctx, "SELECT")
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		a.NoError(err)
+		a.Equal([]int{1, 2}, ids)
+	}
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestDriverBytesOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn(DriverBytesOption(true))
+	data := []byte("raw")
+	rs := NewRowsWithColumnDefinition(
+		pgconn.FieldDescription{Name: "data", DataTypeOID: pgtype.ByteaOID, Format: pgtype.BinaryFormatCode},
+		pgconn.FieldDescription{Name: "id", DataTypeOID: pgtype.Int4OID, Format: pgtype.BinaryFormatCode},
+	).AddRow(data, int32(1))
+	mock.ExpectQuery("SELECT").WillReturnRows(rs).Times(2)
+
+	var d, id pgtype.DriverBytes
+	a.NoError(mock.QueryRow(ctx, "SELECT").Scan(&d, &id))
+	a.Equal(pgtype.DriverBytes("raw"), d)
+	a.Equal(pgtype.DriverBytes{0, 0, 0, 1}, id, "raw value in the column format")
+	d[0] = 'w'
+	a.Equal([]byte("raw"), data, "scanned bytes are copied")
+
+	rows, _ := mock.Query(ctx, "SELECT")
+	a.True(rows.Next())
+	a.NoError(rows.Scan(&d, &id))
+	a.Equal(pgtype.DriverBytes("raw"), d)
+	rows.Close()
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestResultFormats(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	rs := NewRowsWithColumnDefinition(
+		pgconn.FieldDescription{Name: "id", DataTypeOID: pgtype.Int4OID},
+		pgconn.FieldDescription{Name: "name"},
+	).AddRow(int32(42), "john")
+	mock.ExpectQuery("SELECT").WillReturnRows(rs).WithResultFormats(pgtype.BinaryFormatCode).Times(2)
+	mock.ExpectQuery("SELECT").WithArgs(pgx.QueryResultFormats{pgtype.TextFormatCode, pgtype.BinaryFormatCode}).WillReturnRows(rs)
+
+	rows, err := mock.Query(ctx, "SELECT")
+	a.NoError(err)
+	a.True(rows.Next())
+	a.Equal(int16(pgtype.BinaryFormatCode), rows.FieldDescriptions()[0].Format)
+	a.Equal([][]byte{{0, 0, 0, 42}, []byte("john")}, rows.RawValues())
+	values, err := rows.Values()
+	a.NoError(err)
+	a.Equal([]any{int32(42), []byte("john")}, values, "unknown types are []byte in binary format")
+	var id int
+	a.NoError(rows.Scan(&id, nil))
+	a.Equal(42, id)
+	rows.Close()
+
+	rows, _ = mock.Query(ctx, "SELECT")
+	a.True(rows.Next(), "repeated query rows start from the beginning")
+	rows.Close()
+
+	rows, _ = mock.Query(ctx, "SELECT", pgx.QueryResultFormats{pgtype.TextFormatCode, pgtype.BinaryFormatCode})
+	a.True(rows.Next())
+	a.Equal([][]byte{[]byte("42"), []byte("john")}, rows.RawValues())
+	rows.Close()
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func ExampleRows_AddRowsFunc() {
+	mock, err := NewConn()
+	if err != nil {
+		fmt.Println("failed to open sqlmock database:", err)
+		return
+	}
+	defer mock.Close(context.Background())
+
+	rows := NewRows([]string{"id", "title"}).
+		AddRowsFunc(2, func(i int) []any { return []any{i + 1, fmt.Sprintf("post %d", i+1)} }).
+		RepeatRow(2, 0, "draft")
+
+	mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+	rs, _ := mock.Query(context.Background(), "SELECT")
+	defer rs.Close()
+
+	for rs.Next() {
+		var id int
+		var title string
+		_ = rs.Scan(&id, &title)
+		fmt.Println("scanned id:", id, "and title:", title)
+	}
+	// Output: scanned id: 1 and title: post 1
+	// scanned id: 2 and title: post 2
+	// scanned id: 0 and title: draft
+	// scanned id: 0 and title: draft
+}
+
+func TestRepeatRowCopiesValues(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	rows := NewRows([]string{"id"}).RepeatRow(3, 1)
+	a.Len(rows.rows, 3)
+	rows.rows[0][0] = 2
+	a.Equal(1, rows.rows[1][0], "rows must not share the values slice")
+	a.Panics(func() { NewRows([]string{"id"}).RepeatRow(1, 1, 2) })
+}
+
+func TestRowSetsStringTruncated(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	rs := &rowSets{sets: []*Rows{NewRows([]string{"id"}).RepeatRow(maxStringRows+5, 1)}}
+	s := rs.String()
+	a.Equal(maxStringRows, strings.Count(s, "row "))
+	a.True(strings.HasSuffix(s, "\t\t... and 5 more rows\n"))
+
+	rs.sets = append(rs.sets, NewRows([]string{"id"}).AddRow(2))
+	s = rs.String()
+	a.Contains(s, "\t\t\t... and 5 more rows\n\t\tresult set: 1\n\t\t\trow 0: [2]\n")
+}
+
+func TestAddRowsNoCopy(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	data := [][]any{{1, "john"}, {2, "jane"}}
+	rows := NewRows([]string{"id", "name"}).AddRowsNoCopy(data...)
+	a.Panics(func() { NewRows([]string{"id"}).AddRowsNoCopy([]any{1, 2}) })
+
+	clone := rows.Clone()
+	a.Same(&data[0][0], &clone.rows[0][0], "rows must be shared")
+	a.Equal(2, len(clone.rows))
+
+	mock, err := NewConn()
+	a.NoError(err)
+	defer mock.Close(context.Background())
+	mock.SeedTable("users", rows)
+	_, err = mock.Exec(context.Background(), "UPDATE users SET name = 'bob' WHERE id = 1")
+	a.NoError(err)
+	a.Equal("john", data[0][1], "in-memory tables must not modify shared rows")
+}
+
+func BenchmarkAddRowsNoCopy(b *testing.B) {
+	data := make([][]any, 10000)
+	for i := range data {
+		data[i] = []any{i, "name"}
+	}
+	b.Run("AddRows", func(b *testing.B) {
+		for range b.N {
+			NewRows([]string{"id", "name"}).AddRows(data...).Clone()
+		}
+	})
+	b.Run("AddRowsNoCopy", func(b *testing.B) {
+		for range b.N {
+			NewRows([]string{"id", "name"}).AddRowsNoCopy(data...).Clone()
+		}
+	})
+}
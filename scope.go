@@ -0,0 +1,88 @@
+package pgxmock
+
+import (
+	"fmt"
+
+	pgx "github.com/jackc/pgx/v5"
+)
+
+// scopedExpecter creates expectations and applies
+// additional settings to every created expectation
+type scopedExpecter struct {
+	mock  *pgxmock
+	apply func(expectation)
+}
+
+// scoped applies settings to all expectations declared by f
+func (s *scopedExpecter) scoped(f func()) {
+	n := len(s.mock.declared())
+	f()
+	for _, e := range s.mock.declared()[n:] {
+		s.apply(e)
+	}
+}
+
+func (s *scopedExpecter) ExpectBatch() (e *ExpectedBatch) {
+	s.scoped(func() { e = s.mock.ExpectBatch() })
+	return
+}
+
+func (s *scopedExpecter) ExpectCopyFrom(expectedTableName pgx.Identifier, expectedColumns []string) (e *ExpectedCopyFrom) {
+	s.scoped(func() { e = s.mock.ExpectCopyFrom(expectedTableName, expectedColumns) })
+	return
+}
+
+func (s *scopedExpecter) ExpectExec(expectedSQL string) (e *ExpectedExec) {
+	s.scoped(func() { e = s.mock.ExpectExec(expectedSQL) })
+	return
+}
+
+func (s *scopedExpecter) ExpectQuery(expectedSQL string) (e *ExpectedQuery) {
+	s.scoped(func() { e = s.mock.ExpectQuery(expectedSQL) })
+	return
+}
+
+func (s *scopedExpecter) ExpectPrepare(expectedStmtName, expectedSQL string) (e *ExpectedPrepare) {
+	s.scoped(func() { e = s.mock.ExpectPrepare(expectedStmtName, expectedSQL) })
+	return
+}
+
+func (s *scopedExpecter) ExpectTx(f func(tx TxExpecter)) (e *ExpectedCommit) {
+	s.scoped(func() { e = s.mock.ExpectTx(f) })
+	return
+}
+
+func (c *pgxmock) InState(state string) TxExpecter {
+	return &scopedExpecter{mock: c, apply: func(e expectation) { e.setRequiredState(state) }}
+}
+
+// ExpectationGroup is a named group of expectations, which may be
+// verified independently from other expectations of the mock.
+// Returned by pgxmock.Group.
+type ExpectationGroup struct {
+	scopedExpecter
+	name         string
+	expectations []expectation
+}
+
+func (c *pgxmock) Group(name string) *ExpectationGroup {
+	g := &ExpectationGroup{name: name}
+	g.scopedExpecter = scopedExpecter{mock: c, apply: func(e expectation) {
+		g.expectations = append(g.expectations, e)
+	}}
+	return g
+}
+
+// Name returns the name of the group
+func (g *ExpectationGroup) Name() string {
+	return g.name
+}
+
+// WereMet checks whether all expectations of the group were met.
+// If any of them was not met - an error is returned.
+func (g *ExpectationGroup) WereMet() error {
+	if err := g.mock.expectationsWereMet(g.expectations); err != nil {
+		return fmt.Errorf("group '%s': %w", g.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package pgxmock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInState(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+	mock.MatchExpectationsInOrder(false)
+
+	cart := NewRows([]string{"items"})
+	mock.InState("cart-created").ExpectQuery("SELECT items").
+		WillReturnRows(cart.Clone().AddRow(1))
+	mock.InState("logged-in").ExpectExec("INSERT INTO carts").
+		WillReturnResult(NewResult("INSERT", 1)).
+		TransitionsTo("cart-created")
+	mock.InState("logged-in").ExpectQuery("SELECT items").
+		WillReturnRows(cart.Clone().AddRow(0))
+	mock.ExpectExec("UPDATE users SET last_login").
+		WillReturnResult(NewResult("UPDATE", 1)).
+		TransitionsTo("logged-in")
+
+	var items int
+	_, err := mock.Exec(ctx, "INSERT INTO carts")
+	a.Error(err, "not logged in yet")
+	_, err = mock.Exec(ctx, "UPDATE users SET last_login = now()")
+	a.NoError(err)
+	a.NoError(mock.QueryRow(ctx, "SELECT items FROM carts").Scan(&items))
+	a.Equal(0, items)
+	_, err = mock.Exec(ctx, "INSERT INTO carts")
+	a.NoError(err)
+	a.NoError(mock.QueryRow(ctx, "SELECT items FROM carts").Scan(&items))
+	a.Equal(1, items)
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestInStateOrdered(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	mock.InState("ready").ExpectTx(func(tx TxExpecter) {
+		tx.ExpectExec("UPDATE").WillReturnResult(NewResult("UPDATE", 1))
+	})
+
+	_, err := mock.Begin(ctx)
+	a.ErrorContains(err, "only in state 'ready'")
+}
+
+func TestGroup(t *testing.T) {
+	t.Parallel()
+	mock, _ := NewConn()
+	a := assert.New(t)
+
+	checkout := mock.Group("checkout")
+	a.Equal("checkout", checkout.Name())
+	checkout.ExpectTx(func(tx TxExpecter) {
+		tx.ExpectExec("INSERT INTO orders").WillReturnResult(NewResult("INSERT", 1))
+	})
+	mock.ExpectPing()
+
+	a.Regexp(`^group 'checkout': there is a remaining expectation declared at scope_test\.go:\d+ which was not matched: `+
+		`ExpectedBegin => expecting call to Begin\(\) or to BeginTx\(\)\n$`, checkout.WereMet().Error())
+	tx, _ := mock.Begin(ctx)
+	_, err := tx.Exec(ctx, "INSERT INTO orders")
+	a.NoError(err)
+	a.NoError(tx.Commit(ctx))
+	a.NoError(checkout.WereMet())
+	a.Error(mock.ExpectationsWereMet(), "Ping() is still awaited")
+}
@@ -0,0 +1,576 @@
+package pgxmock
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+	pgconn "github.com/jackc/pgx/v5/pgconn"
+)
+
+// store keeps the in-memory tables seeded with SeedTable
+// and changed by INSERT, UPDATE and DELETE statements
+type store struct {
+	sync.Mutex
+	tables   map[string]*Rows         // committed tables
+	txs      map[*pgxmockTx]*txTables // tables seen by every open transaction
+	clock    Clock                    // clock of the primary keeping versions for replicas
+	maxLag   time.Duration            // the longest lag of replicas
+	versions []tablesVersion          // committed tables replicas may still see
+	primary  *store                   // store of the primary the replica reads tables of
+	lag      time.Duration            // lag of the replica behind the primary
+}
+
+// txTables are the tables seen by the open transaction and the writes made
+// in it, which are replayed on the enclosing tables on commit, so changes
+// committed by other transactions or made outside of it are kept
+type txTables struct {
+	tables map[string]*Rows
+	writes []tableWrite
+}
+
+// tableWrite is the INSERT, UPDATE or DELETE statement applied to the table
+type tableWrite struct {
+	table string
+	apply func(table *Rows) (string, error)
+}
+
+// tablesVersion is the state of tables committed at the moment
+type tablesVersion struct {
+	at     time.Time
+	tables map[string]*Rows
+}
+
+var (
+	reSelect = regexp.MustCompile(`(?is)^\s*SELECT\s+(.+?)\s+FROM\s+([\w."]+)` +
+		`(?:\s+WHERE\s+(.+?))?(?:\s+ORDER\s+BY\s+([\w"]+)(?:\s+(ASC|DESC))?)?` +
+		`(?:\s+LIMIT\s+(\$\d+|\d+))?(?:\s+OFFSET\s+(\$\d+|\d+))?\s*;?\s*$`)
+	reAnd       = regexp.MustCompile(`(?i)\s+AND\s+`)
+	reCondition = regexp.MustCompile(`(?is)^([\w"]+)\s*(=|<>|!=|<=|>=|<|>)\s*(\$\d+|-?\d+(?:\.\d+)?|'(?:[^']|'')*'|TRUE|FALSE)$`)
+	reIsNull    = regexp.MustCompile(`(?is)^([\w"]+)\s+IS\s+(NOT\s+)?NULL$`)
+	reInsert    = regexp.MustCompile(`(?is)^\s*INSERT\s+INTO\s+([\w."]+)\s*(?:\(([^)]*)\))?\s*VALUES\s*(.+?)\s*;?\s*$`)
+	reUpdate    = regexp.MustCompile(`(?is)^\s*UPDATE\s+([\w."]+)\s+SET\s+(.+?)(?:\s+WHERE\s+(.+?))?\s*;?\s*$`)
+	reDelete    = regexp.MustCompile(`(?is)^\s*DELETE\s+FROM\s+([\w."]+)(?:\s+WHERE\s+(.+?))?\s*;?\s*$`)
+	reValue     = `(\$\d+|-?\d+(?:\.\d+)?|'(?:[^']|'')*'|(?i:TRUE|FALSE|NULL))`
+	reTuple     = regexp.MustCompile(`^\s*\(((?:[^()']|'(?:[^']|'')*')*)\)\s*(?:,|$)`)
+	reListValue = regexp.MustCompile(`^\s*` + reValue + `\s*(?:,|$)`)
+	reAssign    = regexp.MustCompile(`^\s*([\w"]+)\s*=\s*` + reValue + `\s*(?:,|$)`)
+)
+
+// SeedTable fills the in-memory table with rows, see Expecter.SeedTable
+func (c *pgxmock) SeedTable(name string, rows *Rows) {
+	c.store.Lock()
+	defer c.store.Unlock()
+	if c.store.tables == nil {
+		c.store.tables = make(map[string]*Rows)
+	}
+	table := rows.clone(true)
+	c.store.tables[tableName(name)] = table
+	// seeded tables are replicated immediately
+	for i, v := range c.store.versions {
+		tables := make(map[string]*Rows, len(v.tables)+1)
+		maps.Copy(tables, v.tables)
+		tables[tableName(name)] = table
+		c.store.versions[i].tables = tables
+	}
+}
+
+// TableRows returns the copy of committed rows of the in-memory table, see Expecter.TableRows
+func (c *pgxmock) TableRows(name string) [][]any {
+	c.store.Lock()
+	defer c.store.Unlock()
+	table, ok := c.store.visibleTables()[tableName(name)]
+	if !ok {
+		return nil
+	}
+	return table.Clone().rows
+}
+
+// replicate makes the store the replica of the primary store lagging behind
+// it for the duration measured with the clock of the primary
+func (s *store) replicate(primary *store, clock Clock, lag time.Duration) {
+	primary.Lock()
+	defer primary.Unlock()
+	if primary.clock == nil {
+		primary.clock = clock
+		primary.versions = []tablesVersion{{tables: maps.Clone(primary.tables)}}
+	}
+	primary.maxLag = max(primary.maxLag, lag)
+	s.primary, s.lag = primary, lag
+}
+
+// publish remembers committed tables for replicas and forgets
+// versions replicas cannot see anymore, the store must be locked
+func (s *store) publish() {
+	if s.clock == nil {
+		return
+	}
+	now := s.clock.Now()
+	s.versions = append(s.versions, tablesVersion{at: now, tables: maps.Clone(s.tables)})
+	i := 0
+	for i+1 < len(s.versions) && !s.versions[i+1].at.After(now.Add(-s.maxLag)) {
+		i++
+	}
+	s.versions = s.versions[i:]
+}
+
+// visibleTables returns tables of the store, or tables of the primary
+// committed lag ago for the replica, the store must be locked
+func (s *store) visibleTables() map[string]*Rows {
+	if s.primary == nil {
+		return s.tables
+	}
+	p := s.primary
+	p.Lock()
+	defer p.Unlock()
+	seen := p.clock.Now().Add(-s.lag)
+	tables := p.versions[0].tables
+	for _, v := range p.versions[1:] {
+		if v.at.After(seen) {
+			break
+		}
+		tables = v.tables
+	}
+	return tables
+}
+
+// txTablesOf returns the tables seen by the open transaction tx,
+// nil if tx is nil or already ended, the store must be locked
+func (s *store) txTablesOf(tx *pgxmockTx) *txTables {
+	if tx == nil {
+		return nil
+	}
+	return s.txs[tx]
+}
+
+// readTables returns the tables seen by the transaction tx or the
+// visible committed tables outside of it, the store must be locked
+func (s *store) readTables(tx *pgxmockTx) map[string]*Rows {
+	if t := s.txTablesOf(tx); t != nil {
+		return t.tables
+	}
+	return s.visibleTables()
+}
+
+// begin starts the transaction tx seeing the tables of its enclosing
+// transaction or the committed ones. Writes replace changed tables with
+// copies, so copying the table references is enough.
+func (s *store) begin(tx *pgxmockTx) {
+	s.Lock()
+	defer s.Unlock()
+	if s.txs == nil {
+		s.txs = make(map[*pgxmockTx]*txTables)
+	}
+	s.txs[tx] = &txTables{tables: maps.Clone(s.readTables(tx.parent))}
+}
+
+// commit replays writes made in the transaction tx and its savepoints
+// still open on the enclosing transaction or the committed tables
+func (s *store) commit(tx *pgxmockTx) {
+	s.Lock()
+	defer s.Unlock()
+	s.release(tx)
+}
+
+// release replays writes of the transaction tx, the store must be locked
+func (s *store) release(tx *pgxmockTx) {
+	t := s.txTablesOf(tx)
+	if t == nil {
+		return
+	}
+	for savepoint := range s.txs {
+		if savepoint.parent == tx {
+			s.release(savepoint)
+		}
+	}
+	delete(s.txs, tx)
+	if parent := s.txTablesOf(tx.parent); parent != nil {
+		for _, w := range t.writes {
+			if _, _, err := w.applyTo(parent.tables); err == nil {
+				parent.writes = append(parent.writes, w)
+			}
+		}
+		return
+	}
+	if s.tables == nil {
+		s.tables = make(map[string]*Rows)
+	}
+	for _, w := range t.writes {
+		_, _, _ = w.applyTo(s.tables)
+	}
+	s.publish()
+}
+
+// rollback discards writes made in the transaction tx and its savepoints
+func (s *store) rollback(tx *pgxmockTx) {
+	s.Lock()
+	defer s.Unlock()
+	for t := range s.txs {
+		if t.within(tx) {
+			delete(s.txs, t)
+		}
+	}
+}
+
+// tableName returns the lowercased name without quotes
+func tableName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), `"`, ""))
+}
+
+// condition is the parsed predicate of the WHERE clause
+type condition struct {
+	column int
+	op     string
+	value  any
+}
+
+func (cond condition) matches(v any) bool {
+	switch cond.op {
+	case "IS NULL":
+		return v == nil
+	case "IS NOT NULL":
+		return v != nil
+	}
+	if v == nil || cond.value == nil {
+		return false
+	}
+	c, ok := compareValues(v, cond.value)
+	if !ok {
+		if cond.op != "=" && cond.op != "<>" && cond.op != "!=" {
+			return false
+		}
+		c = 1
+		if reflect.DeepEqual(v, cond.value) {
+			c = 0
+		}
+	}
+	switch cond.op {
+	case "=":
+		return c == 0
+	case "<>", "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0 // >=
+}
+
+// query answers the simple SELECT from the seeded table. The false
+// result means the statement is not a simple SELECT of a known table.
+func (s *store) query(tx *pgxmockTx, sql string, args []any) (pgx.Rows, bool, error) {
+	m := reSelect.FindStringSubmatch(sql)
+	if m == nil {
+		return nil, false, nil
+	}
+	s.Lock()
+	defer s.Unlock()
+	table, ok := s.readTables(tx)[tableName(m[2])]
+	if !ok {
+		return nil, false, nil
+	}
+	args = queryArgs(args)
+	columns, err := table.columnIndexes(m[1])
+	if err != nil {
+		return nil, true, err
+	}
+	conds, err := table.conditions(m[3], args)
+	if err != nil {
+		return nil, true, err
+	}
+	result := NewRowsWithColumnDefinition()
+	result.csvParser, result.types = table.csvParser, table.types
+	for _, i := range columns {
+		result.defs = append(result.defs, table.defs[i])
+	}
+	var selected [][]any
+	for _, row := range table.rows {
+		if matchesAll(conds, row) {
+			selected = append(selected, row)
+		}
+	}
+	if m[4] != "" {
+		col, err := table.columnIndex(m[4])
+		if err != nil {
+			return nil, true, err
+		}
+		desc := strings.EqualFold(m[5], "DESC")
+		slices.SortStableFunc(selected, func(a, b []any) int {
+			c, _ := compareValues(a[col], b[col])
+			if desc {
+				return -c
+			}
+			return c
+		})
+	}
+	if m[7] != "" {
+		offset, err := pageCount(m[7], args)
+		if err != nil {
+			return nil, true, err
+		}
+		selected = selected[min(offset, len(selected)):]
+	}
+	if m[6] != "" {
+		limit, err := pageCount(m[6], args)
+		if err != nil {
+			return nil, true, err
+		}
+		selected = selected[:min(limit, len(selected))]
+	}
+	for _, row := range selected {
+		values := make([]any, len(columns))
+		for i, col := range columns {
+			values[i] = row[col]
+		}
+		result.rows = append(result.rows, values)
+	}
+	result.commandTag = pgconn.NewCommandTag(fmt.Sprintf("SELECT %d", len(result.rows)))
+	return &rowSets{sets: []*Rows{result}}, true, nil
+}
+
+// matchesAll reports whether the row matches all conditions
+func matchesAll(conds []condition, row []any) bool {
+	return !slices.ContainsFunc(conds, func(cond condition) bool { return !cond.matches(row[cond.column]) })
+}
+
+// exec applies INSERT, UPDATE or DELETE to the seeded table seen by the
+// transaction tx, or to the committed one if tx is nil. The false result
+// means the statement is not a simple write to a known table.
+func (s *store) exec(tx *pgxmockTx, sql string, args []any) (pgconn.CommandTag, bool, error) {
+	var w tableWrite
+	args = queryArgs(args)
+	if m := reInsert.FindStringSubmatch(sql); m != nil {
+		w.table = m[1]
+		w.apply = func(table *Rows) (string, error) { return table.insert(m[2], m[3], args) }
+	} else if m := reUpdate.FindStringSubmatch(sql); m != nil {
+		w.table = m[1]
+		w.apply = func(table *Rows) (string, error) { return table.update(m[2], m[3], args) }
+	} else if m := reDelete.FindStringSubmatch(sql); m != nil {
+		w.table = m[1]
+		w.apply = func(table *Rows) (string, error) { return table.delete(m[2], args) }
+	} else {
+		return pgconn.CommandTag{}, false, nil
+	}
+	w.table = tableName(w.table)
+	s.Lock()
+	defer s.Unlock()
+	if t := s.txTablesOf(tx); t != nil {
+		tag, ok, err := w.applyTo(t.tables)
+		if ok && err == nil {
+			t.writes = append(t.writes, w)
+		}
+		return pgconn.NewCommandTag(tag), ok, err
+	}
+	tag, ok, err := w.applyTo(s.tables)
+	if ok && err == nil {
+		s.publish()
+	}
+	return pgconn.NewCommandTag(tag), ok, err
+}
+
+// applyTo applies the write to the copy of the table, so failed statements
+// change nothing. The false result means the table is not known.
+func (w tableWrite) applyTo(tables map[string]*Rows) (string, bool, error) {
+	table, ok := tables[w.table]
+	if !ok {
+		return "", false, nil
+	}
+	changed := table.Clone()
+	tag, err := w.apply(changed)
+	if err != nil {
+		return "", true, err
+	}
+	tables[w.table] = changed
+	return tag, true, nil
+}
+
+// insert adds rows of the VALUES list
+func (r *Rows) insert(columnList, values string, args []any) (string, error) {
+	columns, err := r.columnIndexes(cmp.Or(columnList, "*"))
+	if err != nil {
+		return "", err
+	}
+	n := 0
+	for values != "" {
+		m := reTuple.FindStringSubmatch(values)
+		if m == nil {
+			return "", fmt.Errorf("unsupported values: %s", values)
+		}
+		values = values[len(m[0]):]
+		tuple, err := parseList(m[1], args)
+		if err != nil {
+			return "", err
+		}
+		if len(tuple) != len(columns) {
+			msg := "INSERT has more target columns than expressions"
+			if len(tuple) > len(columns) {
+				msg = "INSERT has more expressions than target columns"
+			}
+			return "", &pgconn.PgError{Severity: "ERROR", Code: "42601", Message: msg}
+		}
+		row := make([]any, len(r.defs))
+		for i, col := range columns {
+			row[col] = tuple[i]
+		}
+		r.rows = append(r.rows, row)
+		n++
+	}
+	return fmt.Sprintf("INSERT 0 %d", n), nil
+}
+
+// update sets values of the SET list in the rows matching the WHERE clause
+func (r *Rows) update(assignments, where string, args []any) (string, error) {
+	conds, err := r.conditions(where, args)
+	if err != nil {
+		return "", err
+	}
+	values := make(map[int]any)
+	for assignments != "" {
+		m := reAssign.FindStringSubmatch(assignments)
+		if m == nil {
+			return "", fmt.Errorf("unsupported assignment: %s", assignments)
+		}
+		assignments = assignments[len(m[0]):]
+		col, err := r.columnIndex(m[1])
+		if err != nil {
+			return "", err
+		}
+		if values[col], err = literalValue(m[2], args); err != nil {
+			return "", err
+		}
+	}
+	n := 0
+	for _, row := range r.rows {
+		if !matchesAll(conds, row) {
+			continue
+		}
+		for col, v := range values {
+			row[col] = v
+		}
+		n++
+	}
+	return fmt.Sprintf("UPDATE %d", n), nil
+}
+
+// delete removes the rows matching the WHERE clause
+func (r *Rows) delete(where string, args []any) (string, error) {
+	conds, err := r.conditions(where, args)
+	if err != nil {
+		return "", err
+	}
+	n := len(r.rows)
+	r.rows = slices.DeleteFunc(r.rows, func(row []any) bool { return matchesAll(conds, row) })
+	return fmt.Sprintf("DELETE %d", n-len(r.rows)), nil
+}
+
+// parseList returns values of the comma separated list
+func parseList(list string, args []any) ([]any, error) {
+	var values []any
+	for list != "" {
+		m := reListValue.FindStringSubmatch(list)
+		if m == nil {
+			return nil, fmt.Errorf("unsupported values: %s", list)
+		}
+		list = list[len(m[0]):]
+		v, err := literalValue(m[1], args)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// literalValue returns the value of the literal or the placeholder argument
+func literalValue(s string, args []any) (any, error) {
+	switch strings.ToUpper(s) {
+	case "TRUE":
+		return true, nil
+	case "FALSE":
+		return false, nil
+	case "NULL":
+		return nil, nil
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, nil
+	}
+	return pageValue(s, args)
+}
+
+// columnIndex returns the position of the column
+func (r *Rows) columnIndex(name string) (int, error) {
+	name = tableName(name)
+	for i, def := range r.defs {
+		if strings.EqualFold(def.Name, name) {
+			return i, nil
+		}
+	}
+	return 0, &pgconn.PgError{Severity: "ERROR", Code: "42703", Message: fmt.Sprintf("column \"%s\" does not exist", name)}
+}
+
+// columnIndexes returns positions of the comma separated columns or all for *
+func (r *Rows) columnIndexes(list string) ([]int, error) {
+	var columns []int
+	if strings.TrimSpace(list) == "*" {
+		for i := range r.defs {
+			columns = append(columns, i)
+		}
+		return columns, nil
+	}
+	for _, name := range strings.Split(list, ",") {
+		i, err := r.columnIndex(name)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, i)
+	}
+	return columns, nil
+}
+
+// conditions parses the WHERE clause of predicates joined with AND
+func (r *Rows) conditions(where string, args []any) ([]condition, error) {
+	if where == "" {
+		return nil, nil
+	}
+	var conds []condition
+	for _, pred := range reAnd.Split(where, -1) {
+		pred = strings.TrimSpace(pred)
+		if m := reIsNull.FindStringSubmatch(pred); m != nil {
+			col, err := r.columnIndex(m[1])
+			if err != nil {
+				return nil, err
+			}
+			op := "IS NULL"
+			if m[2] != "" {
+				op = "IS NOT NULL"
+			}
+			conds = append(conds, condition{column: col, op: op})
+			continue
+		}
+		m := reCondition.FindStringSubmatch(pred)
+		if m == nil {
+			return nil, fmt.Errorf("unsupported predicate: %s", pred)
+		}
+		col, err := r.columnIndex(m[1])
+		if err != nil {
+			return nil, err
+		}
+		value, err := literalValue(m[3], args)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, condition{column: col, op: m[2], value: value})
+	}
+	return conds, nil
+}
@@ -0,0 +1,233 @@
+package pgxmock
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeedTable(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewPool()
+	mock.SeedTable("users", NewRows([]string{"id", "name", "email"}).
+		AddRow(1, "john", "john@example.com").
+		AddRow(2, "jane", nil).
+		AddRow(3, "peter", "peter@example.com"))
+
+	var name string
+	a.NoError(mock.QueryRow(ctx, "SELECT name FROM users WHERE id = $1", 2).Scan(&name))
+	a.Equal("jane", name)
+
+	rows, err := mock.Query(ctx, `SELECT id, name FROM "Users" WHERE email IS NOT NULL AND id >= 1 ORDER BY id DESC LIMIT 5`)
+	a.NoError(err)
+	names, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (name string, err error) {
+		err = row.Scan(nil, &name)
+		return
+	})
+	a.NoError(err)
+	a.Equal([]string{"peter", "john"}, names)
+	a.Equal("SELECT 2", rows.CommandTag().String())
+
+	err = mock.QueryRow(ctx, "SELECT * FROM users WHERE name = 'nobody'").Scan(&name)
+	a.ErrorIs(err, pgx.ErrNoRows)
+
+	var pgErr *pgconn.PgError
+	_, err = mock.Query(ctx, "SELECT age FROM users")
+	a.ErrorAs(err, &pgErr)
+	a.Equal("42703", pgErr.Code)
+
+	// expectations take precedence over tables
+	mock.ExpectQuery("SELECT name FROM users").WithArgs(1).WillReturnRows(NewRows([]string{"name"}).AddRow("mocked"))
+	a.NoError(mock.QueryRow(ctx, "SELECT name FROM users WHERE id = $1", 1).Scan(&name))
+	a.Equal("mocked", name)
+
+	_, err = mock.Query(ctx, "SELECT name FROM orders")
+	a.ErrorContains(err, "was not expected")
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestTableWrites(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	mock.SeedTable("users", NewRows([]string{"id", "name", "active"}).AddRow(1, "john", true))
+
+	tag, err := mock.Exec(ctx, "INSERT INTO users (id, name) VALUES ($1, $2), (3, 'it''s me')", 2, "jane")
+	a.NoError(err)
+	a.Equal("INSERT 0 2", tag.String())
+
+	tag, err = mock.Exec(ctx, "UPDATE users SET active = FALSE, name = $1 WHERE id >= 2", "jim")
+	a.NoError(err)
+	a.Equal("UPDATE 2", tag.String())
+
+	tag, err = mock.Exec(ctx, "DELETE FROM users WHERE id = $1", 3)
+	a.NoError(err)
+	a.Equal("DELETE 1", tag.String())
+
+	a.Equal([][]any{{1, "john", true}, {2, "jim", false}}, mock.TableRows("users"))
+
+	var name string
+	a.NoError(mock.QueryRow(ctx, "SELECT name FROM users WHERE active = false").Scan(&name))
+	a.Equal("jim", name)
+
+	_, err = mock.Exec(ctx, "INSERT INTO users (id, name) VALUES (4, 'joe', true)")
+	a.ErrorContains(err, "INSERT has more expressions than target columns")
+	a.Len(mock.TableRows("users"), 2, "failed statements change nothing")
+	a.Nil(mock.TableRows("orders"))
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestTableTransactions(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn()
+	mock.SeedTable("users", NewRows([]string{"id"}).AddRow(1))
+	insert := func(tx pgx.Tx, id int) {
+		_, err := tx.Exec(ctx, "INSERT INTO users VALUES ($1)", id)
+		a.NoError(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	tx, err := mock.Begin(ctx)
+	a.NoError(err)
+	insert(tx, 2)
+	var n int
+	a.NoError(tx.QueryRow(ctx, "SELECT id FROM users WHERE id = 2").Scan(&n), "changes are visible in the transaction")
+	a.Equal([][]any{{1}}, mock.TableRows("users"), "but not committed")
+	a.NoError(tx.Rollback(ctx))
+	a.Equal([][]any{{1}}, mock.TableRows("users"))
+
+	mock.ExpectBegin()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectCommit()
+	tx, _ = mock.Begin(ctx)
+	insert(tx, 2)
+	savepoint, _ := tx.Begin(ctx)
+	insert(savepoint, 3)
+	a.NoError(savepoint.Rollback(ctx))
+	a.NoError(tx.Commit(ctx))
+	a.Equal([][]any{{1}, {2}}, mock.TableRows("users"))
+
+	mock.ExpectBegin()
+	mock.ExpectCommit().WillReturnError(errors.New("serialization failure"))
+	tx, _ = mock.Begin(ctx)
+	insert(tx, 4)
+	a.Error(tx.Commit(ctx))
+	a.Equal([][]any{{1}, {2}}, mock.TableRows("users"), "nothing persisted on failure")
+
+	mock.ExpectBegin()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	mock.ExpectCommit()
+	tx, _ = mock.Begin(ctx)
+	savepoint, _ = tx.Begin(ctx)
+	insert(savepoint, 3)
+	a.NoError(savepoint.Commit(ctx))
+	a.ErrorIs(savepoint.Rollback(ctx), pgx.ErrTxClosed, "deferred rollback of the committed savepoint")
+	insert(tx, 4)
+	a.NoError(tx.Commit(ctx))
+	a.ErrorIs(tx.Rollback(ctx), pgx.ErrTxClosed, "deferred rollback of the committed transaction")
+	a.Equal([][]any{{1}, {2}, {3}, {4}}, mock.TableRows("users"))
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestTableOverlappingTransactions(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewPool()
+	mock.MatchExpectationsInOrder(false)
+	mock.SeedTable("users", NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	exec := func(conn pgx.Tx, sql string, args ...any) {
+		_, err := conn.Exec(ctx, sql, args...)
+		a.NoError(err)
+	}
+	count := func(conn pgx.Tx) int {
+		rows, _ := conn.Query(ctx, "SELECT id FROM users")
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		a.NoError(err)
+		return len(ids)
+	}
+
+	mock.ExpectBegin().Times(2)
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+	tx1, _ := mock.Begin(ctx)
+	tx2, _ := mock.Begin(ctx)
+	exec(tx1, "INSERT INTO users VALUES ($1)", 3)
+	exec(tx2, "INSERT INTO users VALUES ($1)", 4)
+	exec(mock, "DELETE FROM users WHERE id = $1", 1)
+	a.Equal(3, count(tx1), "changes of other transactions are not seen")
+	a.Equal(1, count(mock), "uncommitted changes are not seen outside")
+	a.NoError(tx2.Commit(ctx))
+	a.Equal([][]any{{2}, {4}}, mock.TableRows("users"))
+	a.NoError(tx1.Rollback(ctx))
+	a.Equal([][]any{{2}, {4}}, mock.TableRows("users"), "rollback keeps changes made by others")
+	a.NoError(mock.ExpectationsWereMet())
+}
+
+func TestReplicaOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	clock := &fakeClock{}
+	primary, _ := NewPool(ClockOption(clock))
+	replica, err := NewPool(ReplicaOption(primary, time.Second))
+	a.NoError(err)
+	primary.SeedTable("users", NewRows([]string{"id", "name"}).AddRow(1, "john"))
+
+	// findName reads from the replica falling back to the primary
+	findName := func(id int) (name string, stale bool) {
+		err := replica.QueryRow(ctx, "SELECT name FROM users WHERE id = $1", id).Scan(&name)
+		if errors.Is(err, pgx.ErrNoRows) {
+			stale = true
+			err = primary.QueryRow(ctx, "SELECT name FROM users WHERE id = $1", id).Scan(&name)
+		}
+		a.NoError(err)
+		return
+	}
+	name, stale := findName(1)
+	a.Equal("john", name)
+	a.False(stale, "seeded tables are replicated immediately")
+
+	_, err = primary.Exec(ctx, "INSERT INTO users VALUES ($1, $2)", 2, "jane")
+	a.NoError(err)
+	name, stale = findName(2)
+	a.Equal("jane", name)
+	a.True(stale)
+	clock.Advance(500 * time.Millisecond)
+	a.Len(replica.TableRows("users"), 1)
+	clock.Advance(500 * time.Millisecond)
+	a.Len(replica.TableRows("users"), 2)
+	name, stale = findName(2)
+	a.Equal("jane", name)
+	a.False(stale)
+
+	primary.ExpectBegin()
+	primary.ExpectCommit()
+	tx, _ := primary.Begin(ctx)
+	_, err = tx.Exec(ctx, "DELETE FROM users WHERE id = $1", 1)
+	a.NoError(err)
+	clock.Advance(time.Second)
+	a.NoError(tx.Commit(ctx))
+	a.Len(replica.TableRows("users"), 2, "changes are replicated since the commit")
+	clock.Advance(time.Second)
+	a.Equal([][]any{{2, "jane"}}, replica.TableRows("users"))
+
+	var pgErr *pgconn.PgError
+	_, err = replica.Exec(ctx, "INSERT INTO users VALUES ($1, $2)", 3, "peter")
+	a.ErrorAs(err, &pgErr)
+	a.Equal("25006", pgErr.Code)
+	a.NoError(primary.ExpectationsWereMet())
+	a.NoError(replica.ExpectationsWereMet())
+
+	_, err = NewConn(ReplicaOption(nil, time.Second))
+	a.Error(err)
+	_, err = NewConn(ReplicaOption(primary, -time.Second))
+	a.Error(err)
+}
@@ -0,0 +1,44 @@
+//go:build go1.25
+
+package pgxmock
+
+import (
+	"context"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSynctestDelay(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		a := assert.New(t)
+		mock, _ := NewConn()
+		mock.ExpectPing().WillDelayFor(time.Hour)
+		mock.ExpectExec("UPDATE").WillReturnResult(NewResult("UPDATE", 1))
+
+		start := time.Now()
+		a.NoError(mock.Ping(context.Background()))
+		a.Equal(time.Hour, time.Since(start))
+
+		_, err := mock.Exec(context.Background(), "UPDATE foo")
+		a.NoError(err)
+		a.Equal(time.Hour, time.Since(start))
+		a.NoError(mock.ExpectationsWereMet())
+	})
+}
+
+func TestSynctestDelayCancelled(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		a := assert.New(t)
+		mock, _ := NewConn()
+		mock.ExpectPing().WillDelayFor(time.Hour)
+
+		c, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		start := time.Now()
+		a.ErrorIs(mock.Ping(c), context.DeadlineExceeded)
+		a.Equal(time.Minute, time.Since(start))
+	})
+}
@@ -0,0 +1,146 @@
+package pgxmock
+
+import (
+	"time"
+
+	pgconn "github.com/jackc/pgx/v5/pgconn"
+)
+
+// ExpectedQueryTemplate is a reusable definition of the ExpectedQuery.
+// It may be declared once and instantiated many times on one or many mocks.
+// Every method returns a new template, so the template may be safely
+// used as a base for other templates.
+type ExpectedQueryTemplate struct {
+	expectSQL string
+	modifiers []func(*ExpectedQuery)
+}
+
+// QueryTemplate creates a template of the query expectation
+// matching expectedSQL query.
+func QueryTemplate(expectedSQL string) *ExpectedQueryTemplate {
+	return &ExpectedQueryTemplate{expectSQL: expectedSQL}
+}
+
+func (t *ExpectedQueryTemplate) with(f func(*ExpectedQuery)) *ExpectedQueryTemplate {
+	return &ExpectedQueryTemplate{
+		expectSQL: t.expectSQL,
+		modifiers: append(t.modifiers[:len(t.modifiers):len(t.modifiers)], f),
+	}
+}
+
+// WithArgs returns the template expecting given args, see ExpectedQuery.WithArgs.
+func (t *ExpectedQueryTemplate) WithArgs(args ...interface{}) *ExpectedQueryTemplate {
+	return t.with(func(e *ExpectedQuery) { e.WithArgs(args...) })
+}
+
+// WithRewrittenSQL returns the template expecting rewritten SQL, see ExpectedQuery.WithRewrittenSQL.
+func (t *ExpectedQueryTemplate) WithRewrittenSQL(sql string) *ExpectedQueryTemplate {
+	return t.with(func(e *ExpectedQuery) { e.WithRewrittenSQL(sql) })
+}
+
+// WillReturnRows returns the template returning given rows, see ExpectedQuery.WillReturnRows.
+func (t *ExpectedQueryTemplate) WillReturnRows(rows ...*Rows) *ExpectedQueryTemplate {
+	return t.with(func(e *ExpectedQuery) { e.WillReturnRows(rows...) })
+}
+
+// WillReturnError returns the template returning given error.
+func (t *ExpectedQueryTemplate) WillReturnError(err error) *ExpectedQueryTemplate {
+	return t.with(func(e *ExpectedQuery) { e.WillReturnError(err) })
+}
+
+// WillDelayFor returns the template delaying the result for duration.
+func (t *ExpectedQueryTemplate) WillDelayFor(duration time.Duration) *ExpectedQueryTemplate {
+	return t.with(func(e *ExpectedQuery) { e.WillDelayFor(duration) })
+}
+
+// RowsWillBeClosed returns the template expecting query rows to be closed.
+func (t *ExpectedQueryTemplate) RowsWillBeClosed() *ExpectedQueryTemplate {
+	return t.with(func(e *ExpectedQuery) { e.RowsWillBeClosed() })
+}
+
+// Maybe returns the template of the optional query expectation.
+func (t *ExpectedQueryTemplate) Maybe() *ExpectedQueryTemplate {
+	return t.with(func(e *ExpectedQuery) { e.Maybe() })
+}
+
+// Times returns the template of the query expected to be called n times.
+func (t *ExpectedQueryTemplate) Times(n uint) *ExpectedQueryTemplate {
+	return t.with(func(e *ExpectedQuery) { e.Times(n) })
+}
+
+// ExpectOn instantiates the template as the query expectation of the mock.
+// The returned *ExpectedQuery may be customized further.
+func (t *ExpectedQueryTemplate) ExpectOn(mock TxExpecter) *ExpectedQuery {
+	e := mock.ExpectQuery(t.expectSQL)
+	for _, f := range t.modifiers {
+		f(e)
+	}
+	return e
+}
+
+// ExpectedExecTemplate is a reusable definition of the ExpectedExec.
+// It may be declared once and instantiated many times on one or many mocks.
+// Every method returns a new template, so the template may be safely
+// used as a base for other templates.
+type ExpectedExecTemplate struct {
+	expectSQL string
+	modifiers []func(*ExpectedExec)
+}
+
+// ExecTemplate creates a template of the exec expectation
+// matching expectedSQL query.
+func ExecTemplate(expectedSQL string) *ExpectedExecTemplate {
+	return &ExpectedExecTemplate{expectSQL: expectedSQL}
+}
+
+func (t *ExpectedExecTemplate) with(f func(*ExpectedExec)) *ExpectedExecTemplate {
+	return &ExpectedExecTemplate{
+		expectSQL: t.expectSQL,
+		modifiers: append(t.modifiers[:len(t.modifiers):len(t.modifiers)], f),
+	}
+}
+
+// WithArgs returns the template expecting given args, see ExpectedExec.WithArgs.
+func (t *ExpectedExecTemplate) WithArgs(args ...interface{}) *ExpectedExecTemplate {
+	return t.with(func(e *ExpectedExec) { e.WithArgs(args...) })
+}
+
+// WithRewrittenSQL returns the template expecting rewritten SQL, see ExpectedExec.WithRewrittenSQL.
+func (t *ExpectedExecTemplate) WithRewrittenSQL(sql string) *ExpectedExecTemplate {
+	return t.with(func(e *ExpectedExec) { e.WithRewrittenSQL(sql) })
+}
+
+// WillReturnResult returns the template returning given result, see ExpectedExec.WillReturnResult.
+func (t *ExpectedExecTemplate) WillReturnResult(result pgconn.CommandTag) *ExpectedExecTemplate {
+	return t.with(func(e *ExpectedExec) { e.WillReturnResult(result) })
+}
+
+// WillReturnError returns the template returning given error.
+func (t *ExpectedExecTemplate) WillReturnError(err error) *ExpectedExecTemplate {
+	return t.with(func(e *ExpectedExec) { e.WillReturnError(err) })
+}
+
+// WillDelayFor returns the template delaying the result for duration.
+func (t *ExpectedExecTemplate) WillDelayFor(duration time.Duration) *ExpectedExecTemplate {
+	return t.with(func(e *ExpectedExec) { e.WillDelayFor(duration) })
+}
+
+// Maybe returns the template of the optional exec expectation.
+func (t *ExpectedExecTemplate) Maybe() *ExpectedExecTemplate {
+	return t.with(func(e *ExpectedExec) { e.Maybe() })
+}
+
+// Times returns the template of the exec expected to be called n times.
+func (t *ExpectedExecTemplate) Times(n uint) *ExpectedExecTemplate {
+	return t.with(func(e *ExpectedExec) { e.Times(n) })
+}
+
+// ExpectOn instantiates the template as the exec expectation of the mock.
+// The returned *ExpectedExec may be customized further.
+func (t *ExpectedExecTemplate) ExpectOn(mock TxExpecter) *ExpectedExec {
+	e := mock.ExpectExec(t.expectSQL)
+	for _, f := range t.modifiers {
+		f(e)
+	}
+	return e
+}
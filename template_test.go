@@ -0,0 +1,52 @@
+package pgxmock
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryTemplate(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	base := QueryTemplate("SELECT .* FROM users").WithArgs(AnyArg())
+	found := base.WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+	failed := base.WillReturnError(errors.New("no users"))
+
+	for range 2 {
+		mock, _ := NewConn()
+		found.ExpectOn(mock)
+		failed.ExpectOn(mock)
+		mock.ExpectTx(func(tx TxExpecter) {
+			found.ExpectOn(tx).Times(2)
+		})
+
+		var id int
+		a.NoError(mock.QueryRow(ctx, "SELECT id FROM users WHERE name = $1", "John").Scan(&id))
+		a.Equal(1, id)
+		a.EqualError(mock.QueryRow(ctx, "SELECT id FROM users WHERE name = $1", "Jane").Scan(&id), "no users")
+		tx, _ := mock.Begin(ctx)
+		_, err := tx.Query(ctx, "SELECT id FROM users WHERE name = $1", "John")
+		a.NoError(err)
+		_, err = tx.Query(ctx, "SELECT id FROM users WHERE name = $1", "John")
+		a.NoError(err)
+		a.NoError(tx.Commit(ctx))
+		a.NoError(mock.ExpectationsWereMet())
+	}
+}
+
+func TestExecTemplate(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	insert := ExecTemplate("INSERT INTO users").WillReturnResult(NewResult("INSERT", 1))
+	mock, _ := NewConn()
+	insert.WithArgs("John").ExpectOn(mock)
+	insert.WithArgs("Jane").Maybe().ExpectOn(mock)
+
+	_, err := mock.Exec(ctx, "INSERT INTO users(name) VALUES ($1)", "John")
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+}
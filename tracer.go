@@ -0,0 +1,80 @@
+package pgxmock
+
+import (
+	"context"
+	"strconv"
+
+	pgx "github.com/jackc/pgx/v5"
+	pgconn "github.com/jackc/pgx/v5/pgconn"
+)
+
+// traceConn is passed to tracers instead of the real connection,
+// its PgConn() is nil, e.g. tracelog.TraceLog logs no pid then
+var traceConn = &pgx.Conn{}
+
+// rowsCommandTag returns the command tag of the mocked rows if any
+func rowsCommandTag(rows pgx.Rows) pgconn.CommandTag {
+	if rs, ok := rows.(*rowSets); ok && len(rs.sets) > 0 {
+		return rs.CommandTag()
+	}
+	return pgconn.CommandTag{}
+}
+
+func (c *pgxmock) traceQueryStart(ctx context.Context, sql string, args []any) context.Context {
+	if c.tracer == nil {
+		return ctx
+	}
+	return c.tracer.TraceQueryStart(ctx, traceConn, pgx.TraceQueryStartData{SQL: sql, Args: args})
+}
+
+func (c *pgxmock) traceQueryEnd(ctx context.Context, tag pgconn.CommandTag, err error) {
+	if c.tracer != nil {
+		c.tracer.TraceQueryEnd(ctx, traceConn, pgx.TraceQueryEndData{CommandTag: tag, Err: err})
+	}
+}
+
+func (c *pgxmock) traceBatchStart(ctx context.Context, b *pgx.Batch) context.Context {
+	if t, ok := c.tracer.(pgx.BatchTracer); ok {
+		return t.TraceBatchStart(ctx, traceConn, pgx.TraceBatchStartData{Batch: b})
+	}
+	return ctx
+}
+
+func (c *pgxmock) traceBatchQuery(ctx context.Context, sql string, args []any, tag pgconn.CommandTag, err error) {
+	if t, ok := c.tracer.(pgx.BatchTracer); ok {
+		t.TraceBatchQuery(ctx, traceConn, pgx.TraceBatchQueryData{SQL: sql, Args: args, CommandTag: tag, Err: err})
+	}
+}
+
+func (c *pgxmock) traceBatchEnd(ctx context.Context, err error) {
+	if t, ok := c.tracer.(pgx.BatchTracer); ok {
+		t.TraceBatchEnd(ctx, traceConn, pgx.TraceBatchEndData{Err: err})
+	}
+}
+
+func (c *pgxmock) traceCopyFromStart(ctx context.Context, tableName pgx.Identifier, columnNames []string) context.Context {
+	if t, ok := c.tracer.(pgx.CopyFromTracer); ok {
+		return t.TraceCopyFromStart(ctx, traceConn, pgx.TraceCopyFromStartData{TableName: tableName, ColumnNames: columnNames})
+	}
+	return ctx
+}
+
+func (c *pgxmock) traceCopyFromEnd(ctx context.Context, rowsAffected int64, err error) {
+	if t, ok := c.tracer.(pgx.CopyFromTracer); ok {
+		tag := pgconn.NewCommandTag("COPY " + strconv.FormatInt(max(rowsAffected, 0), 10))
+		t.TraceCopyFromEnd(ctx, traceConn, pgx.TraceCopyFromEndData{CommandTag: tag, Err: err})
+	}
+}
+
+func (c *pgxmock) tracePrepareStart(ctx context.Context, name, sql string) context.Context {
+	if t, ok := c.tracer.(pgx.PrepareTracer); ok {
+		return t.TracePrepareStart(ctx, traceConn, pgx.TracePrepareStartData{Name: name, SQL: sql})
+	}
+	return ctx
+}
+
+func (c *pgxmock) tracePrepareEnd(ctx context.Context, err error) {
+	if t, ok := c.tracer.(pgx.PrepareTracer); ok {
+		t.TracePrepareEnd(ctx, traceConn, pgx.TracePrepareEndData{Err: err})
+	}
+}
@@ -0,0 +1,56 @@
+package pgxmock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/tracelog"
+	"github.com/stretchr/testify/assert"
+)
+
+type logEntry struct {
+	level tracelog.LogLevel
+	msg   string
+	data  map[string]any
+}
+
+func TestTracerOption(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	var entries []logEntry
+	tracer := &tracelog.TraceLog{
+		Logger: tracelog.LoggerFunc(func(_ context.Context, level tracelog.LogLevel, msg string, data map[string]any) {
+			entries = append(entries, logEntry{level, msg, data})
+		}),
+		LogLevel: tracelog.LogLevelInfo,
+	}
+	mock, _ := NewConn(TracerOption(tracer))
+	mock.ExpectExec("UPDATE").WithArgs(1).WillReturnResult(NewResult("UPDATE", 2))
+	mock.ExpectQuery("SELECT").WillReturnError(errors.New("no table"))
+	mock.ExpectPrepare("stmt", "SELECT")
+	mock.ExpectCopyFrom(pgx.Identifier{"users"}, []string{"id"}).WillReturnResult(3)
+	eb := mock.ExpectBatch()
+	eb.ExpectExec("INSERT").WillReturnResult(NewResult("INSERT", 1))
+
+	_, _ = mock.Exec(ctx, "UPDATE users SET active = $1", 1)
+	_, _ = mock.Query(ctx, "SELECT * FROM users")
+	_, _ = mock.Prepare(ctx, "stmt", "SELECT 1")
+	_, _ = mock.CopyFrom(ctx, pgx.Identifier{"users"}, []string{"id"}, pgx.CopyFromRows([][]any{{1}}))
+	b := &pgx.Batch{}
+	b.Queue("INSERT INTO users VALUES (1)")
+	a.NoError(mock.SendBatch(ctx, b).Close())
+	a.NoError(mock.ExpectationsWereMet())
+
+	msgs := make([]string, len(entries))
+	for i, e := range entries {
+		msgs[i] = e.msg
+	}
+	a.Equal([]string{"Query", "Query", "Prepare", "CopyFrom", "BatchQuery", "BatchClose"}, msgs)
+	a.Equal("UPDATE 2", entries[0].data["commandTag"])
+	a.Equal(tracelog.LogLevelError, entries[1].level)
+	a.EqualError(entries[1].data["err"].(error), "no table")
+	a.Equal(int64(3), entries[3].data["rowCount"])
+	a.Equal("INSERT INTO users VALUES (1)", entries[4].data["sql"])
+}
@@ -0,0 +1,171 @@
+package pgxmock
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pgx "github.com/jackc/pgx/v5"
+	pgconn "github.com/jackc/pgx/v5/pgconn"
+)
+
+// pgxmockTx is the transaction returned by Begin and BeginTx. Calls issued
+// on it are told apart from calls issued on the mock itself, e.g. on the
+// pool, while the transaction is open
+type pgxmockTx struct {
+	*pgxmock
+	begin    *ExpectedBegin  // expectation the transaction was begun by
+	parent   *pgxmockTx      // enclosing transaction of the savepoint
+	site     string          // call site, see VerifyTxCompletionOption
+	readOnly bool            // begun read-only or the savepoint of the read-only transaction
+	closed   bool            // committed or rolled back, guarded by txMu
+	prepared map[string]bool // statements prepared in the transaction, guarded by mu, see TransactionPoolingOption
+}
+
+// serverConn returns the outermost transaction, which keeps the server
+// connection for all its savepoints with the transaction pooling
+func (tx *pgxmockTx) serverConn() *pgxmockTx {
+	for tx.parent != nil {
+		tx = tx.parent
+	}
+	return tx
+}
+
+// keepStatement remembers the statement prepared on the server connection
+func (tx *pgxmockTx) keepStatement(name string) {
+	conn := tx.serverConn()
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	conn.prepared[name] = true
+}
+
+// keepsStatement reports whether the statement was prepared
+// on the server connection of the transaction
+func (tx *pgxmockTx) keepsStatement(name string) bool {
+	conn := tx.serverConn()
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	return conn.prepared[name]
+}
+
+func (tx *pgxmockTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return tx.BeginTx(ctx, pgx.TxOptions{})
+}
+
+func (tx *pgxmockTx) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	return tx.beginIn(ctx, txOptions, tx)
+}
+
+func (tx *pgxmockTx) Commit(ctx context.Context) error {
+	return tx.commit(ctx, tx)
+}
+
+func (tx *pgxmockTx) Rollback(ctx context.Context) error {
+	return tx.rollback(ctx, tx)
+}
+
+func (tx *pgxmockTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return tx.execIn(ctx, tx, sql, args...)
+}
+
+func (tx *pgxmockTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return tx.queryIn(ctx, tx, sql, args...)
+}
+
+func (tx *pgxmockTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return tx.queryRowIn(ctx, tx, sql, args...)
+}
+
+func (tx *pgxmockTx) Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error) {
+	return tx.prepare(ctx, tx, name, sql)
+}
+
+func (tx *pgxmockTx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return tx.copyFrom(ctx, tableName, columnNames, rowSrc, tx)
+}
+
+func (tx *pgxmockTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return tx.sendBatch(ctx, b, tx)
+}
+
+// beginTx registers the begun transaction as open,
+// savepoints of the read-only transaction are read-only too
+func (c *pgxmock) beginTx(tx *pgxmockTx, readOnly bool) {
+	tx.readOnly = readOnly || tx.parent != nil && tx.parent.readOnly
+	if c.verifyTx {
+		tx.site = declarationSite()
+	}
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+	c.txs = append(c.txs, tx)
+}
+
+// openTx returns the transaction tx to be committed or rolled back, or the
+// last begun one if tx is nil. The same as pgx, the closed transaction
+// cannot be ended twice, e.g. by the deferred Rollback after Commit.
+func (c *pgxmock) openTx(tx *pgxmockTx) (*pgxmockTx, error) {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+	if tx == nil {
+		if n := len(c.txs); n > 0 {
+			return c.txs[n-1], nil
+		}
+		return nil, nil
+	}
+	if tx.closed {
+		return nil, pgx.ErrTxClosed
+	}
+	return tx, nil
+}
+
+// endTx closes the committed or rolled back transaction together with
+// its savepoints, which are released or rolled back by the database too
+func (c *pgxmock) endTx(tx *pgxmockTx) {
+	if tx == nil {
+		return
+	}
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+	open := c.txs[:0]
+	for _, t := range c.txs {
+		if t.within(tx) {
+			t.closed = true
+			continue
+		}
+		open = append(open, t)
+	}
+	c.txs = open
+}
+
+// inOpenTx reports whether the call is issued in the open transaction
+func (c *pgxmock) inOpenTx(call *Call) bool {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+	return call.tx != nil && !call.tx.closed
+}
+
+// within reports whether the transaction is tx or its savepoint
+func (tx *pgxmockTx) within(outer *pgxmockTx) bool {
+	for ; tx != nil; tx = tx.parent {
+		if tx == outer {
+			return true
+		}
+	}
+	return false
+}
+
+// txMatches returns an error if the call, which the begin expectation
+// owns, is issued outside of its transaction, e.g. on the pool
+func txMatches(method string, begin *ExpectedBegin, tx *pgxmockTx) error {
+	method = strings.TrimSuffix(method, "()")
+	if begin == nil {
+		return nil
+	}
+	for ; tx != nil; tx = tx.parent {
+		if tx.begin == begin {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: call was expected to be issued in the transaction begun at %s, but was issued outside of it",
+		method, begin.declaredAt())
+}
@@ -0,0 +1,33 @@
+package pgxmock
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// EnumType returns the enum data type with the given name and OID,
+// to be registered with TypesOption or Rows.WithTypes. Values of enum
+// columns may be scanned into strings or custom string based Go types.
+func EnumType(name string, oid uint32) *pgtype.Type {
+	return &pgtype.Type{Name: name, OID: oid, Codec: &pgtype.EnumCodec{}}
+}
+
+// DomainType returns the domain data type with the given name and OID
+// over the underlying built-in data type, to be registered with TypesOption
+// or Rows.WithTypes. Values of domain columns are scanned the same way as
+// values of the underlying type. Panics if the underlying type is unknown.
+func DomainType(name string, oid uint32, underlyingOID uint32) *pgtype.Type {
+	underlying, ok := pgtype.NewMap().TypeForOID(underlyingOID)
+	if !ok {
+		panic(fmt.Sprintf("unknown underlying type OID %d for domain %s", underlyingOID, name))
+	}
+	return &pgtype.Type{Name: name, OID: oid, Codec: underlying.Codec}
+}
+
+// HstoreType returns the hstore extension data type with the given OID,
+// to be registered with TypesOption or Rows.WithTypes, so the text form of
+// hstore values may be scanned or matched with TypedArg.
+func HstoreType(oid uint32) *pgtype.Type {
+	return &pgtype.Type{Name: "hstore", OID: oid, Codec: pgtype.HstoreCodec{}}
+}
@@ -0,0 +1,188 @@
+package pgxmock
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+type mood string
+
+type positiveInt int64
+
+func TestEnumAndDomainTypes(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	mock, _ := NewConn(TypesOption(EnumType("mood", 90003), DomainType("positive_int", 90004, pgtype.Int8OID)))
+
+	rows := mock.NewRowsWithColumnDefinition(
+		pgconn.FieldDescription{Name: "mood", DataTypeOID: 90003},
+		pgconn.FieldDescription{Name: "score", DataTypeOID: 90004},
+	).AddRow("happy", int64(42)).FromCSVStringWithOptions("sad,7", CSVOptions{TypedParsing: true})
+	r := rows.Kind()
+
+	var s string
+	var m mood
+	var i int64
+	var p positiveInt
+	a.True(r.Next())
+	a.NoError(r.Scan(&s, &i))
+	a.Equal("happy", s)
+	a.Equal(int64(42), i)
+	a.NoError(r.Scan(&m, &p))
+	a.Equal(mood("happy"), m)
+	a.Equal(positiveInt(42), p)
+
+	a.True(r.Next())
+	a.NoError(r.Scan(&m, &p))
+	a.Equal(mood("sad"), m)
+	a.Equal(positiveInt(7), p)
+
+	a.Panics(func() { DomainType("foo", 90005, 90006) })
+}
+
+// decimal mimics shopspring/decimal.Decimal implementing sql.Scanner
+type decimal struct{ s string }
+
+func (d *decimal) Scan(v any) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("could not convert value '%+v' to decimal", v)
+	}
+	d.s = s
+	return nil
+}
+
+func TestNumericScan(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	num := pgtype.Numeric{Int: big.NewInt(1234), Exp: -2, Valid: true}
+	numeric := pgconn.FieldDescription{Name: "amount", DataTypeOID: pgtype.NumericOID}
+	for _, v := range []any{num, "12.34", 12.34} {
+		r := NewRowsWithColumnDefinition(numeric).AddRow(v).Kind()
+		a.True(r.Next())
+		var s string
+		var f float64
+		var n pgtype.Numeric
+		var d decimal
+		a.NoError(r.Scan(&s))
+		a.Equal("12.34", s)
+		a.NoError(r.Scan(&f))
+		a.Equal(12.34, f)
+		a.NoError(r.Scan(&n))
+		a.Equal(num, n)
+		a.NoError(r.Scan(&d))
+		a.Equal("12.34", d.s)
+	}
+	r := NewRowsWithColumnDefinition(numeric).AddRow(true).Kind()
+	a.True(r.Next())
+	var d decimal
+	a.ErrorContains(r.Scan(&d), "could not convert value")
+}
+
+func TestRangeAndIntervalScan(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	lower := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	upper := lower.Add(24 * time.Hour)
+	tstzrange := pgtype.Range[time.Time]{Lower: lower, Upper: upper, LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive, Valid: true}
+	rows := NewRowsWithColumnDefinition(
+		pgconn.FieldDescription{Name: "during", DataTypeOID: pgtype.TstzrangeOID},
+		pgconn.FieldDescription{Name: "days", DataTypeOID: pgtype.DaterangeOID},
+		pgconn.FieldDescription{Name: "duration", DataTypeOID: pgtype.IntervalOID},
+	).
+		AddRow(tstzrange, "[2024-01-01,2024-01-05)", time.Hour).
+		AddRow("[2024-01-01 00:00:00+00,2024-01-02 00:00:00+00)", pgtype.Range[pgtype.Date]{
+			Lower:     pgtype.Date{Time: lower, Valid: true},
+			Upper:     pgtype.Date{Time: lower.AddDate(0, 0, 4), Valid: true},
+			LowerType: pgtype.Inclusive, UpperType: pgtype.Exclusive, Valid: true,
+		}, "01:00:00")
+	r := rows.Kind()
+	for r.Next() {
+		var during pgtype.Range[pgtype.Timestamptz]
+		var days pgtype.Range[pgtype.Date]
+		var interval pgtype.Interval
+		a.NoError(r.Scan(&during, &days, &interval))
+		a.True(during.Lower.Time.Equal(lower))
+		a.True(during.Upper.Time.Equal(upper))
+		a.Equal(pgtype.Exclusive, during.UpperType)
+		a.True(days.Upper.Time.Equal(lower.AddDate(0, 0, 4)))
+		a.Equal(pgtype.Interval{Microseconds: time.Hour.Microseconds(), Valid: true}, interval)
+
+		var d time.Duration
+		var a1, a2 any
+		a.NoError(r.Scan(&a1, &a2, &d))
+		a.Equal(time.Hour, d)
+	}
+	a.NoError(r.Err())
+
+	r = NewRows([]string{"duration"}).AddRow(time.Hour).Kind()
+	a.True(r.Next())
+	var interval pgtype.Interval
+	a.NoError(r.Scan(&interval))
+	a.Equal(time.Hour.Microseconds(), interval.Microseconds)
+}
+
+func TestArrayScan(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	r := NewRows([]string{"ids", "names", "blobs", "flat"}).
+		AddRow([]int64{1, 2, 3}, []string{"a", "b"}, [][]byte{[]byte("a")}, pgtype.FlatArray[int64]{1, 2, 3}).
+		AddRow("{1,2,3}", "{a,b}", [][]byte{[]byte("a")}, "{1,2,3}").
+		Kind()
+	for r.Next() {
+		var ids []int32
+		var names pgtype.FlatArray[string]
+		var blobs [][]byte
+		var flat []int64
+		a.NoError(r.Scan(&ids, &names, &blobs, &flat))
+		a.Equal([]int32{1, 2, 3}, ids)
+		a.Equal(pgtype.FlatArray[string]{"a", "b"}, names)
+		a.Equal([][]byte{[]byte("a")}, blobs)
+		a.Equal([]int64{1, 2, 3}, flat)
+	}
+	a.NoError(r.Err())
+
+	r = NewRowsWithColumnDefinition(pgconn.FieldDescription{Name: "ids", DataTypeOID: pgtype.Int8ArrayOID}).
+		FromCSVStringWithOptions(`"{1,2,3}"`, CSVOptions{TypedParsing: true}).
+		Kind()
+	a.True(r.Next())
+	var ids []int64
+	a.NoError(r.Scan(&ids))
+	a.Equal([]int64{1, 2, 3}, ids)
+}
+
+func TestHstore(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	const hstoreOID = 90005
+	one := "1"
+	expected := map[string]*string{"a": &one, "b": nil}
+	mock, _ := NewConn(TypesOption(HstoreType(hstoreOID)))
+	r := mock.NewRowsWithColumnDefinition(pgconn.FieldDescription{Name: "attrs", DataTypeOID: hstoreOID}).
+		AddRow(`"a"=>"1", "b"=>NULL`).
+		AddRow(pgtype.Hstore(expected)).
+		AddRow(expected).
+		Kind()
+	for r.Next() {
+		var m map[string]*string
+		var h pgtype.Hstore
+		a.NoError(r.Scan(&m))
+		a.Equal(expected, m)
+		a.NoError(r.Scan(&h))
+		a.Equal(pgtype.Hstore(expected), h)
+	}
+	a.NoError(r.Err())
+
+	mock.ExpectExec("UPDATE").
+		WithArgs(TypedArg(HstoreType(hstoreOID), `"a"=>"1", "b"=>NULL`)).
+		WillReturnResult(NewResult("UPDATE", 1))
+	_, err := mock.Exec(ctx, "UPDATE items SET attrs = $1", pgtype.Hstore(expected))
+	a.NoError(err)
+	a.NoError(mock.ExpectationsWereMet())
+}